@@ -0,0 +1,97 @@
+package tixer
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// APIKeyPrefix starts every API key, so keys can be told apart from JWTs
+	// and recognised by secret scanners.
+	APIKeyPrefix = "tix_"
+
+	apiKeySecretBytes = 24
+	apiKeyHintLength  = len(APIKeyPrefix) + 8
+)
+
+// APIKey represents a long-lived credential that lets a service call the API
+// on behalf of the user that issued it. Only the hash of the key is stored.
+type APIKey struct {
+	ID           int64
+	PublicID     PublicID
+	UserID       int64
+	UserPublicID PublicID
+	Name         string
+	Hint         string // the first characters of the key, to recognise it in listings
+	Hash         []byte
+	Scopes       Permissions // the permissions the key may use, as long as the user still holds them
+	ExpiresAt    *time.Time  // nil if the key never expires
+	LastUsedAt   *time.Time  // nil if the key was never used
+	CreatedAt    time.Time
+}
+
+// Validate checks API key's fields to ensure that the basic business rules are met.
+// It returns a boolean indicating if the API key is valid and a map of errors if it's not.
+func (k APIKey) Validate() (bool, map[string]string) {
+	errors := make(map[string]string)
+
+	if k.Name == "" {
+		errors["name"] = "must be provided"
+	}
+
+	if len(k.Name) > 100 {
+		errors["name"] = "must not be more than 100 characters long"
+	}
+
+	if len(k.Scopes) == 0 {
+		errors["scopes"] = "must contain at least one scope"
+	}
+
+	for _, scope := range k.Scopes {
+		if !AllPermissions.Has(scope) {
+			errors["scopes"] = "contains unknown scope " + string(scope)
+		}
+	}
+
+	if k.ExpiresAt != nil && !k.ExpiresAt.After(time.Now()) {
+		errors["expiresAt"] = "must be in the future"
+	}
+
+	if len(errors) > 0 {
+		return false, errors
+	}
+
+	return true, nil
+}
+
+// GenerateKey generates a new random key, sets the hint and the hash of the API key
+// and returns the plaintext key. The plaintext key cannot be recovered later.
+func (k *APIKey) GenerateKey() (string, error) {
+	secret := make([]byte, apiKeySecretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+
+	key := APIKeyPrefix + hex.EncodeToString(secret)
+	k.Hint = key[:apiKeyHintLength]
+	k.Hash = HashAPIKey(key)
+
+	return key, nil
+}
+
+// HashAPIKey returns the hash under which a plaintext API key is stored.
+// Keys carry enough entropy for a fast hash to be safe, which keeps the lookup
+// on every request cheap.
+func HashAPIKey(key string) []byte {
+	hash := sha256.Sum256([]byte(key))
+	return hash[:]
+}
+
+// IsAPIKey reports whether the bearer token looks like an API key rather than a JWT.
+func IsAPIKey(token string) bool {
+	return strings.HasPrefix(token, APIKeyPrefix)
+}
@@ -0,0 +1,134 @@
+// This package provides support for issuing and verifying signed JSON Web Tokens.
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrInvalidToken      = errors.New("invalid token")
+	ErrSigningKeyMissing = errors.New("signing key missing")
+)
+
+// Config represents the configuration details for the token authenticator.
+type Config struct {
+	Algorithm      string        // the signing algorithm, HS256 or RS256
+	Secret         string        // the shared secret used by HS256
+	PrivateKeyFile string        // path to the PEM encoded RSA private key used by RS256 to sign tokens
+	PublicKeyFile  string        // path to the PEM encoded RSA public key used by RS256 to verify tokens
+	Issuer         string        // the value of the iss claim
+	TTL            time.Duration // how long an issued token is valid
+}
+
+// Claims represents the claims carried by the tokens. The subject is the public ID of the user.
+type Claims struct {
+	jwt.RegisteredClaims
+}
+
+// Authenticator issues and verifies signed tokens.
+type Authenticator struct {
+	method    jwt.SigningMethod
+	signKey   any
+	verifyKey any
+	issuer    string
+	ttl       time.Duration
+}
+
+// NewAuthenticator creates a new Authenticator, loading the keys required by the configured algorithm.
+// With RS256 the private key is optional: without it tokens can be verified but not issued.
+func NewAuthenticator(cfg Config) (*Authenticator, error) {
+	a := &Authenticator{
+		issuer: cfg.Issuer,
+		ttl:    cfg.TTL,
+	}
+
+	switch cfg.Algorithm {
+	case jwt.SigningMethodHS256.Alg():
+		if len(cfg.Secret) < 32 {
+			return nil, errors.New("HS256 secret must be at least 32 bytes long")
+		}
+		a.method = jwt.SigningMethodHS256
+		a.signKey = []byte(cfg.Secret)
+		a.verifyKey = []byte(cfg.Secret)
+
+	case jwt.SigningMethodRS256.Alg():
+		a.method = jwt.SigningMethodRS256
+
+		pem, err := os.ReadFile(cfg.PublicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read public key: %w", err)
+		}
+		if a.verifyKey, err = jwt.ParseRSAPublicKeyFromPEM(pem); err != nil {
+			return nil, fmt.Errorf("failed to parse public key: %w", err)
+		}
+
+		if cfg.PrivateKeyFile != "" {
+			pem, err := os.ReadFile(cfg.PrivateKeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read private key: %w", err)
+			}
+			if a.signKey, err = jwt.ParseRSAPrivateKeyFromPEM(pem); err != nil {
+				return nil, fmt.Errorf("failed to parse private key: %w", err)
+			}
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm: %s", cfg.Algorithm)
+	}
+
+	return a, nil
+}
+
+// Issue creates a signed token for the given subject and returns it with its expiry time.
+func (a *Authenticator) Issue(subject string) (string, time.Time, error) {
+	if a.signKey == nil {
+		return "", time.Time{}, ErrSigningKeyMissing
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(a.ttl)
+
+	claims := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    a.issuer,
+			Subject:   subject,
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	token, err := jwt.NewWithClaims(a.method, claims).SignedString(a.signKey)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to sign token: %w", err)
+	}
+
+	return token, expiresAt, nil
+}
+
+// Verify checks the signature, algorithm, issuer and time based claims of the token
+// and returns its claims. It returns ErrInvalidToken if any check fails.
+func (a *Authenticator) Verify(token string) (Claims, error) {
+	var claims Claims
+
+	_, err := jwt.ParseWithClaims(token, &claims,
+		func(*jwt.Token) (any, error) { return a.verifyKey, nil },
+		jwt.WithValidMethods([]string{a.method.Alg()}),
+		jwt.WithIssuer(a.issuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return Claims{}, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	if claims.Subject == "" {
+		return Claims{}, fmt.Errorf("%w: subject missing", ErrInvalidToken)
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,18 @@
+package tixer
+
+import (
+	"time"
+)
+
+// CheckIn represents the admission of a purchased ticket, held through a paid order, at the gate.
+// A ticket is checked in at most once.
+type CheckIn struct {
+	ID             int64
+	OrderID        int64
+	OrderPublicID  PublicID
+	TicketPublicID PublicID
+	EventPublicID  *PublicID // nil if the ticket is not sold for an event
+	ScannedByID    int64     // the ID of the gate staff user that scanned the credential
+	ScannedBy      string    // the email of the gate staff user
+	ScannedAt      time.Time
+}
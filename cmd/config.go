@@ -2,18 +2,37 @@ package main
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
+	tixer "github.com/mroobert/monorepo-tixer"
+	"github.com/mroobert/monorepo-tixer/auth"
+	"github.com/mroobert/monorepo-tixer/credential"
 	"github.com/mroobert/monorepo-tixer/env"
 	"github.com/mroobert/monorepo-tixer/httpio"
+	"github.com/mroobert/monorepo-tixer/payment"
 	"github.com/mroobert/monorepo-tixer/psql"
 )
 
 // Config represents the application configuration details.
 type config struct {
-	Env      string // the environment the application is running in.
-	Server   httpio.ServerConfig
-	Database psql.DbConfig
+	Env         string // the environment the application is running in.
+	Server      httpio.ServerConfig
+	Database    psql.DbConfig
+	Orders      ordersConfig
+	Payment     payment.Config
+	Auth        auth.Config
+	Credential  credential.Config
+	Tickets     tixer.TicketRules // the ticket rules of the deployment, overridden per organizer in the database
+	TicketStore string            // "postgres" or "memory", which keeps the tickets alone in the process, the other records stay in the database
+}
+
+// ordersConfig represents the configuration details for the order lifecycle.
+type ordersConfig struct {
+	ExpiryWindow   time.Duration // how long an unpaid order holds its ticket
+	ExpiryInterval time.Duration // how often the expiry worker looks for overdue orders and waitlist claims
+	ClaimWindow    time.Duration // how long a unit released to the waitlist is held for the next user
 }
 
 // NewConfig creates a new instance of Config.
@@ -88,17 +107,160 @@ func newConfig() (*config, error) {
 		return nil, fmt.Errorf("loading DB_QUERY_TIMEOUT failed: %w", err)
 	}
 
+	dbAutoMigrate, err := env.LoadBoolEnvOrDefault("DB_AUTO_MIGRATE", false)
+	if err != nil {
+		return nil, fmt.Errorf("loading DB_AUTO_MIGRATE failed: %w", err)
+	}
+
 	dbConfig := psql.DbConfig{
 		DSN:             dbDSN,
 		MaxOpenConns:    dbMaxOpenConns,
 		MinConns:        dbMinConns,
 		MaxConnIdleTime: dbMaxConnIdleTime,
 		QueryTimeout:    dbQueryTimeout,
+		AutoMigrate:     dbAutoMigrate,
+	}
+
+	// Load the orders configuration.
+	ordersExpiryWindow, err := env.LoadDurationEnvOrDefault("ORDERS_EXPIRY_WINDOW", 15*time.Minute)
+	if err != nil {
+		return nil, fmt.Errorf("loading ORDERS_EXPIRY_WINDOW failed: %w", err)
+	}
+	if ordersExpiryWindow <= 0 {
+		return nil, fmt.Errorf("loading ORDERS_EXPIRY_WINDOW failed: must be greater than zero, got %s", ordersExpiryWindow)
+	}
+
+	ordersExpiryInterval, err := env.LoadDurationEnvOrDefault("ORDERS_EXPIRY_INTERVAL", 30*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("loading ORDERS_EXPIRY_INTERVAL failed: %w", err)
+	}
+	if ordersExpiryInterval <= 0 {
+		return nil, fmt.Errorf("loading ORDERS_EXPIRY_INTERVAL failed: must be greater than zero, got %s", ordersExpiryInterval)
+	}
+
+	ordersClaimWindow, err := env.LoadDurationEnvOrDefault("WAITLIST_CLAIM_WINDOW", 30*time.Minute)
+	if err != nil {
+		return nil, fmt.Errorf("loading WAITLIST_CLAIM_WINDOW failed: %w", err)
+	}
+	if ordersClaimWindow <= 0 {
+		return nil, fmt.Errorf("loading WAITLIST_CLAIM_WINDOW failed: must be greater than zero, got %s", ordersClaimWindow)
+	}
+
+	ordersConfig := ordersConfig{
+		ExpiryWindow:   ordersExpiryWindow,
+		ExpiryInterval: ordersExpiryInterval,
+		ClaimWindow:    ordersClaimWindow,
+	}
+
+	// Load the payment configuration.
+	paymentGateway := env.LoadEnvOrDefault("PAYMENT_GATEWAY", "fake")
+
+	paymentTimeout, err := env.LoadDurationEnvOrDefault("PAYMENT_TIMEOUT", 5*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("loading PAYMENT_TIMEOUT failed: %w", err)
+	}
+
+	paymentConfig := payment.Config{
+		Gateway: paymentGateway,
+		Timeout: paymentTimeout,
+	}
+
+	// Load the authentication configuration.
+	authAlgorithm := env.LoadEnvOrDefault("AUTH_JWT_ALGORITHM", "HS256")
+	authSecret := env.LoadEnvOrDefault("AUTH_JWT_SECRET", "")
+	authPrivateKeyFile := env.LoadEnvOrDefault("AUTH_JWT_PRIVATE_KEY_FILE", "")
+	authPublicKeyFile := env.LoadEnvOrDefault("AUTH_JWT_PUBLIC_KEY_FILE", "")
+	authIssuer := env.LoadEnvOrDefault("AUTH_JWT_ISSUER", "tixer")
+
+	authTTL, err := env.LoadDurationEnvOrDefault("AUTH_JWT_TTL", 24*time.Hour)
+	if err != nil {
+		return nil, fmt.Errorf("loading AUTH_JWT_TTL failed: %w", err)
+	}
+
+	authConfig := auth.Config{
+		Algorithm:      authAlgorithm,
+		Secret:         authSecret,
+		PrivateKeyFile: authPrivateKeyFile,
+		PublicKeyFile:  authPublicKeyFile,
+		Issuer:         authIssuer,
+		TTL:            authTTL,
+	}
+
+	// Load the ticket credential configuration.
+	credentialSigningKey := env.LoadEnvOrDefault("CREDENTIAL_SIGNING_KEY", "")
+
+	credentialConfig := credential.Config{
+		SigningKey: credentialSigningKey,
+	}
+
+	// Load the ticket store.
+	ticketStore := env.LoadEnvOrDefault("TICKET_STORE", "postgres")
+	if ticketStore != "postgres" && ticketStore != "memory" {
+		return nil, fmt.Errorf("loading TICKET_STORE failed: must be postgres or memory, got %s", ticketStore)
+	}
+
+	// Load the ticket rules.
+	ticketRules := tixer.DefaultTicketRules()
+
+	ticketTitleMaxLength, err := env.LoadInt32EnvOrDefault("TICKET_TITLE_MAX_LENGTH", int32(ticketRules.MaxTitleLength))
+	if err != nil {
+		return nil, fmt.Errorf("loading TICKET_TITLE_MAX_LENGTH failed: %w", err)
+	}
+	ticketRules.MaxTitleLength = int(ticketTitleMaxLength)
+
+	ticketRules.MinPrice, err = env.LoadInt64EnvOrDefault("TICKET_PRICE_MIN", ticketRules.MinPrice)
+	if err != nil {
+		return nil, fmt.Errorf("loading TICKET_PRICE_MIN failed: %w", err)
+	}
+
+	ticketRules.MaxPrices, err = parseTicketMaxPrices(env.LoadListEnvOrDefault("TICKET_PRICE_MAX", nil))
+	if err != nil {
+		return nil, fmt.Errorf("loading TICKET_PRICE_MAX failed: %w", err)
+	}
+
+	for _, currency := range env.LoadListEnvOrDefault("TICKET_CURRENCIES", nil) {
+		ticketRules.AllowedCurrencies = append(ticketRules.AllowedCurrencies, tixer.Currency(strings.ToUpper(currency)))
+	}
+
+	ticketRules.ForbiddenWords = env.LoadListEnvOrDefault("TICKET_FORBIDDEN_WORDS", nil)
+
+	if valid, errs := ticketRules.Validate(); !valid {
+		return nil, fmt.Errorf("loading ticket rules failed: %v", errs)
 	}
 
 	return &config{
-		Env:      environment,
-		Server:   serverConfig,
-		Database: dbConfig,
+		Env:         environment,
+		Server:      serverConfig,
+		Database:    dbConfig,
+		Orders:      ordersConfig,
+		Payment:     paymentConfig,
+		Auth:        authConfig,
+		Credential:  credentialConfig,
+		Tickets:     ticketRules,
+		TicketStore: ticketStore,
 	}, nil
 }
+
+// parseTicketMaxPrices parses the highest ticket prices, given per currency
+// as a currency code and an amount in minor units, e.g. EUR:50000.
+func parseTicketMaxPrices(items []string) (map[tixer.Currency]int64, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	prices := make(map[tixer.Currency]int64, len(items))
+	for _, item := range items {
+		currency, amount, ok := strings.Cut(item, ":")
+		if !ok {
+			return nil, fmt.Errorf("price %q must be a currency and an amount separated by a colon", item)
+		}
+
+		value, err := strconv.ParseInt(strings.TrimSpace(amount), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("price %q must have an integer amount: %w", item, err)
+		}
+		prices[tixer.Currency(strings.ToUpper(strings.TrimSpace(currency)))] = value
+	}
+
+	return prices, nil
+}
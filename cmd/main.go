@@ -2,16 +2,26 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
+	_ "time/tzdata" // event time zones are validated against the IANA database, which may be missing on the host
 
+	tixer "github.com/mroobert/monorepo-tixer"
+	"github.com/mroobert/monorepo-tixer/auth"
+	"github.com/mroobert/monorepo-tixer/credential"
 	"github.com/mroobert/monorepo-tixer/env"
 	"github.com/mroobert/monorepo-tixer/httpio"
 	"github.com/mroobert/monorepo-tixer/logger"
+	"github.com/mroobert/monorepo-tixer/memory"
+	"github.com/mroobert/monorepo-tixer/migrations"
+	"github.com/mroobert/monorepo-tixer/payment"
 	"github.com/mroobert/monorepo-tixer/psql"
+	"github.com/mroobert/monorepo-tixer/worker"
 )
 
 func main() {
@@ -22,6 +32,35 @@ func main() {
 	}
 
 	ctx := context.Background()
+
+	// The import-rates command loads exchange rates instead of starting the application.
+	if len(os.Args) > 1 && os.Args[1] == "import-rates" {
+		if len(os.Args) != 3 {
+			fmt.Println("usage: cmd import-rates <file.csv>")
+			os.Exit(2)
+		}
+
+		if err := importRates(ctx, os.Args[2]); err != nil {
+			fmt.Println("failed to import the exchange rates: ", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// The migrate command changes the database schema instead of starting the application.
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := migrate(ctx, os.Args[2:]); err != nil {
+			if errors.Is(err, errMigrateUsage) {
+				fmt.Println(err)
+				os.Exit(2)
+			}
+
+			fmt.Println("failed to migrate the database: ", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	app, err := NewApplication(ctx)
 	if err != nil {
 		fmt.Println("failed to create the application: ", err)
@@ -30,15 +69,17 @@ func main() {
 	logger.SetLogger(app.Config.Env)
 
 	if err := app.Run(ctx); err != nil {
-		slog.Error("failed to run the application", err)
+		slog.Error("failed to run the application", slog.String("error", err.Error()))
 		os.Exit(1)
 	}
 }
 
 // Application holds the dependencies for the web application.
 type Application struct {
-	Config *config
-	Server *httpio.Server
+	Config       *config
+	Server       *httpio.Server
+	OrderExpirer *worker.OrderExpirer
+	ClaimExpirer *worker.ClaimExpirer
 }
 
 // NewApplication creates a new configured Application.
@@ -53,12 +94,81 @@ func NewApplication(ctx context.Context) (*Application, error) {
 		return nil, fmt.Errorf("connecting to db failed: %w", err)
 	}
 
-	server := httpio.NewServer(cfg.Server, cfg.Env)
-	server.TicketRepository = psql.NewTicketRepository(dbPool, cfg.Database.QueryTimeout)
+	if err := psql.CheckRowSecurity(ctx, dbPool); err != nil {
+		if errors.Is(err, psql.ErrDbRowSecurityBypassed) {
+			return nil, errors.New("DB_DSN must connect as a role that is neither a superuser nor has BYPASSRLS, the tenant isolation of the tickets relies on row-level security")
+		}
+		return nil, fmt.Errorf("checking db role failed: %w", err)
+	}
+
+	if cfg.Database.AutoMigrate {
+		migrator, err := psql.NewMigrator(dbPool, migrations.Files)
+		if err != nil {
+			return nil, fmt.Errorf("reading migrations failed: %w", err)
+		}
+
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("migrating db failed: %w", err)
+		}
+		slog.Info("applied the pending migrations", slog.Int("count", len(applied)))
+	}
+
+	paymentGateway, err := payment.NewGateway(cfg.Payment)
+	if err != nil {
+		return nil, fmt.Errorf("creating payment gateway failed: %w", err)
+	}
+
+	authenticator, err := auth.NewAuthenticator(cfg.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("creating authenticator failed: %w", err)
+	}
+
+	credentialSigner, err := credential.NewSigner(cfg.Credential)
+	if err != nil {
+		if errors.Is(err, credential.ErrSigningKeyMissing) {
+			return nil, errors.New("CREDENTIAL_SIGNING_KEY is required (base64 Ed25519 seed)")
+		}
+		return nil, fmt.Errorf("creating credential signer failed: %w", err)
+	}
+
+	var ticketStore tixer.TicketStore = psql.NewTicketRepository(dbPool, cfg.Database.QueryTimeout)
+	if cfg.TicketStore == "memory" {
+		slog.Warn("the tickets are kept in the process only and are reached by the ticket routes only, every other record is kept in the database")
+		ticketStore = memory.NewTicketStore()
+	}
+
+	waitlistRepository := psql.NewWaitlistRepository(dbPool, cfg.Database.QueryTimeout, cfg.Orders.ClaimWindow)
+	orderRepository := psql.NewOrderRepository(dbPool, cfg.Database.QueryTimeout, cfg.Orders.ExpiryWindow, cfg.Orders.ClaimWindow)
+
+	server := httpio.NewServer(cfg.Server, cfg.Env, authenticator)
+	server.TicketRepository = ticketStore
+	server.EventRepository = psql.NewEventRepository(dbPool, cfg.Database.QueryTimeout)
+	server.VenueRepository = psql.NewVenueRepository(dbPool, cfg.Database.QueryTimeout)
+	server.SeatRepository = psql.NewSeatRepository(dbPool, cfg.Database.QueryTimeout)
+	server.OrderRepository = orderRepository
+	server.PaymentRepository = psql.NewPaymentRepository(dbPool, cfg.Database.QueryTimeout)
+	server.RefundRepository = psql.NewRefundRepository(dbPool, cfg.Database.QueryTimeout, cfg.Orders.ClaimWindow)
+	server.UserRepository = psql.NewUserRepository(dbPool, cfg.Database.QueryTimeout)
+	server.RoleRepository = psql.NewRoleRepository(dbPool, cfg.Database.QueryTimeout)
+	server.APIKeyRepository = psql.NewAPIKeyRepository(dbPool, cfg.Database.QueryTimeout)
+	server.PaymentGateway = paymentGateway
+	server.WaitlistRepository = waitlistRepository
+	server.PromoCodeRepository = psql.NewPromoCodeRepository(dbPool, cfg.Database.QueryTimeout)
+	server.TransferRepository = psql.NewTransferRepository(dbPool, cfg.Database.QueryTimeout)
+	server.ResaleRepository = psql.NewResaleRepository(dbPool, cfg.Database.QueryTimeout)
+	server.CheckInRepository = psql.NewCheckInRepository(dbPool, cfg.Database.QueryTimeout)
+	server.CredentialSigner = credentialSigner
+	server.ExchangeRateRepository = psql.NewExchangeRateRepository(dbPool, cfg.Database.QueryTimeout)
+	server.TicketRulesRepository = psql.NewTicketRulesRepository(dbPool, cfg.Database.QueryTimeout)
+	server.TenantRepository = psql.NewTenantRepository(dbPool, cfg.Database.QueryTimeout)
+	server.TicketRules = cfg.Tickets
 
 	return &Application{
-		Config: cfg,
-		Server: server,
+		Config:       cfg,
+		Server:       server,
+		OrderExpirer: worker.NewOrderExpirer(orderRepository, cfg.Orders.ExpiryInterval),
+		ClaimExpirer: worker.NewClaimExpirer(waitlistRepository, cfg.Orders.ExpiryInterval),
 	}, nil
 }
 
@@ -69,6 +179,27 @@ func (a *Application) Run(ctx context.Context) error {
 
 	serverErrors := make(chan error, 1)
 
+	workerCtx, stopWorkers := context.WithCancel(ctx)
+	var workers sync.WaitGroup
+	defer workers.Wait()
+	defer stopWorkers()
+
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		slog.Info("starting the order expirer", slog.String("interval", a.Config.Orders.ExpiryInterval.String()))
+
+		a.OrderExpirer.Run(workerCtx)
+	}()
+
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		slog.Info("starting the waitlist claim expirer", slog.String("interval", a.Config.Orders.ExpiryInterval.String()))
+
+		a.ClaimExpirer.Run(workerCtx)
+	}()
+
 	go func() {
 		slog.Info("starting the server",
 			slog.String("addr", a.Config.Server.Addr),
@@ -86,6 +217,9 @@ func (a *Application) Run(ctx context.Context) error {
 		slog.Info("shutdown signal received", slog.String("signal", sig.String()))
 		defer slog.Info("shutdown complete", slog.String("signal", sig.String()))
 
+		stopWorkers()
+		workers.Wait()
+
 		ctx, cancel := context.WithTimeout(ctx, a.Config.Server.ShutdownTimeout)
 		defer cancel()
 
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mroobert/monorepo-tixer/migrations"
+	"github.com/mroobert/monorepo-tixer/psql"
+)
+
+// errMigrateUsage is returned when the migrate command is called with unknown arguments.
+var errMigrateUsage = errors.New("usage: cmd migrate up | down <n> | status | force <version>")
+
+// migrate applies, rolls back or reports the migrations embedded in the binary, as asked by args:
+//
+//	up               apply the pending migrations
+//	down <n>         roll back the last n applied migrations
+//	status           list the migrations and whether they are applied
+//	force <version>  record the schema as being at version without running any migration
+func migrate(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errMigrateUsage
+	}
+
+	var number int64
+	switch args[0] {
+	case "up", "status":
+		if len(args) != 1 {
+			return errMigrateUsage
+		}
+	case "down", "force":
+		if len(args) != 2 {
+			return errMigrateUsage
+		}
+
+		var err error
+		if number, err = strconv.ParseInt(args[1], 10, 64); err != nil || number < 0 || (args[0] == "down" && number == 0) {
+			return errMigrateUsage
+		}
+	default:
+		return errMigrateUsage
+	}
+
+	cfg, err := newConfig()
+	if err != nil {
+		return fmt.Errorf("loading config failed: %w", err)
+	}
+
+	dbPool, err := psql.NewPool(cfg.Database)
+	if err != nil {
+		return fmt.Errorf("connecting to db failed: %w", err)
+	}
+	defer dbPool.Close()
+
+	migrator, err := psql.NewMigrator(dbPool, migrations.Files)
+	if err != nil {
+		return fmt.Errorf("reading migrations failed: %w", err)
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, migration := range applied {
+			fmt.Printf("applied %d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Printf("applied %d migrations\n", len(applied))
+
+	case "down":
+		rolledBack, err := migrator.Down(ctx, int(number))
+		for _, migration := range rolledBack {
+			fmt.Printf("rolled back %d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Printf("rolled back %d migrations\n", len(rolledBack))
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+		return w.Flush()
+
+	case "force":
+		if err := migrator.Force(ctx, number); err != nil {
+			return err
+		}
+		fmt.Printf("forced the schema to version %d\n", number)
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	tixer "github.com/mroobert/monorepo-tixer"
+	"github.com/mroobert/monorepo-tixer/psql"
+)
+
+// importRates loads the exchange rates of a CSV file with the header base,quote,rate,valid_at
+// into the database. It is the command line counterpart of POST /v1/exchange-rates.
+func importRates(ctx context.Context, path string) error {
+	cfg, err := newConfig()
+	if err != nil {
+		return fmt.Errorf("loading config failed: %w", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("opening rates file failed: %w", err)
+	}
+	defer file.Close()
+
+	rates, err := tixer.ReadExchangeRatesCSV(file)
+	if err != nil {
+		return fmt.Errorf("reading rates file failed: %w", err)
+	}
+
+	dbPool, err := psql.NewPool(cfg.Database)
+	if err != nil {
+		return fmt.Errorf("connecting to db failed: %w", err)
+	}
+	defer dbPool.Close()
+
+	err = psql.NewExchangeRateRepository(dbPool, cfg.Database.QueryTimeout).Insert(ctx, rates)
+	if err != nil {
+		return fmt.Errorf("storing exchange rates failed: %w", err)
+	}
+
+	fmt.Printf("imported %d exchange rates\n", len(rates))
+	return nil
+}
@@ -0,0 +1,125 @@
+// This package provides support for issuing and verifying the signed credentials
+// that admit the holders of purchased tickets at the gate.
+package credential
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidCredential = errors.New("invalid credential")
+	ErrSigningKeyMissing = errors.New("signing key missing")
+)
+
+// Config represents the configuration details for the credential signer.
+type Config struct {
+	SigningKey string // the base64 encoded 32 byte Ed25519 seed used to sign credentials
+}
+
+// Claims represents the facts carried by a credential. A purchased ticket is held through
+// a paid order, so the order identifies the admitted unit; its version changes whenever
+// the order changes hands, which revokes the credentials issued to previous holders.
+type Claims struct {
+	OrderID  string    `json:"o"`
+	TicketID string    `json:"t"`
+	EventID  string    `json:"e,omitempty"`
+	Version  int32     `json:"v"`
+	IssuedAt time.Time `json:"-"`
+}
+
+// payload is the encoded form of the claims, with the issue time in Unix seconds to keep it compact.
+type payload struct {
+	Claims
+	IssuedAt int64 `json:"iat"`
+}
+
+// Signer issues and verifies credentials signed with Ed25519. A credential is the base64url
+// encoded JSON payload and signature joined by a dot, short enough to fit a QR code.
+// Since only the public key is needed to verify them, gate devices can check credentials offline.
+type Signer struct {
+	privateKey ed25519.PrivateKey
+	publicKey  ed25519.PublicKey
+}
+
+// NewSigner creates a new Signer from the configured seed.
+// It returns ErrSigningKeyMissing if no seed is configured.
+func NewSigner(cfg Config) (*Signer, error) {
+	if cfg.SigningKey == "" {
+		return nil, ErrSigningKeyMissing
+	}
+
+	seed, err := base64.StdEncoding.DecodeString(cfg.SigningKey)
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("signing key must be a base64 encoded %d byte Ed25519 seed", ed25519.SeedSize)
+	}
+
+	privateKey := ed25519.NewKeyFromSeed(seed)
+
+	return &Signer{
+		privateKey: privateKey,
+		publicKey:  privateKey.Public().(ed25519.PublicKey),
+	}, nil
+}
+
+// Issue creates a signed credential for the given claims, issued now.
+func (s *Signer) Issue(claims Claims) (string, error) {
+	data, err := json.Marshal(payload{Claims: claims, IssuedAt: time.Now().Unix()})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal credential: %w", err)
+	}
+
+	signature := ed25519.Sign(s.privateKey, data)
+
+	return base64.RawURLEncoding.EncodeToString(data) + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// Verify checks the signature of the credential and returns its claims.
+// It returns ErrInvalidCredential if the credential is malformed or was not signed by this signer.
+func (s *Signer) Verify(credential string) (Claims, error) {
+	encodedData, encodedSignature, ok := strings.Cut(credential, ".")
+	if !ok {
+		return Claims{}, fmt.Errorf("%w: malformed", ErrInvalidCredential)
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(encodedData)
+	if err != nil {
+		return Claims{}, fmt.Errorf("%w: %w", ErrInvalidCredential, err)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil {
+		return Claims{}, fmt.Errorf("%w: %w", ErrInvalidCredential, err)
+	}
+
+	if !ed25519.Verify(s.publicKey, data, signature) {
+		return Claims{}, fmt.Errorf("%w: signature mismatch", ErrInvalidCredential)
+	}
+
+	var p payload
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&p); err != nil {
+		return Claims{}, fmt.Errorf("%w: %w", ErrInvalidCredential, err)
+	}
+
+	if p.OrderID == "" || p.TicketID == "" {
+		return Claims{}, fmt.Errorf("%w: order or ticket missing", ErrInvalidCredential)
+	}
+
+	claims := p.Claims
+	claims.IssuedAt = time.Unix(p.IssuedAt, 0)
+
+	return claims, nil
+}
+
+// PublicKey returns the base64 encoded public key that verifies the issued credentials.
+func (s *Signer) PublicKey() string {
+	return base64.StdEncoding.EncodeToString(s.publicKey)
+}
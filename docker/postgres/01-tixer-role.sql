@@ -0,0 +1,4 @@
+-- The application connects as tixer, the owner of the tixer database. It is neither a superuser nor
+-- has BYPASSRLS, so the row-level security policies that isolate the tenants apply to it.
+CREATE ROLE tixer LOGIN PASSWORD 'tixer' NOSUPERUSER NOBYPASSRLS;
+CREATE DATABASE tixer OWNER tixer;
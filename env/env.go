@@ -92,3 +92,32 @@ func LoadDurationEnvOrDefault(env string, defaultValue time.Duration) (time.Dura
 
 	return d, nil
 }
+
+func LoadBoolEnvOrDefault(env string, defaultValue bool) (bool, error) {
+	v := os.Getenv(env)
+	if v == "" {
+		return defaultValue, nil
+	}
+
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return defaultValue, err
+	}
+	return b, nil
+}
+
+// LoadListEnvOrDefault loads a comma separated list, dropping the spaces around its items.
+func LoadListEnvOrDefault(env string, defaultValue []string) []string {
+	v := os.Getenv(env)
+	if v == "" {
+		return defaultValue
+	}
+
+	var items []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
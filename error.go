@@ -1,5 +1,10 @@
 package tixer
 
+import (
+	"errors"
+	"fmt"
+)
+
 // System error codes.
 const (
 	ECONFLICT      = "conflict"
@@ -10,3 +15,47 @@ const (
 	EUNAUTHORIZED  = "unauthorized"
 	EFORBIDDEN     = "forbidden"
 )
+
+// Error represents a domain error that carries one of the system error codes
+// and a message that is safe to show to the end user.
+type Error struct {
+	Code    string
+	Message string
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	return fmt.Sprintf("tixer error: code=%s message=%s", e.Code, e.Message)
+}
+
+// Errorf is a helper function to return an Error with a given code and formatted message.
+func Errorf(code string, format string, args ...any) *Error {
+	return &Error{
+		Code:    code,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+// ErrorCode returns the code of the error, if available.
+// Otherwise it returns EINTERNAL.
+func ErrorCode(err error) string {
+	var e *Error
+	if err == nil {
+		return ""
+	} else if errors.As(err, &e) {
+		return e.Code
+	}
+	return EINTERNAL
+}
+
+// ErrorMessage returns the message of the error, if available.
+// Otherwise it returns a generic error message.
+func ErrorMessage(err error) string {
+	var e *Error
+	if err == nil {
+		return ""
+	} else if errors.As(err, &e) {
+		return e.Message
+	}
+	return "internal error"
+}
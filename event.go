@@ -0,0 +1,71 @@
+package tixer
+
+import (
+	"time"
+)
+
+// Event represents an event, such as a concert or a match, for which tickets are sold.
+type Event struct {
+	ID            int64
+	PublicID      PublicID
+	OwnerID       *int64 // the ID of the user that organises the event
+	VenueID       *int64 // nil if the event has no venue, e.g. an online event
+	VenuePublicID *PublicID
+	Name          string
+	StartsAt      time.Time
+	EndsAt        time.Time
+	TimeZone      string // the IANA time zone of the event, used to show its local times
+	Version       int32
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// Validate checks event's fields to ensure that the basic business rules are met.
+// It returns a boolean indicating if the event is valid and a map of errors if it's not.
+func (e Event) Validate() (bool, map[string]string) {
+	errors := make(map[string]string)
+
+	if e.Name == "" {
+		errors["name"] = "must be provided"
+	}
+
+	if len(e.Name) > 150 {
+		errors["name"] = "must not be more than 150 characters long"
+	}
+
+	if e.StartsAt.IsZero() {
+		errors["startsAt"] = "must be provided"
+	}
+
+	if !e.EndsAt.After(e.StartsAt) {
+		errors["endsAt"] = "must be after the start time"
+	}
+
+	if e.TimeZone == "" {
+		errors["timeZone"] = "must be provided"
+	} else if _, err := time.LoadLocation(e.TimeZone); err != nil {
+		errors["timeZone"] = "must be a valid IANA time zone"
+	}
+
+	if len(errors) > 0 {
+		return false, errors
+	}
+
+	return true, nil
+}
+
+// Location returns the location of the event time zone.
+// It falls back to UTC if the time zone is unknown.
+func (e Event) Location() *time.Location {
+	loc, err := time.LoadLocation(e.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+
+	return loc
+}
+
+// OwnedBy reports whether the event belongs to the user with the given ID.
+func (e Event) OwnedBy(userID int64) bool {
+	return e.OwnerID != nil && *e.OwnerID == userID
+}
@@ -0,0 +1,179 @@
+package tixer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ExchangeRate represents the price of one unit of the base currency in the quote currency,
+// e.g. a EUR/USD rate of 1.08 means that 1 EUR costs 1.08 USD.
+type ExchangeRate struct {
+	Base    Currency
+	Quote   Currency
+	Rate    *big.Rat // kept as an exact fraction, so conversions never go through floats
+	ValidAt time.Time
+}
+
+// Validate checks exchange rate's fields to ensure that the basic business rules are met.
+// It returns a boolean indicating if the exchange rate is valid and a map of errors if it's not.
+func (r ExchangeRate) Validate() (bool, map[string]string) {
+	errors := make(map[string]string)
+
+	if err := ValidateCurrency(string(r.Base)); err != nil {
+		errors["base"] = "must be a supported currency"
+	}
+
+	if err := ValidateCurrency(string(r.Quote)); err != nil {
+		errors["quote"] = "must be a supported currency"
+	}
+
+	if r.Base == r.Quote {
+		errors["quote"] = "must be different from the base currency"
+	}
+
+	if r.Rate == nil || r.Rate.Sign() <= 0 {
+		errors["rate"] = "must be greater than 0"
+	}
+
+	if r.ValidAt.IsZero() {
+		errors["validAt"] = "must be provided"
+	}
+
+	if len(errors) > 0 {
+		return false, errors
+	}
+
+	return true, nil
+}
+
+// Invert returns the rate of the opposite direction, e.g. USD/EUR from EUR/USD.
+func (r ExchangeRate) Invert() ExchangeRate {
+	return ExchangeRate{
+		Base:    r.Quote,
+		Quote:   r.Base,
+		Rate:    new(big.Rat).Inv(r.Rate),
+		ValidAt: r.ValidAt,
+	}
+}
+
+// Convert converts money in the base currency to the quote currency.
+// The exact result is rounded to the nearest minor unit of the quote currency,
+// with halves rounded away from zero, e.g. 1.005 USD becomes 1.01 USD and 0.5 JPY becomes 1 JPY.
+// It returns an EUNPROCESSABLE error if the result does not fit in an int64 amount.
+func (r ExchangeRate) Convert(m Money) (Money, error) {
+	if m.Currency != r.Base {
+		return Money{}, fmt.Errorf("cannot convert %s with a %s/%s rate", m.Currency, r.Base, r.Quote)
+	}
+
+	// amount in quote minor units = amount in base minor units * rate * 10^quote decimals / 10^base decimals
+	exact := new(big.Rat).SetInt64(m.Amount)
+	exact.Mul(exact, r.Rate)
+	exact.Mul(exact, new(big.Rat).SetFrac(pow10(currencies[r.Quote].minorUnits), pow10(currencies[r.Base].minorUnits)))
+
+	amount, ok := roundHalfAwayFromZero(exact)
+	if !ok {
+		return Money{}, Errorf(EUNPROCESSABLE, "%d %s is too large to be converted to %s", m.Amount, m.Currency, r.Quote)
+	}
+
+	return Money{Amount: amount, Currency: r.Quote}, nil
+}
+
+// roundHalfAwayFromZero rounds an exact fraction to the nearest integer, with halves rounded away from zero.
+// It returns false if the rounded value does not fit in an int64.
+func roundHalfAwayFromZero(x *big.Rat) (int64, bool) {
+	num := new(big.Int).Abs(x.Num())
+	den := x.Denom()
+
+	// floor((2*|num| + den) / (2*den)) is |x| rounded half up.
+	rounded := new(big.Int).Mul(num, big.NewInt(2))
+	rounded.Add(rounded, den)
+	rounded.Quo(rounded, new(big.Int).Mul(den, big.NewInt(2)))
+
+	if x.Sign() < 0 {
+		rounded.Neg(rounded)
+	}
+
+	if !rounded.IsInt64() {
+		return 0, false
+	}
+
+	return rounded.Int64(), true
+}
+
+// pow10 returns 10 to the power of n.
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// ReadExchangeRatesCSV reads exchange rates from CSV data with the header
+// base,quote,rate,valid_at where rate is a decimal number and valid_at an RFC 3339 time.
+// It returns an EINVALID error that points to the offending line if the data is malformed.
+func ReadExchangeRatesCSV(r io.Reader) ([]ExchangeRate, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 4
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, Errorf(EINVALID, "the exchange rates file is empty")
+		}
+		return nil, Errorf(EINVALID, "invalid exchange rates file: %v", err)
+	}
+
+	if strings.Join(header, ",") != "base,quote,rate,valid_at" {
+		return nil, Errorf(EINVALID, "the exchange rates file must start with the header base,quote,rate,valid_at")
+	}
+
+	var rates []ExchangeRate
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, Errorf(EINVALID, "invalid exchange rates file: %v", err)
+		}
+
+		rate := ExchangeRate{
+			Base:  Currency(strings.ToUpper(record[0])),
+			Quote: Currency(strings.ToUpper(record[1])),
+		}
+
+		value, ok := new(big.Rat).SetString(record[2])
+		if !ok {
+			return nil, Errorf(EINVALID, "line %d: rate must be a decimal number", line)
+		}
+		rate.Rate = value
+
+		rate.ValidAt, err = time.Parse(time.RFC3339, record[3])
+		if err != nil {
+			return nil, Errorf(EINVALID, "line %d: valid_at must be an RFC 3339 time", line)
+		}
+
+		// The first field in alphabetical order is reported, so the same file always gets the same error.
+		if valid, errs := rate.Validate(); !valid {
+			fields := make([]string, 0, len(errs))
+			for field := range errs {
+				fields = append(fields, field)
+			}
+			slices.Sort(fields)
+
+			return nil, Errorf(EINVALID, "line %d: %s %s", line, fields[0], errs[fields[0]])
+		}
+
+		rates = append(rates, rate)
+	}
+
+	if len(rates) == 0 {
+		return nil, Errorf(EINVALID, "the exchange rates file holds no rates")
+	}
+
+	return rates, nil
+}
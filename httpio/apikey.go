@@ -0,0 +1,198 @@
+package httpio
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	nanoid "github.com/matoous/go-nanoid/v2"
+	tixer "github.com/mroobert/monorepo-tixer"
+	"github.com/mroobert/monorepo-tixer/psql"
+)
+
+// registerAPIKeyRoutes registers the API key resource routes with the server.
+func (s *Server) registerAPIKeyRoutes(r *http.ServeMux) {
+	r.HandleFunc("POST /v1/apikeys", s.authorize(tixer.PermAPIKeysWrite, s.handleCreateAPIKey))
+	r.HandleFunc("GET /v1/apikeys", s.authorize(tixer.PermAPIKeysWrite, s.handleReadAPIKeys))
+	r.HandleFunc("GET /v1/apikeys/{id}", s.authorize(tixer.PermAPIKeysWrite, s.handleReadAPIKey))
+	r.HandleFunc("DELETE /v1/apikeys/{id}", s.authorize(tixer.PermAPIKeysWrite, s.handleDeleteAPIKey))
+}
+
+// apiKeyResponseBody represents the expected fields in the response body for an API key resource.
+type apiKeyResponseBody struct {
+	PublicID   string             `json:"publicID"`
+	Name       string             `json:"name"`
+	Hint       string             `json:"hint"`
+	Scopes     []tixer.Permission `json:"scopes"`
+	ExpiresAt  *time.Time         `json:"expiresAt"`
+	LastUsedAt *time.Time         `json:"lastUsedAt"`
+	CreatedAt  time.Time          `json:"createdAt"`
+}
+
+// createAPIKeyRequestBody represents the expected request body for issuing a new API key.
+type createAPIKeyRequestBody struct {
+	Name      string             `json:"name"`
+	Scopes    []tixer.Permission `json:"scopes"`
+	ExpiresAt *time.Time         `json:"expiresAt"`
+}
+
+// handleCreateAPIKey handles issuing a new API key for the caller.
+// The plaintext key is only part of this response.
+func (s *Server) handleCreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var body createAPIKeyRequestBody
+
+	err := s.readJSON(w, r, &body)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	user, ok := s.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	publicID, err := nanoid.Generate(tixer.PublicIDAlphabet, tixer.PublicIDLength)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+		return
+	}
+
+	apiKey := tixer.APIKey{
+		PublicID:  tixer.PublicID(publicID),
+		UserID:    user.ID,
+		Name:      body.Name,
+		Scopes:    body.Scopes,
+		ExpiresAt: body.ExpiresAt,
+	}
+	if valid, errs := apiKey.Validate(); !valid {
+		s.failedValidationResponse(w, r, errs)
+		return
+	}
+
+	// A key cannot be used to gain permissions the caller does not hold.
+	for _, scope := range apiKey.Scopes {
+		if !s.can(r, scope) {
+			s.failedValidationResponse(w, r, map[string]string{"scopes": "contains scope " + string(scope) + " that you do not hold"})
+			return
+		}
+	}
+
+	key, err := apiKey.GenerateKey()
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+		return
+	}
+
+	apiKeyDB, err := s.APIKeyRepository.Insert(r.Context(), apiKey)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+		return
+	}
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("/v1/apikeys/%s", apiKeyDB.PublicID))
+
+	err = s.writeJSON(w, http.StatusCreated, envelope{"apiKey": toAPIKeyResponseBody(apiKeyDB), "key": key}, headers)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// handleReadAPIKeys handles reading the API keys of the caller.
+func (s *Server) handleReadAPIKeys(w http.ResponseWriter, r *http.Request) {
+	user, ok := s.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	apiKeysDB, err := s.APIKeyRepository.SelectMultiple(r.Context(), user.ID)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+		return
+	}
+
+	apiKeys := make([]apiKeyResponseBody, len(apiKeysDB))
+	for i, apiKeyDB := range apiKeysDB {
+		apiKeys[i] = toAPIKeyResponseBody(apiKeyDB)
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"apiKeys": apiKeys}, nil)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// handleReadAPIKey handles reading an API key of the caller.
+func (s *Server) handleReadAPIKey(w http.ResponseWriter, r *http.Request) {
+	id, err := s.readIDParam(r)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	user, ok := s.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	apiKeyDB, err := s.APIKeyRepository.SelectOne(r.Context(), id, user.ID)
+	if err != nil {
+		switch err {
+		case psql.ErrDbRecordNotFound:
+			s.notFoundResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"apiKey": toAPIKeyResponseBody(apiKeyDB)}, nil)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// handleDeleteAPIKey handles revoking an API key of the caller.
+func (s *Server) handleDeleteAPIKey(w http.ResponseWriter, r *http.Request) {
+	id, err := s.readIDParam(r)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	user, ok := s.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	err = s.APIKeyRepository.Delete(r.Context(), id, user.ID)
+	if err != nil {
+		switch err {
+		case psql.ErrDbRecordNotFound:
+			s.notFoundResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"message": "api key succesfully revoked"}, nil)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// toAPIKeyResponseBody converts an API key that was read from DB
+// to an API key that will be sent in the response body.
+func toAPIKeyResponseBody(apiKeyDB tixer.APIKey) apiKeyResponseBody {
+	return apiKeyResponseBody{
+		PublicID:   string(apiKeyDB.PublicID),
+		Name:       apiKeyDB.Name,
+		Hint:       apiKeyDB.Hint,
+		Scopes:     apiKeyDB.Scopes,
+		ExpiresAt:  apiKeyDB.ExpiresAt,
+		LastUsedAt: apiKeyDB.LastUsedAt,
+		CreatedAt:  apiKeyDB.CreatedAt,
+	}
+}
@@ -0,0 +1,101 @@
+package httpio
+
+import (
+	"context"
+	"net/http"
+	"slices"
+
+	tixer "github.com/mroobert/monorepo-tixer"
+	"github.com/mroobert/monorepo-tixer/httpio/rcontext"
+	"github.com/mroobert/monorepo-tixer/psql"
+)
+
+// authorize is the policy check that runs before a handler: it loads the permissions
+// of the caller's roles and rejects the request if the required permission is missing.
+// Anonymous callers get the permissions of the guest role, while callers using an API key
+// are further limited to the key scopes. The loaded permissions are put into the context,
+// so handlers can make finer decisions with can.
+func (s *Server) authorize(perm tixer.Permission, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var permissions tixer.Permissions
+		var err error
+
+		identity := rcontext.GetIdentity(r.Context())
+		if identity == nil {
+			permissions, err = s.RoleRepository.SelectRolePermissions(r.Context(), tixer.RoleGuest)
+		} else {
+			permissions, err = s.RoleRepository.SelectUserPermissions(r.Context(), tixer.PublicID(identity.Subject))
+		}
+		if err != nil {
+			s.internalServerErrorResponse(w, r, err)
+			return
+		}
+
+		if !permissions.Has(perm) {
+			if identity == nil {
+				s.authenticationRequiredResponse(w, r)
+			} else {
+				s.notPermittedResponse(w, r)
+			}
+			return
+		}
+
+		if identity != nil && identity.Scopes != nil {
+			if !identity.Scopes.Has(perm) {
+				s.missingScopeResponse(w, r, perm)
+				return
+			}
+			permissions = slices.DeleteFunc(permissions, func(p tixer.Permission) bool {
+				return !identity.Scopes.Has(p)
+			})
+		}
+
+		ctx := rcontext.SetPermissions(r.Context(), permissions)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	}
+}
+
+// can reports whether the caller holds the permission.
+// It only sees the permissions loaded by authorize.
+func (s *Server) can(r *http.Request, perm tixer.Permission) bool {
+	return rcontext.GetPermissions(r.Context()).Has(perm)
+}
+
+// currentUser reads the authenticated caller from the database.
+// It writes the error response itself and returns false if the caller cannot be resolved,
+// so handlers can simply return.
+func (s *Server) currentUser(w http.ResponseWriter, r *http.Request) (tixer.User, bool) {
+	identity := rcontext.GetIdentity(r.Context())
+	if identity == nil {
+		s.authenticationRequiredResponse(w, r)
+		return tixer.User{}, false
+	}
+
+	user, err := s.UserRepository.SelectOne(r.Context(), tixer.PublicID(identity.Subject))
+	if err != nil {
+		switch err {
+		case psql.ErrDbRecordNotFound:
+			s.invalidAuthenticationTokenResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return tixer.User{}, false
+	}
+
+	return user, true
+}
+
+// lookupAPIKey resolves a plaintext API key for the APIKeys middleware.
+func (s *Server) lookupAPIKey(ctx context.Context, key string) (tixer.APIKey, error) {
+	apiKey, err := s.APIKeyRepository.Use(ctx, tixer.HashAPIKey(key))
+	if err != nil {
+		switch err {
+		case psql.ErrDbRecordNotFound:
+			return tixer.APIKey{}, tixer.Errorf(tixer.EUNAUTHORIZED, "invalid or expired api key")
+		default:
+			return tixer.APIKey{}, err
+		}
+	}
+
+	return apiKey, nil
+}
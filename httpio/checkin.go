@@ -0,0 +1,203 @@
+package httpio
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	tixer "github.com/mroobert/monorepo-tixer"
+	"github.com/mroobert/monorepo-tixer/credential"
+	"github.com/mroobert/monorepo-tixer/psql"
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// credentialQRCodeSize is the width and height, in pixels, of the served QR codes.
+const credentialQRCodeSize = 320
+
+// registerCheckInRoutes registers the ticket credential and check-in routes with the server.
+func (s *Server) registerCheckInRoutes(r *http.ServeMux) {
+	r.HandleFunc("GET /v1/orders/{id}/credential", s.authorize(tixer.PermOrdersWrite, s.handleReadCredential))
+	r.HandleFunc("POST /v1/checkins", s.authorize(tixer.PermCheckInsWrite, s.handleCreateCheckIn))
+	r.HandleFunc("GET /v1/checkins/key", s.authorize(tixer.PermCheckInsWrite, s.handleReadCheckInKey))
+}
+
+// checkInResponseBody represents the expected fields in the response body for a check-in.
+type checkInResponseBody struct {
+	OrderID   string    `json:"orderID"`
+	TicketID  string    `json:"ticketID"`
+	EventID   string    `json:"eventID,omitempty"`
+	ScannedBy string    `json:"scannedBy"`
+	ScannedAt time.Time `json:"scannedAt"`
+}
+
+// handleReadCredential handles serving the signed credential of a paid order as a PNG QR code.
+// A new credential is issued on every request; the credentials issued before the order changed hands are revoked.
+func (s *Server) handleReadCredential(w http.ResponseWriter, r *http.Request) {
+	id, err := s.readIDParam(r)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	orderDB, err := s.OrderRepository.SelectOne(crossTenantContext(r), id)
+	if err != nil {
+		switch err {
+		case psql.ErrDbRecordNotFound:
+			s.notFoundResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if !s.canAccessOrder(w, r, orderDB) {
+		return
+	}
+
+	if orderDB.Status != tixer.OrderPaid {
+		s.orderNotPaidResponse(w, r)
+		return
+	}
+
+	ticketDB, err := s.TicketRepository.SelectOne(orderTenantContext(r, orderDB), orderDB.TicketPublicID)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+		return
+	}
+
+	claims := credential.Claims{
+		OrderID:  string(orderDB.PublicID),
+		TicketID: string(orderDB.TicketPublicID),
+		Version:  orderDB.Version,
+	}
+	if ticketDB.EventPublicID != nil {
+		claims.EventID = string(*ticketDB.EventPublicID)
+	}
+
+	token, err := s.CredentialSigner.Issue(claims)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+		return
+	}
+
+	png, err := qrcode.Encode(token, qrcode.Medium, credentialQRCodeSize)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, fmt.Errorf("failed to encode credential as QR code: %w", err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	w.Write(png)
+}
+
+// createCheckInRequestBody represents the expected request body for checking in a ticket holder.
+// The optional event ID is the event the gate admits to; tickets for other events are refused.
+type createCheckInRequestBody struct {
+	Credential string `json:"credential"`
+	EventID    string `json:"eventID"`
+}
+
+// handleCreateCheckIn handles gate staff scanning the credential of a ticket holder. The signature is
+// verified with the signing key, then the ticket is checked in once; a repeated scan is refused with
+// the details of the first one.
+func (s *Server) handleCreateCheckIn(w http.ResponseWriter, r *http.Request) {
+	var body createCheckInRequestBody
+
+	err := s.readJSON(w, r, &body)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	validator := newValidator()
+	if validator.validateCreateCheckInRequestBody(body); !validator.valid() {
+		s.failedValidationResponse(w, r, validator.errors)
+		return
+	}
+
+	claims, err := s.CredentialSigner.Verify(body.Credential)
+	if err != nil {
+		s.failedValidationResponse(w, r, map[string]string{"credential": "must be a valid ticket credential"})
+		return
+	}
+
+	if body.EventID != "" && claims.EventID != body.EventID {
+		s.wrongEventResponse(w, r)
+		return
+	}
+
+	user, ok := s.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	orderDB, err := s.OrderRepository.SelectOne(crossTenantContext(r), tixer.PublicID(claims.OrderID))
+	if err != nil {
+		switch err {
+		case psql.ErrDbRecordNotFound:
+			s.credentialRevokedResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if string(orderDB.TicketPublicID) != claims.TicketID {
+		s.credentialRevokedResponse(w, r)
+		return
+	}
+
+	// The scan is made for the organizer that sold the ticket, only its own gate staff can admit the holder.
+	if (user.TenantID == nil || *user.TenantID != orderDB.TenantID) && !s.can(r, tixer.PermTicketsManage) {
+		s.notTenantMemberResponse(w, r)
+		return
+	}
+
+	checkInDB, err := s.CheckInRepository.Insert(orderTenantContext(r, orderDB), tixer.CheckIn{
+		OrderID:     orderDB.ID,
+		ScannedByID: user.ID,
+	}, claims.Version)
+	if err != nil {
+		switch err {
+		case psql.ErrDbAlreadyCheckedIn:
+			s.alreadyCheckedInResponse(w, r, checkInDB)
+		case psql.ErrDbEditConflict:
+			s.credentialRevokedResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusCreated, envelope{"checkIn": toCheckInResponseBody(checkInDB)}, nil)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// handleReadCheckInKey handles reading the public key that verifies the credentials,
+// so gate devices can check them while offline.
+func (s *Server) handleReadCheckInKey(w http.ResponseWriter, r *http.Request) {
+	err := s.writeJSON(w, http.StatusOK, envelope{"algorithm": "Ed25519", "publicKey": s.CredentialSigner.PublicKey()}, nil)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// toCheckInResponseBody converts a check-in that was read from DB
+// to a check-in that will be sent in the response body.
+func toCheckInResponseBody(checkInDB tixer.CheckIn) checkInResponseBody {
+	body := checkInResponseBody{
+		OrderID:   string(checkInDB.OrderPublicID),
+		TicketID:  string(checkInDB.TicketPublicID),
+		ScannedBy: checkInDB.ScannedBy,
+		ScannedAt: checkInDB.ScannedAt,
+	}
+	if checkInDB.EventPublicID != nil {
+		body.EventID = string(*checkInDB.EventPublicID)
+	}
+
+	return body
+}
@@ -1,12 +1,26 @@
 package httpio
 
 import (
+	"fmt"
 	"log/slog"
 	"net/http"
+	"time"
 
 	tixer "github.com/mroobert/monorepo-tixer"
+	"github.com/mroobert/monorepo-tixer/httpio/mid"
 )
 
+// errorStatuses maps the system error codes to HTTP status codes.
+var errorStatuses = map[string]int{
+	tixer.ECONFLICT:      http.StatusConflict,
+	tixer.EINTERNAL:      http.StatusInternalServerError,
+	tixer.EINVALID:       http.StatusBadRequest,
+	tixer.EUNPROCESSABLE: http.StatusUnprocessableEntity,
+	tixer.ENOTFOUND:      http.StatusNotFound,
+	tixer.EUNAUTHORIZED:  http.StatusUnauthorized,
+	tixer.EFORBIDDEN:     http.StatusForbidden,
+}
+
 func (s *Server) logError(r *http.Request, err error) {
 	slog.Error("encountered an error",
 		slog.String("error", err.Error()),
@@ -28,6 +42,18 @@ func (s *Server) errorResponse(w http.ResponseWriter, r *http.Request, status in
 	}
 }
 
+// domainErrorResponse writes the response for an error returned by the domain rules.
+// Errors that are not a *tixer.Error are treated as internal errors.
+func (s *Server) domainErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	code := tixer.ErrorCode(err)
+	if code == tixer.EINTERNAL {
+		s.internalServerErrorResponse(w, r, err)
+		return
+	}
+
+	s.errorResponse(w, r, errorStatuses[code], code, tixer.ErrorMessage(err))
+}
+
 func (s *Server) internalServerErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
 	s.logError(r, err)
 
@@ -49,6 +75,185 @@ func (s *Server) editConflictResponse(w http.ResponseWriter, r *http.Request) {
 	s.errorResponse(w, r, http.StatusConflict, tixer.ECONFLICT, message)
 }
 
+func (s *Server) ticketUnavailableResponse(w http.ResponseWriter, r *http.Request) {
+	message := "the ticket is sold out"
+	s.errorResponse(w, r, http.StatusConflict, tixer.ECONFLICT, message)
+}
+
+func (s *Server) ticketNotPublishedResponse(w http.ResponseWriter, r *http.Request) {
+	message := "the ticket is not published"
+	s.errorResponse(w, r, http.StatusConflict, tixer.ECONFLICT, message)
+}
+
+func (s *Server) tenantRequiredResponse(w http.ResponseWriter, r *http.Request) {
+	message := fmt.Sprintf("the request must be made for a tenant, name it with the %s header", mid.TenantHeader)
+	s.errorResponse(w, r, http.StatusBadRequest, tixer.EINVALID, message)
+}
+
+func (s *Server) notTenantMemberResponse(w http.ResponseWriter, r *http.Request) {
+	message := "you do not work for the tenant the request is made for"
+	s.errorResponse(w, r, http.StatusForbidden, tixer.EFORBIDDEN, message)
+}
+
+func (s *Server) seatTakenResponse(w http.ResponseWriter, r *http.Request) {
+	message := "a ticket was already issued for this seat at the event"
+	s.errorResponse(w, r, http.StatusConflict, tixer.ECONFLICT, message)
+}
+
+func (s *Server) ticketOnSaleResponse(w http.ResponseWriter, r *http.Request) {
+	message := "the ticket is still on sale, order it instead of joining the waitlist"
+	s.errorResponse(w, r, http.StatusConflict, tixer.ECONFLICT, message)
+}
+
+func (s *Server) alreadyWaitingResponse(w http.ResponseWriter, r *http.Request) {
+	message := "you are already on the waitlist of this ticket"
+	s.errorResponse(w, r, http.StatusConflict, tixer.ECONFLICT, message)
+}
+
+func (s *Server) priceTierSoldOutResponse(w http.ResponseWriter, r *http.Request) {
+	message := "the current price tier sold out, please retry the order at the next tier"
+	s.errorResponse(w, r, http.StatusConflict, tixer.ECONFLICT, message)
+}
+
+func (s *Server) promoCodeExhaustedResponse(w http.ResponseWriter, r *http.Request) {
+	message := "the promo code has reached its redemption limit"
+	s.errorResponse(w, r, http.StatusConflict, tixer.ECONFLICT, message)
+}
+
+func (s *Server) duplicatePromoCodeResponse(w http.ResponseWriter, r *http.Request) {
+	message := "a promo code with this code already exists"
+	s.errorResponse(w, r, http.StatusConflict, tixer.ECONFLICT, message)
+}
+
+func (s *Server) orderNotPayableResponse(w http.ResponseWriter, r *http.Request) {
+	message := "the order is not awaiting payment"
+	s.errorResponse(w, r, http.StatusConflict, tixer.ECONFLICT, message)
+}
+
+func (s *Server) orderNotCancellableResponse(w http.ResponseWriter, r *http.Request) {
+	message := "the order can no longer be cancelled"
+	s.errorResponse(w, r, http.StatusConflict, tixer.ECONFLICT, message)
+}
+
+func (s *Server) recipientOutsideTenantResponse(w http.ResponseWriter, r *http.Request) {
+	message := "only the users that work for the organizer of the ticket can take it over"
+	s.errorResponse(w, r, http.StatusForbidden, tixer.EFORBIDDEN, message)
+}
+
+func (s *Server) transferPendingResponse(w http.ResponseWriter, r *http.Request) {
+	message := "the ticket already has a pending transfer"
+	s.errorResponse(w, r, http.StatusConflict, tixer.ECONFLICT, message)
+}
+
+func (s *Server) orderNotListableResponse(w http.ResponseWriter, r *http.Request) {
+	message := "only paid orders that were not checked in can be listed for resale"
+	s.errorResponse(w, r, http.StatusConflict, tixer.ECONFLICT, message)
+}
+
+func (s *Server) orderNotRefundableResponse(w http.ResponseWriter, r *http.Request) {
+	message := "the order changed hands or was checked in after it was paid and can no longer be refunded"
+	s.errorResponse(w, r, http.StatusConflict, tixer.ECONFLICT, message)
+}
+
+func (s *Server) orderListedResponse(w http.ResponseWriter, r *http.Request) {
+	message := "the order is listed for resale, withdraw the listing first"
+	s.errorResponse(w, r, http.StatusConflict, tixer.ECONFLICT, message)
+}
+
+func (s *Server) listingUnavailableResponse(w http.ResponseWriter, r *http.Request) {
+	message := "the listing is no longer available"
+	s.errorResponse(w, r, http.StatusConflict, tixer.ECONFLICT, message)
+}
+
+func (s *Server) ownListingResponse(w http.ResponseWriter, r *http.Request) {
+	message := "you cannot purchase your own listing"
+	s.errorResponse(w, r, http.StatusConflict, tixer.ECONFLICT, message)
+}
+
+func (s *Server) orderNotPaidResponse(w http.ResponseWriter, r *http.Request) {
+	message := "credentials are only issued for paid orders"
+	s.errorResponse(w, r, http.StatusConflict, tixer.ECONFLICT, message)
+}
+
+func (s *Server) credentialRevokedResponse(w http.ResponseWriter, r *http.Request) {
+	message := "the credential was revoked, the ticket was refunded or changed hands"
+	s.errorResponse(w, r, http.StatusConflict, tixer.ECONFLICT, message)
+}
+
+func (s *Server) wrongEventResponse(w http.ResponseWriter, r *http.Request) {
+	message := "the ticket is not valid for this event"
+	s.errorResponse(w, r, http.StatusConflict, tixer.ECONFLICT, message)
+}
+
+func (s *Server) alreadyCheckedInResponse(w http.ResponseWriter, r *http.Request, checkIn tixer.CheckIn) {
+	message := fmt.Sprintf("the ticket was already checked in at %s by %s", checkIn.ScannedAt.Format(time.RFC3339), checkIn.ScannedBy)
+	s.errorResponse(w, r, http.StatusConflict, tixer.ECONFLICT, message)
+}
+
+func (s *Server) paymentDeclinedResponse(w http.ResponseWriter, r *http.Request) {
+	message := "the payment was declined"
+	s.errorResponse(w, r, http.StatusPaymentRequired, tixer.EUNPROCESSABLE, message)
+}
+
+func (s *Server) paymentTimeoutResponse(w http.ResponseWriter, r *http.Request) {
+	message := "the payment provider did not respond in time, please try again"
+	s.errorResponse(w, r, http.StatusGatewayTimeout, tixer.EINTERNAL, message)
+}
+
+func (s *Server) recordInUseResponse(w http.ResponseWriter, r *http.Request) {
+	message := "the resource is referenced by other records and cannot be removed"
+	s.errorResponse(w, r, http.StatusConflict, tixer.ECONFLICT, message)
+}
+
+func (s *Server) duplicateEmailResponse(w http.ResponseWriter, r *http.Request) {
+	message := "a user with this email address already exists"
+	s.errorResponse(w, r, http.StatusConflict, tixer.ECONFLICT, message)
+}
+
+func (s *Server) invalidCredentialsResponse(w http.ResponseWriter, r *http.Request) {
+	message := "invalid authentication credentials"
+	s.errorResponse(w, r, http.StatusUnauthorized, tixer.EUNAUTHORIZED, message)
+}
+
+func (s *Server) authenticationRequiredResponse(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("WWW-Authenticate", "Bearer")
+
+	message := "you must be authenticated to access this resource"
+	s.errorResponse(w, r, http.StatusUnauthorized, tixer.EUNAUTHORIZED, message)
+}
+
+func (s *Server) invalidAuthenticationTokenResponse(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("WWW-Authenticate", "Bearer")
+
+	message := "invalid or missing authentication token"
+	s.errorResponse(w, r, http.StatusUnauthorized, tixer.EUNAUTHORIZED, message)
+}
+
+func (s *Server) notPermittedResponse(w http.ResponseWriter, r *http.Request) {
+	message := "your user account doesn't have the necessary permissions to access this resource"
+	s.errorResponse(w, r, http.StatusForbidden, tixer.EFORBIDDEN, message)
+}
+
+func (s *Server) missingScopeResponse(w http.ResponseWriter, r *http.Request, scope tixer.Permission) {
+	message := fmt.Sprintf("the api key doesn't have the %s scope required to access this resource", scope)
+	s.errorResponse(w, r, http.StatusForbidden, tixer.EFORBIDDEN, message)
+}
+
+func (s *Server) notOwnerResponse(w http.ResponseWriter, r *http.Request) {
+	message := "you do not own this resource"
+	s.errorResponse(w, r, http.StatusForbidden, tixer.EFORBIDDEN, message)
+}
+
+func (s *Server) duplicateRoleResponse(w http.ResponseWriter, r *http.Request) {
+	message := "a role with this name already exists"
+	s.errorResponse(w, r, http.StatusConflict, tixer.ECONFLICT, message)
+}
+
+func (s *Server) builtInRoleResponse(w http.ResponseWriter, r *http.Request) {
+	message := "built-in roles cannot be deleted"
+	s.errorResponse(w, r, http.StatusConflict, tixer.ECONFLICT, message)
+}
+
 func (s *Server) failedValidationResponse(w http.ResponseWriter, r *http.Request, errors map[string]string) {
 	env := envelope{"error": map[string]any{
 		"code":        tixer.EUNPROCESSABLE,
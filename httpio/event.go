@@ -0,0 +1,366 @@
+package httpio
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	nanoid "github.com/matoous/go-nanoid/v2"
+	tixer "github.com/mroobert/monorepo-tixer"
+	"github.com/mroobert/monorepo-tixer/psql"
+)
+
+// registerEventRoutes registers the event resource routes with the server.
+func (s *Server) registerEventRoutes(r *http.ServeMux) {
+	r.HandleFunc("POST /v1/events", s.authorize(tixer.PermEventsWrite, s.handleCreateEvent))
+	r.HandleFunc("GET /v1/events", s.authorize(tixer.PermTicketsRead, s.handleReadEvents))
+	r.HandleFunc("GET /v1/events/{id}", s.authorize(tixer.PermTicketsRead, s.handleReadEvent))
+	r.HandleFunc("PATCH /v1/events/{id}", s.authorize(tixer.PermEventsWrite, s.handleUpdateEvent))
+	r.HandleFunc("DELETE /v1/events/{id}", s.authorize(tixer.PermEventsWrite, s.handleDeleteEvent))
+}
+
+// eventResponseBody represents the expected fields in the response body for an event resource.
+// The start and end times are expressed in the time zone of the event.
+type eventResponseBody struct {
+	PublicID string    `json:"publicID"`
+	VenueID  *string   `json:"venueID,omitempty"`
+	Name     string    `json:"name"`
+	StartsAt time.Time `json:"startsAt"`
+	EndsAt   time.Time `json:"endsAt"`
+	TimeZone string    `json:"timeZone"`
+	Version  int32     `json:"version"`
+}
+
+// createEventRequestBody represents the expected request body for creating a new event.
+type createEventRequestBody struct {
+	VenueID  string    `json:"venueID"`
+	Name     string    `json:"name"`
+	StartsAt time.Time `json:"startsAt"`
+	EndsAt   time.Time `json:"endsAt"`
+	TimeZone string    `json:"timeZone"`
+}
+
+// handleCreateEvent handles the creation of a new event.
+func (s *Server) handleCreateEvent(w http.ResponseWriter, r *http.Request) {
+	var body createEventRequestBody
+
+	err := s.readJSON(w, r, &body)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	validator := newValidator()
+	if validator.validateCreateEventRequestBody(body); !validator.valid() {
+		s.failedValidationResponse(w, r, validator.errors)
+		return
+	}
+
+	user, ok := s.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	publicID, err := nanoid.Generate(tixer.PublicIDAlphabet, tixer.PublicIDLength)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+		return
+	}
+
+	event := tixer.Event{
+		PublicID: tixer.PublicID(publicID),
+		OwnerID:  &user.ID,
+		Name:     body.Name,
+		StartsAt: body.StartsAt,
+		EndsAt:   body.EndsAt,
+		TimeZone: body.TimeZone,
+	}
+	if valid, errs := event.Validate(); !valid {
+		s.failedValidationResponse(w, r, errs)
+		return
+	}
+
+	if body.VenueID != "" {
+		if !s.setEventVenue(w, r, &event, tixer.PublicID(body.VenueID)) {
+			return
+		}
+	}
+
+	eventDB, err := s.EventRepository.Insert(r.Context(), event)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+		return
+	}
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("/v1/events/%s", eventDB.PublicID))
+
+	err = s.writeJSON(w, http.StatusCreated, envelope{"event": toEventResponseBody(eventDB)}, headers)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// eventUrlQs represents the expected query string parameters for reading events.
+type eventUrlQs struct {
+	name     string
+	venue    string
+	page     int
+	pageSize int
+	sort     string
+}
+
+// handleReadEvents handles reading events from the system.
+func (s *Server) handleReadEvents(w http.ResponseWriter, r *http.Request) {
+	sortSafeList := []string{"starts_at", "name", "id", "-starts_at", "-name", "-id"}
+
+	validator := newValidator()
+	qs := validator.validateEventUrlValues(r.URL.Query(), sortSafeList)
+	if !validator.valid() {
+		s.failedValidationResponse(w, r, validator.errors)
+		return
+	}
+
+	sorter, err := psql.NewSorter(qs.sort, sortSafeList)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	paginator := psql.NewPaginator(qs.page, qs.pageSize)
+
+	filter := psql.EventFilter{
+		Name:          qs.name,
+		Limit:         paginator.Limit(),
+		Offset:        paginator.Offset(),
+		SortColumn:    sorter.Column(),
+		SortDirection: sorter.SortDirection(),
+	}
+	if qs.venue != "" {
+		venueID := tixer.PublicID(qs.venue)
+		filter.VenueID = &venueID
+	}
+
+	eventsDB, pagination, err := s.EventRepository.SelectMultiple(r.Context(), filter)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+		return
+	}
+
+	events := make([]eventResponseBody, len(eventsDB))
+	for i, eventDB := range eventsDB {
+		events[i] = toEventResponseBody(eventDB)
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"events": events, "pagination": pagination}, nil)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// handleReadEvent handles reading a single event from the system.
+func (s *Server) handleReadEvent(w http.ResponseWriter, r *http.Request) {
+	id, err := s.readIDParam(r)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	eventDB, err := s.EventRepository.SelectOne(r.Context(), id)
+	if err != nil {
+		switch err {
+		case psql.ErrDbRecordNotFound:
+			s.notFoundResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"event": toEventResponseBody(eventDB)}, nil)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// updateEventRequestBody represents the expected request body for updating an existing event.
+// An empty venueID removes the venue of the event.
+type updateEventRequestBody struct {
+	VenueID  *string    `json:"venueID"`
+	Name     *string    `json:"name"`
+	StartsAt *time.Time `json:"startsAt"`
+	EndsAt   *time.Time `json:"endsAt"`
+	TimeZone *string    `json:"timeZone"`
+}
+
+// handleUpdateEvent handles updating an event in the system.
+func (s *Server) handleUpdateEvent(w http.ResponseWriter, r *http.Request) {
+	var body updateEventRequestBody
+
+	id, err := s.readIDParam(r)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	user, ok := s.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	eventDB, err := s.EventRepository.SelectOne(r.Context(), id)
+	if err != nil {
+		switch err {
+		case psql.ErrDbRecordNotFound:
+			s.notFoundResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if !eventDB.OwnedBy(user.ID) && !s.can(r, tixer.PermEventsManage) {
+		s.notOwnerResponse(w, r)
+		return
+	}
+
+	err = s.readJSON(w, r, &body)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	validator := newValidator()
+	if validator.validateUpdateEventRequestBody(body); !validator.valid() {
+		s.failedValidationResponse(w, r, validator.errors)
+		return
+	}
+
+	if body.Name != nil {
+		eventDB.Name = *body.Name
+	}
+	if body.StartsAt != nil {
+		eventDB.StartsAt = *body.StartsAt
+	}
+	if body.EndsAt != nil {
+		eventDB.EndsAt = *body.EndsAt
+	}
+	if body.TimeZone != nil {
+		eventDB.TimeZone = *body.TimeZone
+	}
+
+	if valid, errs := eventDB.Validate(); !valid {
+		s.failedValidationResponse(w, r, errs)
+		return
+	}
+
+	switch {
+	case body.VenueID == nil:
+	case *body.VenueID == "":
+		eventDB.VenueID = nil
+		eventDB.VenuePublicID = nil
+	default:
+		if !s.setEventVenue(w, r, &eventDB, tixer.PublicID(*body.VenueID)) {
+			return
+		}
+	}
+
+	err = s.EventRepository.Update(r.Context(), &eventDB)
+	if err != nil {
+		switch err {
+		case psql.ErrDbEditConflict:
+			s.editConflictResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"event": toEventResponseBody(eventDB)}, nil)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// handleDeleteEvent handles the deletion of an event from the system.
+func (s *Server) handleDeleteEvent(w http.ResponseWriter, r *http.Request) {
+	id, err := s.readIDParam(r)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	user, ok := s.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	eventDB, err := s.EventRepository.SelectOne(r.Context(), id)
+	if err != nil {
+		switch err {
+		case psql.ErrDbRecordNotFound:
+			s.notFoundResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if !eventDB.OwnedBy(user.ID) && !s.can(r, tixer.PermEventsManage) {
+		s.notOwnerResponse(w, r)
+		return
+	}
+
+	err = s.EventRepository.Delete(r.Context(), id)
+	if err != nil {
+		switch err {
+		case psql.ErrDbRecordNotFound:
+			s.notFoundResponse(w, r)
+		case psql.ErrDbRecordInUse:
+			s.recordInUseResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"message": "event succesfully deleted"}, nil)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// setEventVenue resolves the venue with the given ID and sets it on the event.
+// It writes the error response itself and returns false if the venue cannot be resolved.
+func (s *Server) setEventVenue(w http.ResponseWriter, r *http.Request, event *tixer.Event, venueID tixer.PublicID) bool {
+	venueDB, err := s.VenueRepository.SelectOne(r.Context(), venueID)
+	if err != nil {
+		switch err {
+		case psql.ErrDbRecordNotFound:
+			s.failedValidationResponse(w, r, map[string]string{"venueID": "must reference an existing venue"})
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return false
+	}
+
+	event.VenueID = &venueDB.ID
+	event.VenuePublicID = &venueDB.PublicID
+	return true
+}
+
+// toEventResponseBody converts an event that was read from DB
+// to an event that will be sent in the response body.
+func toEventResponseBody(eventDB tixer.Event) eventResponseBody {
+	loc := eventDB.Location()
+
+	return eventResponseBody{
+		PublicID: string(eventDB.PublicID),
+		VenueID:  (*string)(eventDB.VenuePublicID),
+		Name:     eventDB.Name,
+		StartsAt: eventDB.StartsAt.In(loc),
+		EndsAt:   eventDB.EndsAt.In(loc),
+		TimeZone: eventDB.TimeZone,
+		Version:  eventDB.Version,
+	}
+}
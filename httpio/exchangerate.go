@@ -0,0 +1,121 @@
+package httpio
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	tixer "github.com/mroobert/monorepo-tixer"
+	"github.com/mroobert/monorepo-tixer/psql"
+)
+
+// registerExchangeRateRoutes registers the exchange rate routes with the server.
+func (s *Server) registerExchangeRateRoutes(r *http.ServeMux) {
+	r.HandleFunc("POST /v1/exchange-rates", s.authorize(tixer.PermExchangeRatesManage, s.handleLoadExchangeRates))
+}
+
+// exchangeRateResponseBody represents the expected fields in the response body for an exchange rate.
+type exchangeRateResponseBody struct {
+	Base    string    `json:"base"`
+	Quote   string    `json:"quote"`
+	Rate    string    `json:"rate"`
+	ValidAt time.Time `json:"validAt"`
+}
+
+// handleLoadExchangeRates handles loading exchange rates from a CSV request body
+// with the header base,quote,rate,valid_at.
+func (s *Server) handleLoadExchangeRates(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, int64(s.maxReqBodySize))
+
+	rates, err := tixer.ReadExchangeRatesCSV(r.Body)
+	if err != nil {
+		s.domainErrorResponse(w, r, err)
+		return
+	}
+
+	err = s.ExchangeRateRepository.Insert(r.Context(), rates)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+		return
+	}
+
+	body := make([]exchangeRateResponseBody, len(rates))
+	for i, rate := range rates {
+		body[i] = exchangeRateResponseBody{
+			Base:    string(rate.Base),
+			Quote:   string(rate.Quote),
+			Rate:    formatRate(rate),
+			ValidAt: rate.ValidAt,
+		}
+	}
+
+	err = s.writeJSON(w, http.StatusCreated, envelope{"exchangeRates": body}, nil)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// convertedPriceBody represents a price converted to the currency requested by the caller,
+// together with the exchange rate used for the conversion.
+type convertedPriceBody struct {
+	Amount      int64      `json:"amount"`
+	Currency    string     `json:"currency"`
+	Rate        string     `json:"rate"`
+	RateValidAt *time.Time `json:"rateValidAt,omitempty"`
+}
+
+// priceConverter converts prices to a single currency. The exchange rates are read once
+// per currency, so a request converting many prices loads every rate only once.
+type priceConverter struct {
+	target     tixer.Currency
+	rates      map[tixer.Currency]tixer.ExchangeRate
+	repository *psql.ExchangeRateRepository
+}
+
+// newPriceConverter creates a converter to the target currency.
+func (s *Server) newPriceConverter(target tixer.Currency) *priceConverter {
+	return &priceConverter{
+		target:     target,
+		rates:      make(map[tixer.Currency]tixer.ExchangeRate),
+		repository: s.ExchangeRateRepository,
+	}
+}
+
+// convert converts the price to the target currency with the latest exchange rate.
+// A price that is already in the target currency is returned with a rate of 1.
+// It returns psql.ErrDbRecordNotFound if no exchange rate was loaded for the currencies.
+func (c *priceConverter) convert(ctx context.Context, price tixer.Money) (*convertedPriceBody, error) {
+	if price.Currency == c.target {
+		return &convertedPriceBody{Amount: price.Amount, Currency: string(c.target), Rate: "1"}, nil
+	}
+
+	rate, ok := c.rates[price.Currency]
+	if !ok {
+		var err error
+		rate, err = c.repository.SelectLatest(ctx, price.Currency, c.target)
+		if err != nil {
+			return nil, err
+		}
+		c.rates[price.Currency] = rate
+	}
+
+	converted, err := rate.Convert(price)
+	if err != nil {
+		return nil, err
+	}
+
+	return &convertedPriceBody{
+		Amount:      converted.Amount,
+		Currency:    string(converted.Currency),
+		Rate:        formatRate(rate),
+		RateValidAt: &rate.ValidAt,
+	}, nil
+}
+
+// formatRate formats an exchange rate as a decimal number with up to 10 decimals, the precision it is stored with.
+func formatRate(rate tixer.ExchangeRate) string {
+	value := rate.Rate.FloatString(10)
+	value = strings.TrimRight(value, "0")
+	return strings.TrimSuffix(value, ".")
+}
@@ -0,0 +1,53 @@
+package mid
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	tixer "github.com/mroobert/monorepo-tixer"
+	"github.com/mroobert/monorepo-tixer/httpio/rcontext"
+)
+
+// APIKeyLookup resolves a plaintext API key to the key that was issued.
+// It returns an EUNAUTHORIZED error if the key is unknown or has expired.
+type APIKeyLookup func(ctx context.Context, key string) (tixer.APIKey, error)
+
+// APIKeys authenticates the requests that carry an API key as bearer token and puts
+// the identity of the user that issued the key, restricted to the key scopes, into the context.
+// Requests carrying any other kind of token are passed on untouched. The scopes are
+// enforced later, by the permission check of each route.
+func APIKeys(lookup APIKeyLookup) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		h := func(w http.ResponseWriter, r *http.Request) {
+			scheme, token, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+			if !strings.EqualFold(scheme, "Bearer") || !tixer.IsAPIKey(token) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Add("Vary", "Authorization")
+
+			key, err := lookup(r.Context(), token)
+			if err != nil {
+				switch tixer.ErrorCode(err) {
+				case tixer.EUNAUTHORIZED:
+					w.Header().Set("WWW-Authenticate", "Bearer")
+					writeError(w, http.StatusUnauthorized, tixer.EUNAUTHORIZED, tixer.ErrorMessage(err))
+				default:
+					slog.Error("failed to look up api key", slog.String("error", err.Error()))
+					writeError(w, http.StatusInternalServerError, tixer.EINTERNAL, "the server encountered a problem and could not process your request")
+				}
+				return
+			}
+
+			ctx := rcontext.SetIdentity(r.Context(), &rcontext.Identity{
+				Subject: string(key.UserPublicID),
+				Scopes:  key.Scopes,
+			})
+			next.ServeHTTP(w, r.WithContext(ctx))
+		}
+		return http.HandlerFunc(h)
+	}
+}
@@ -0,0 +1,51 @@
+package mid
+
+import (
+	"net/http"
+	"strings"
+
+	tixer "github.com/mroobert/monorepo-tixer"
+	"github.com/mroobert/monorepo-tixer/auth"
+	"github.com/mroobert/monorepo-tixer/httpio/rcontext"
+)
+
+// Authenticate verifies the bearer token of the request and puts the caller's identity into the context.
+// Requests without an Authorization header continue anonymously, while requests carrying
+// a malformed or invalid token are rejected. Requests already authenticated with an API key
+// are passed on untouched.
+func Authenticate(a *auth.Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		h := func(w http.ResponseWriter, r *http.Request) {
+			if rcontext.GetIdentity(r.Context()) != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Add("Vary", "Authorization")
+
+			header := r.Header.Get("Authorization")
+			if header == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			scheme, token, ok := strings.Cut(header, " ")
+			if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				writeError(w, http.StatusUnauthorized, tixer.EUNAUTHORIZED, "invalid or missing authentication token")
+				return
+			}
+
+			claims, err := a.Verify(token)
+			if err != nil {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				writeError(w, http.StatusUnauthorized, tixer.EUNAUTHORIZED, "invalid or missing authentication token")
+				return
+			}
+
+			ctx := rcontext.SetIdentity(r.Context(), &rcontext.Identity{Subject: claims.Subject})
+			next.ServeHTTP(w, r.WithContext(ctx))
+		}
+		return http.HandlerFunc(h)
+	}
+}
@@ -0,0 +1,23 @@
+package mid
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// writeError writes an error response with the same shape as the ones written by the handlers.
+func writeError(w http.ResponseWriter, status int, code string, message string) {
+	jsonData, err := json.MarshalIndent(map[string]any{"error": map[string]string{
+		"code":    code,
+		"message": message,
+	}}, "", "\t")
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	jsonData = append(jsonData, '\n')
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(jsonData)
+}
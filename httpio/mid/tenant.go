@@ -0,0 +1,49 @@
+package mid
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	tixer "github.com/mroobert/monorepo-tixer"
+)
+
+// TenantHeader is the request header that names the public ID of the tenant the request is made for.
+const TenantHeader = "X-Tenant-ID"
+
+// TenantLookup resolves the tenant a request is made for, from the tenant named by the request
+// (empty if none is named) and the identity in ctx. It returns false if the request is made for no tenant,
+// an EINVALID error if the named tenant does not exist and an EFORBIDDEN error if the caller may not act for it.
+type TenantLookup func(ctx context.Context, requested string) (int64, bool, error)
+
+// Tenant puts the tenant the request is made for into the context. The data of a tenant
+// can only be reached by the requests made for it, so it must run after the authentication.
+func Tenant(lookup TenantLookup) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		h := func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", TenantHeader)
+
+			tenantID, ok, err := lookup(r.Context(), r.Header.Get(TenantHeader))
+			if err != nil {
+				switch tixer.ErrorCode(err) {
+				case tixer.EINVALID:
+					writeError(w, http.StatusBadRequest, tixer.EINVALID, tixer.ErrorMessage(err))
+				case tixer.EFORBIDDEN:
+					writeError(w, http.StatusForbidden, tixer.EFORBIDDEN, tixer.ErrorMessage(err))
+				default:
+					slog.Error("failed to look up tenant", slog.String("error", err.Error()))
+					writeError(w, http.StatusInternalServerError, tixer.EINTERNAL, "the server encountered a problem and could not process your request")
+				}
+				return
+			}
+
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(tixer.NewTenantContext(r.Context(), tenantID)))
+		}
+		return http.HandlerFunc(h)
+	}
+}
@@ -0,0 +1,32 @@
+package httpio
+
+import (
+	"strings"
+
+	tixer "github.com/mroobert/monorepo-tixer"
+)
+
+// moneyBody represents an amount of money in the request and response bodies.
+// The amount is in the minor unit of the currency, e.g. cents for EUR.
+type moneyBody struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// toMoney converts the money of a request body to a domain value.
+// The currency code is accepted in any letter case.
+func (b moneyBody) toMoney() tixer.Money {
+	return tixer.Money{
+		Amount:   b.Amount,
+		Currency: tixer.Currency(strings.ToUpper(b.Currency)),
+	}
+}
+
+// toMoneyBody converts money that was read from DB
+// to money that will be sent in the response body.
+func toMoneyBody(m tixer.Money) moneyBody {
+	return moneyBody{
+		Amount:   m.Amount,
+		Currency: string(m.Currency),
+	}
+}
@@ -0,0 +1,485 @@
+package httpio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	nanoid "github.com/matoous/go-nanoid/v2"
+	tixer "github.com/mroobert/monorepo-tixer"
+	"github.com/mroobert/monorepo-tixer/psql"
+)
+
+// registerOrderRoutes registers the order resource routes with the server.
+func (s *Server) registerOrderRoutes(r *http.ServeMux) {
+	r.HandleFunc("POST /v1/orders", s.authorize(tixer.PermOrdersWrite, s.forTenant(s.handleCreateOrder)))
+	r.HandleFunc("GET /v1/orders", s.authorize(tixer.PermOrdersWrite, s.handleReadOrders))
+	r.HandleFunc("GET /v1/orders/{id}", s.authorize(tixer.PermOrdersWrite, s.handleReadOrder))
+	r.HandleFunc("POST /v1/orders/{id}/cancel", s.authorize(tixer.PermOrdersWrite, s.handleCancelOrder))
+}
+
+// orderResponseBody represents the expected fields in the response body for an order resource.
+type orderResponseBody struct {
+	PublicID    string             `json:"publicID"`
+	TicketID    string             `json:"ticketID"`
+	Status      string             `json:"status"`
+	Price       priceBreakdownBody `json:"price"`
+	ExpiresAt   time.Time          `json:"expiresAt"`
+	CheckedInAt *time.Time         `json:"checkedInAt,omitempty"`
+	Version     int32              `json:"version"`
+}
+
+// priceBreakdownBody represents the line items that add up to the total of an order.
+// Discounts are negative line items.
+type priceBreakdownBody struct {
+	LineItems []lineItemBody `json:"lineItems"`
+	Total     moneyBody      `json:"total"`
+}
+
+type lineItemBody struct {
+	Kind        string    `json:"kind"`
+	Description string    `json:"description"`
+	Amount      moneyBody `json:"amount"`
+}
+
+// createOrderRequestBody represents the expected request body for creating a new order.
+// PromoCode is optional and accepted in any letter case.
+type createOrderRequestBody struct {
+	TicketID  string `json:"ticketID"`
+	PromoCode string `json:"promoCode"`
+}
+
+// handleCreateOrder handles the reservation of a ticket by creating a new order.
+func (s *Server) handleCreateOrder(w http.ResponseWriter, r *http.Request) {
+	var body createOrderRequestBody
+
+	err := s.readJSON(w, r, &body)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	validator := newValidator()
+	if validator.validateCreateOrderRequestBody(body); !validator.valid() {
+		s.failedValidationResponse(w, r, validator.errors)
+		return
+	}
+
+	user, ok := s.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	ticketDB, err := s.TicketRepository.SelectOne(r.Context(), tixer.PublicID(body.TicketID))
+	if err != nil {
+		switch err {
+		case psql.ErrDbTenantRequired:
+			s.tenantRequiredResponse(w, r)
+		case psql.ErrDbRecordNotFound:
+			s.notFoundResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if !ticketDB.Status.Published() {
+		s.ticketNotPublishedResponse(w, r)
+		return
+	}
+
+	publicID, err := nanoid.Generate(tixer.PublicIDAlphabet, tixer.PublicIDLength)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+		return
+	}
+
+	rules, err := s.ticketRules(r.Context())
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+		return
+	}
+
+	now := time.Now()
+
+	order := tixer.Order{
+		PublicID:       tixer.PublicID(publicID),
+		BuyerID:        &user.ID,
+		TicketPublicID: ticketDB.PublicID,
+		Subtotal:       ticketDB.EffectivePrice(now, rules),
+		Discount:       tixer.Money{Currency: ticketDB.Price.Currency},
+	}
+	if tier, _ := ticketDB.CurrentTier(now); tier != nil {
+		order.PriceTierID = &tier.ID
+		order.PriceTier = &tier.Name
+	}
+
+	if body.PromoCode != "" {
+		promoDB, err := s.PromoCodeRepository.SelectByCode(crossTenantContext(r), strings.ToUpper(body.PromoCode))
+		if err != nil {
+			switch err {
+			case psql.ErrDbRecordNotFound:
+				s.failedValidationResponse(w, r, map[string]string{"promoCode": "must be an existing promo code"})
+			default:
+				s.internalServerErrorResponse(w, r, err)
+			}
+			return
+		}
+
+		order.Discount, err = promoDB.Discount(ticketDB, now, rules)
+		if err != nil {
+			s.domainErrorResponse(w, r, err)
+			return
+		}
+		order.PromoCodeID = &promoDB.ID
+		order.PromoCode = &promoDB.Code
+	}
+
+	order.Total = tixer.Money{Amount: order.Subtotal.Amount - order.Discount.Amount, Currency: order.Subtotal.Currency}
+
+	orderDB, err := s.OrderRepository.Insert(r.Context(), order)
+	if err != nil {
+		switch err {
+		case psql.ErrDbRecordNotFound:
+			s.notFoundResponse(w, r)
+		case psql.ErrDbTicketUnavailable:
+			s.ticketUnavailableResponse(w, r)
+		case psql.ErrDbTicketNotPublished:
+			s.ticketNotPublishedResponse(w, r)
+		case psql.ErrDbPromoCodeExhausted:
+			s.promoCodeExhaustedResponse(w, r)
+		case psql.ErrDbPriceTierSoldOut:
+			s.priceTierSoldOutResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("/v1/orders/%s", orderDB.PublicID))
+
+	err = s.writeJSON(w, http.StatusCreated, envelope{"order": toOrderResponseBody(orderDB)}, headers)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// orderUrlQs represents the expected query string parameters for reading orders.
+type orderUrlQs struct {
+	status   string
+	page     int
+	pageSize int
+}
+
+// handleReadOrders handles reading orders from the system.
+func (s *Server) handleReadOrders(w http.ResponseWriter, r *http.Request) {
+	validator := newValidator()
+	qs := validator.validateOrderUrlValues(r.URL.Query())
+	if !validator.valid() {
+		s.failedValidationResponse(w, r, validator.errors)
+		return
+	}
+
+	user, ok := s.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	paginator := psql.NewPaginator(qs.page, qs.pageSize)
+
+	filter := psql.OrderFilter{
+		Status: tixer.OrderStatus(qs.status),
+		Limit:  paginator.Limit(),
+		Offset: paginator.Offset(),
+	}
+	if !s.can(r, tixer.PermOrdersManage) {
+		filter.BuyerID = &user.ID
+	}
+
+	ordersDB, pagination, err := s.OrderRepository.SelectMultiple(crossTenantContext(r), filter)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+		return
+	}
+
+	orders := make([]orderResponseBody, len(ordersDB))
+	for i, orderDB := range ordersDB {
+		orders[i] = toOrderResponseBody(orderDB)
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"orders": orders, "pagination": pagination}, nil)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// handleReadOrder handles reading a single order from the system.
+func (s *Server) handleReadOrder(w http.ResponseWriter, r *http.Request) {
+	id, err := s.readIDParam(r)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	orderDB, err := s.OrderRepository.SelectOne(crossTenantContext(r), id)
+	if err != nil {
+		switch err {
+		case psql.ErrDbRecordNotFound:
+			s.notFoundResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if !s.canAccessOrder(w, r, orderDB) {
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"order": toOrderResponseBody(orderDB)}, nil)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// refundResponseBody represents the expected fields in the response body for a refund resource.
+type refundResponseBody struct {
+	PublicID string    `json:"publicID"`
+	OrderID  string    `json:"orderID"`
+	Amount   moneyBody `json:"amount"`
+	Reason   string    `json:"reason,omitempty"`
+}
+
+// cancelOrderRequestBody represents the optional request body for cancelling an order.
+// Amount requests a partial refund of a paid order, in the minor unit of the currency the order was paid in.
+// By default the maximum refundable amount is given back.
+type cancelOrderRequestBody struct {
+	Amount *int64 `json:"amount"`
+	Reason string `json:"reason"`
+}
+
+// handleCancelOrder handles the cancellation of an order. A pending order is simply cancelled,
+// while a paid order is refunded through the payment gateway according to the ticket's refund policy.
+// In both cases the ticket goes back on sale.
+func (s *Server) handleCancelOrder(w http.ResponseWriter, r *http.Request) {
+	var body cancelOrderRequestBody
+
+	id, err := s.readIDParam(r)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	if r.ContentLength != 0 {
+		err = s.readJSON(w, r, &body)
+		if err != nil {
+			s.badRequestResponse(w, r, err)
+			return
+		}
+	}
+
+	validator := newValidator()
+	if validator.validateCancelOrderRequestBody(body); !validator.valid() {
+		s.failedValidationResponse(w, r, validator.errors)
+		return
+	}
+
+	orderDB, err := s.OrderRepository.SelectOne(crossTenantContext(r), id)
+	if err != nil {
+		switch err {
+		case psql.ErrDbRecordNotFound:
+			s.notFoundResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if !s.canAccessOrder(w, r, orderDB) {
+		return
+	}
+
+	switch orderDB.Status {
+	case tixer.OrderPending:
+		s.cancelPendingOrder(w, r, orderDB)
+	case tixer.OrderPaid:
+		if !orderDB.Refundable() {
+			s.orderNotRefundableResponse(w, r)
+			return
+		}
+		s.refundPaidOrder(w, r, orderDB, body)
+	default:
+		s.orderNotCancellableResponse(w, r)
+	}
+}
+
+// canAccessOrder checks that the caller placed the order or is allowed to manage all orders.
+// It writes the error response itself and returns false if the access is denied.
+func (s *Server) canAccessOrder(w http.ResponseWriter, r *http.Request, orderDB tixer.Order) bool {
+	if s.can(r, tixer.PermOrdersManage) {
+		return true
+	}
+
+	user, ok := s.currentUser(w, r)
+	if !ok {
+		return false
+	}
+
+	if !orderDB.PlacedBy(user.ID) {
+		s.notOwnerResponse(w, r)
+		return false
+	}
+
+	return true
+}
+
+// cancelPendingOrder cancels an order that was not paid yet.
+func (s *Server) cancelPendingOrder(w http.ResponseWriter, r *http.Request, orderDB tixer.Order) {
+	err := s.OrderRepository.Cancel(orderTenantContext(r, orderDB), &orderDB)
+	if err != nil {
+		switch err {
+		case psql.ErrDbEditConflict:
+			s.editConflictResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"order": toOrderResponseBody(orderDB)}, nil)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// refundPaidOrder gives back the money of a paid order and cancels it.
+func (s *Server) refundPaidOrder(w http.ResponseWriter, r *http.Request, orderDB tixer.Order, body cancelOrderRequestBody) {
+	ticketDB, err := s.TicketRepository.SelectOne(orderTenantContext(r, orderDB), orderDB.TicketPublicID)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+		return
+	}
+
+	paymentDB, err := s.PaymentRepository.SelectSucceeded(r.Context(), orderDB.ID)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+		return
+	}
+
+	amount, err := ticketDB.RefundAmount(paymentDB.Amount, time.Now())
+	if err != nil {
+		s.domainErrorResponse(w, r, err)
+		return
+	}
+
+	if body.Amount != nil {
+		if *body.Amount > amount.Amount {
+			s.failedValidationResponse(w, r, map[string]string{
+				"amount": fmt.Sprintf("must not be greater than the refundable amount of %s", amount),
+			})
+			return
+		}
+		amount.Amount = *body.Amount
+	}
+
+	publicID, err := nanoid.Generate(tixer.PublicIDAlphabet, tixer.PublicIDLength)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+		return
+	}
+
+	refund := tixer.Refund{
+		PublicID:  tixer.PublicID(publicID),
+		PaymentID: paymentDB.ID,
+		Amount:    amount,
+		Reason:    body.Reason,
+	}
+
+	// The refund is recorded before the money moves, so a concurrent change of the order
+	// is refused before the buyer is refunded and not after.
+	refundDB, err := s.RefundRepository.Insert(orderTenantContext(r, orderDB), refund, &orderDB)
+	if err != nil {
+		switch err {
+		case psql.ErrDbEditConflict:
+			s.editConflictResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	// From here on the outcome of the refund is recorded even if the client went away.
+	ctx := context.WithoutCancel(orderTenantContext(r, orderDB))
+
+	if amount.Amount > 0 {
+		// The reference is the same for every attempt, so a retried refund is not given back twice.
+		refundDB.GatewayReference, err = s.PaymentGateway.Refund(ctx, tixer.RefundRequest{
+			Reference:       "refund_" + string(orderDB.PublicID),
+			ChargeReference: paymentDB.GatewayReference,
+			Amount:          amount,
+		})
+		if err != nil {
+			if abortErr := s.RefundRepository.Abort(ctx, refundDB, &orderDB); abortErr != nil {
+				s.logError(r, fmt.Errorf("failed to abort refund %s: %w", refundDB.PublicID, abortErr))
+			}
+
+			switch {
+			case errors.Is(err, tixer.ErrPaymentTimeout):
+				s.paymentTimeoutResponse(w, r)
+			default:
+				s.internalServerErrorResponse(w, r, err)
+			}
+			return
+		}
+	}
+
+	err = s.RefundRepository.Complete(ctx, &refundDB, &orderDB)
+	if err != nil {
+		// The money was given back: the refund stays pending and its order refunding, for reconciliation.
+		s.internalServerErrorResponse(w, r, fmt.Errorf("refund %s was given back but could not be completed: %w", refundDB.PublicID, err))
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{
+		"order": toOrderResponseBody(orderDB),
+		"refund": refundResponseBody{
+			PublicID: string(refundDB.PublicID),
+			OrderID:  string(refundDB.OrderPublicID),
+			Amount:   toMoneyBody(refundDB.Amount),
+			Reason:   refundDB.Reason,
+		},
+	}, nil)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// toOrderResponseBody converts an order that was read from DB
+// to an order that will be sent in the response body.
+func toOrderResponseBody(orderDB tixer.Order) orderResponseBody {
+	description := "ticket " + string(orderDB.TicketPublicID)
+	if orderDB.PriceTier != nil {
+		description += " (" + *orderDB.PriceTier + ")"
+	}
+
+	lineItems := []lineItemBody{{Kind: "ticket", Description: description, Amount: toMoneyBody(orderDB.Subtotal)}}
+	if orderDB.PromoCode != nil {
+		discount := toMoneyBody(orderDB.Discount)
+		discount.Amount = -discount.Amount
+		lineItems = append(lineItems, lineItemBody{Kind: "discount", Description: "promo code " + *orderDB.PromoCode, Amount: discount})
+	}
+
+	return orderResponseBody{
+		PublicID:    string(orderDB.PublicID),
+		TicketID:    string(orderDB.TicketPublicID),
+		Status:      string(orderDB.Status),
+		Price:       priceBreakdownBody{LineItems: lineItems, Total: toMoneyBody(orderDB.Total)},
+		ExpiresAt:   orderDB.ExpiresAt,
+		CheckedInAt: orderDB.CheckedInAt,
+		Version:     orderDB.Version,
+	}
+}
@@ -0,0 +1,162 @@
+package httpio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	nanoid "github.com/matoous/go-nanoid/v2"
+	tixer "github.com/mroobert/monorepo-tixer"
+	"github.com/mroobert/monorepo-tixer/psql"
+)
+
+// registerPaymentRoutes registers the payment resource routes with the server.
+func (s *Server) registerPaymentRoutes(r *http.ServeMux) {
+	r.HandleFunc("POST /v1/payments", s.authorize(tixer.PermOrdersWrite, s.handleCreatePayment))
+}
+
+// paymentResponseBody represents the expected fields in the response body for a payment resource.
+type paymentResponseBody struct {
+	PublicID string    `json:"publicID"`
+	OrderID  string    `json:"orderID"`
+	Amount   moneyBody `json:"amount"`
+	Status   string    `json:"status"`
+}
+
+// createPaymentRequestBody represents the expected request body for paying an order.
+// The amount is deliberately missing: it is always taken from the order total.
+type createPaymentRequestBody struct {
+	OrderID      string `json:"orderID"`
+	PaymentToken string `json:"paymentToken"`
+}
+
+// handleCreatePayment handles charging the buyer of a pending order. The order is paying
+// while it is charged, so the buyer cannot be charged twice by concurrent requests.
+// The charge reference is derived from the order rather than from the payment, so retrying
+// a charge whose outcome is unknown, e.g. after a gateway timeout, cannot charge the buyer twice.
+func (s *Server) handleCreatePayment(w http.ResponseWriter, r *http.Request) {
+	var body createPaymentRequestBody
+
+	err := s.readJSON(w, r, &body)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	validator := newValidator()
+	if validator.validateCreatePaymentRequestBody(body); !validator.valid() {
+		s.failedValidationResponse(w, r, validator.errors)
+		return
+	}
+
+	orderDB, err := s.OrderRepository.SelectOne(crossTenantContext(r), tixer.PublicID(body.OrderID))
+	if err != nil {
+		switch err {
+		case psql.ErrDbRecordNotFound:
+			s.notFoundResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if !s.canAccessOrder(w, r, orderDB) {
+		return
+	}
+
+	publicID, err := nanoid.Generate(tixer.PublicIDAlphabet, tixer.PublicIDLength)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+		return
+	}
+
+	attempt, err := s.PaymentRepository.StartPayment(r.Context(), &orderDB)
+	if err != nil {
+		switch err {
+		case psql.ErrDbOrderNotPayable:
+			s.orderNotPayableResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	payment := tixer.Payment{
+		PublicID:      tixer.PublicID(publicID),
+		OrderID:       orderDB.ID,
+		OrderPublicID: orderDB.PublicID,
+		Amount:        orderDB.Total,
+		Status:        tixer.PaymentSucceeded,
+	}
+
+	reference, chargeErr := s.PaymentGateway.Charge(r.Context(), tixer.Charge{
+		Reference: fmt.Sprintf("order_%s_%d", orderDB.PublicID, attempt),
+		Amount:    payment.Amount,
+		Source:    body.PaymentToken,
+	})
+	switch {
+	case chargeErr == nil:
+		payment.GatewayReference = reference
+	case errors.Is(chargeErr, tixer.ErrPaymentDeclined):
+		payment.Status = tixer.PaymentDeclined
+	default:
+		payment.Status = tixer.PaymentFailed
+	}
+
+	// The outcome of the charge is recorded even if the client went away, the order must not stay paying.
+	paymentDB, err := s.PaymentRepository.Insert(context.WithoutCancel(r.Context()), payment)
+	if err != nil {
+		switch err {
+		case psql.ErrDbOrderNotPayable:
+			s.logError(r, fmt.Errorf("order %s was charged after it stopped being payable", orderDB.PublicID))
+			s.reverseCharge(r, paymentDB)
+			s.orderNotPayableResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	switch {
+	case chargeErr == nil:
+	case errors.Is(chargeErr, tixer.ErrPaymentDeclined):
+		s.paymentDeclinedResponse(w, r)
+		return
+	case errors.Is(chargeErr, tixer.ErrPaymentTimeout):
+		s.paymentTimeoutResponse(w, r)
+		return
+	default:
+		s.internalServerErrorResponse(w, r, chargeErr)
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusCreated, envelope{"payment": toPaymentResponseBody(paymentDB)}, nil)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// reverseCharge refunds in full a charge that could not be applied to its order.
+// Failures are only logged, the payment row keeps the gateway reference for manual reconciliation.
+func (s *Server) reverseCharge(r *http.Request, paymentDB tixer.Payment) {
+	_, err := s.PaymentGateway.Refund(context.WithoutCancel(r.Context()), tixer.RefundRequest{
+		Reference:       "reversal_" + string(paymentDB.PublicID),
+		ChargeReference: paymentDB.GatewayReference,
+		Amount:          paymentDB.Amount,
+	})
+	if err != nil {
+		s.logError(r, fmt.Errorf("failed to reverse payment %s: %w", paymentDB.PublicID, err))
+	}
+}
+
+// toPaymentResponseBody converts a payment that was read from DB
+// to a payment that will be sent in the response body.
+func toPaymentResponseBody(paymentDB tixer.Payment) paymentResponseBody {
+	return paymentResponseBody{
+		PublicID: string(paymentDB.PublicID),
+		OrderID:  string(paymentDB.OrderPublicID),
+		Amount:   toMoneyBody(paymentDB.Amount),
+		Status:   string(paymentDB.Status),
+	}
+}
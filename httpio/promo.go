@@ -0,0 +1,266 @@
+package httpio
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	nanoid "github.com/matoous/go-nanoid/v2"
+	tixer "github.com/mroobert/monorepo-tixer"
+	"github.com/mroobert/monorepo-tixer/psql"
+)
+
+// registerPromoCodeRoutes registers the promo code resource routes with the server.
+func (s *Server) registerPromoCodeRoutes(r *http.ServeMux) {
+	r.HandleFunc("POST /v1/promo-codes", s.authorize(tixer.PermPromoCodesManage, s.forTenant(s.handleCreatePromoCode)))
+	r.HandleFunc("GET /v1/promo-codes", s.authorize(tixer.PermPromoCodesManage, s.handleReadPromoCodes))
+	r.HandleFunc("GET /v1/promo-codes/{id}", s.authorize(tixer.PermPromoCodesManage, s.handleReadPromoCode))
+	r.HandleFunc("DELETE /v1/promo-codes/{id}", s.authorize(tixer.PermPromoCodesManage, s.handleDeletePromoCode))
+}
+
+// promoCodeResponseBody represents the expected fields in the response body for a promo code resource.
+type promoCodeResponseBody struct {
+	PublicID              string     `json:"publicID"`
+	Code                  string     `json:"code"`
+	DiscountType          string     `json:"discountType"`
+	PercentOff            *int32     `json:"percentOff,omitempty"`
+	AmountOff             *moneyBody `json:"amountOff,omitempty"`
+	Scope                 string     `json:"scope"`
+	EventID               *string    `json:"eventID,omitempty"`
+	TicketID              *string    `json:"ticketID,omitempty"`
+	ValidFrom             *time.Time `json:"validFrom,omitempty"`
+	ValidUntil            *time.Time `json:"validUntil,omitempty"`
+	MaxRedemptions        *int32     `json:"maxRedemptions,omitempty"`
+	MaxRedemptionsPerUser *int32     `json:"maxRedemptionsPerUser,omitempty"`
+	Redemptions           int32      `json:"redemptions"`
+}
+
+// createPromoCodeRequestBody represents the expected request body for creating a new promo code.
+// The code is accepted in any letter case and stored in upper case.
+type createPromoCodeRequestBody struct {
+	Code                  string     `json:"code"`
+	DiscountType          string     `json:"discountType"`
+	PercentOff            int32      `json:"percentOff"`
+	AmountOff             moneyBody  `json:"amountOff"`
+	Scope                 string     `json:"scope"`
+	EventID               string     `json:"eventID"`
+	TicketID              string     `json:"ticketID"`
+	ValidFrom             *time.Time `json:"validFrom"`
+	ValidUntil            *time.Time `json:"validUntil"`
+	MaxRedemptions        *int32     `json:"maxRedemptions"`
+	MaxRedemptionsPerUser *int32     `json:"maxRedemptionsPerUser"`
+}
+
+// handleCreatePromoCode handles the creation of a new promo code.
+func (s *Server) handleCreatePromoCode(w http.ResponseWriter, r *http.Request) {
+	var body createPromoCodeRequestBody
+
+	err := s.readJSON(w, r, &body)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	validator := newValidator()
+	validator.validateCreatePromoCodeRequestBody(body)
+	if !validator.valid() {
+		s.failedValidationResponse(w, r, validator.errors)
+		return
+	}
+
+	publicID, err := nanoid.Generate(tixer.PublicIDAlphabet, tixer.PublicIDLength)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+		return
+	}
+
+	promo := tixer.PromoCode{
+		PublicID:              tixer.PublicID(publicID),
+		Code:                  strings.ToUpper(body.Code),
+		DiscountType:          tixer.DiscountType(body.DiscountType),
+		Scope:                 tixer.PromoScope(body.Scope),
+		ValidFrom:             body.ValidFrom,
+		ValidUntil:            body.ValidUntil,
+		MaxRedemptions:        body.MaxRedemptions,
+		MaxRedemptionsPerUser: body.MaxRedemptionsPerUser,
+	}
+
+	switch promo.DiscountType {
+	case tixer.DiscountPercentage:
+		promo.PercentOff = body.PercentOff
+	case tixer.DiscountFixed:
+		promo.AmountOff = body.AmountOff.toMoney()
+	}
+
+	switch promo.Scope {
+	case tixer.PromoScopeEvent:
+		eventDB, err := s.EventRepository.SelectOne(r.Context(), tixer.PublicID(body.EventID))
+		if err != nil {
+			switch err {
+			case psql.ErrDbRecordNotFound:
+				s.failedValidationResponse(w, r, map[string]string{"eventID": "must be an existing event"})
+			default:
+				s.internalServerErrorResponse(w, r, err)
+			}
+			return
+		}
+		promo.EventID = &eventDB.ID
+	case tixer.PromoScopeTicket:
+		ticketDB, err := s.TicketRepository.SelectOne(r.Context(), tixer.PublicID(body.TicketID))
+		if err != nil {
+			switch err {
+			case psql.ErrDbTenantRequired:
+				s.tenantRequiredResponse(w, r)
+			case psql.ErrDbRecordNotFound:
+				s.failedValidationResponse(w, r, map[string]string{"ticketID": "must be an existing ticket"})
+			default:
+				s.internalServerErrorResponse(w, r, err)
+			}
+			return
+		}
+		promo.TicketID = &ticketDB.ID
+	}
+
+	if valid, errs := promo.Validate(); !valid {
+		s.failedValidationResponse(w, r, errs)
+		return
+	}
+
+	promoDB, err := s.PromoCodeRepository.Insert(crossTenantContext(r), promo)
+	if err != nil {
+		switch err {
+		case psql.ErrDbDuplicatePromoCode:
+			s.duplicatePromoCodeResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("/v1/promo-codes/%s", promoDB.PublicID))
+
+	err = s.writeJSON(w, http.StatusCreated, envelope{"promoCode": toPromoCodeResponseBody(promoDB)}, headers)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// promoCodeUrlQs represents the expected query string parameters for reading promo codes.
+type promoCodeUrlQs struct {
+	page     int
+	pageSize int
+}
+
+// handleReadPromoCodes handles reading promo codes from the system.
+func (s *Server) handleReadPromoCodes(w http.ResponseWriter, r *http.Request) {
+	validator := newValidator()
+	qs := validator.validatePromoCodeUrlValues(r.URL.Query())
+	if !validator.valid() {
+		s.failedValidationResponse(w, r, validator.errors)
+		return
+	}
+
+	paginator := psql.NewPaginator(qs.page, qs.pageSize)
+
+	promosDB, pagination, err := s.PromoCodeRepository.SelectMultiple(crossTenantContext(r), psql.PromoCodeFilter{
+		Limit:  paginator.Limit(),
+		Offset: paginator.Offset(),
+	})
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+		return
+	}
+
+	promos := make([]promoCodeResponseBody, len(promosDB))
+	for i, promoDB := range promosDB {
+		promos[i] = toPromoCodeResponseBody(promoDB)
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"promoCodes": promos, "pagination": pagination}, nil)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// handleReadPromoCode handles reading a single promo code from the system.
+func (s *Server) handleReadPromoCode(w http.ResponseWriter, r *http.Request) {
+	id, err := s.readIDParam(r)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	promoDB, err := s.PromoCodeRepository.SelectOne(crossTenantContext(r), id)
+	if err != nil {
+		switch err {
+		case psql.ErrDbRecordNotFound:
+			s.notFoundResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"promoCode": toPromoCodeResponseBody(promoDB)}, nil)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// handleDeletePromoCode handles the deletion of a promo code from the system.
+// A code that orders have redeemed cannot be deleted.
+func (s *Server) handleDeletePromoCode(w http.ResponseWriter, r *http.Request) {
+	id, err := s.readIDParam(r)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	err = s.PromoCodeRepository.Delete(crossTenantContext(r), id)
+	if err != nil {
+		switch err {
+		case psql.ErrDbRecordNotFound:
+			s.notFoundResponse(w, r)
+		case psql.ErrDbRecordInUse:
+			s.recordInUseResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"message": "promo code succesfully deleted"}, nil)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// toPromoCodeResponseBody converts a promo code that was read from DB
+// to a promo code that will be sent in the response body.
+func toPromoCodeResponseBody(promoDB tixer.PromoCode) promoCodeResponseBody {
+	body := promoCodeResponseBody{
+		PublicID:              string(promoDB.PublicID),
+		Code:                  promoDB.Code,
+		DiscountType:          string(promoDB.DiscountType),
+		Scope:                 string(promoDB.Scope),
+		EventID:               (*string)(promoDB.EventPublicID),
+		TicketID:              (*string)(promoDB.TicketPublicID),
+		ValidFrom:             promoDB.ValidFrom,
+		ValidUntil:            promoDB.ValidUntil,
+		MaxRedemptions:        promoDB.MaxRedemptions,
+		MaxRedemptionsPerUser: promoDB.MaxRedemptionsPerUser,
+		Redemptions:           promoDB.Redemptions,
+	}
+
+	switch promoDB.DiscountType {
+	case tixer.DiscountPercentage:
+		body.PercentOff = &promoDB.PercentOff
+	case tixer.DiscountFixed:
+		amountOff := toMoneyBody(promoDB.AmountOff)
+		body.AmountOff = &amountOff
+	}
+
+	return body
+}
@@ -4,12 +4,16 @@ package rcontext
 import (
 	"context"
 	"time"
+
+	tixer "github.com/mroobert/monorepo-tixer"
 )
 
 type contextKey string
 
 const requestInfoKey = contextKey("requestInfo")
 const ResponseInfoKey = contextKey("ResponseInfo")
+const identityKey = contextKey("identity")
+const permissionsKey = contextKey("permissions")
 
 // RequestInfo holds information about the incoming request.
 type RequestInfo struct {
@@ -22,6 +26,12 @@ type ResponseInfo struct {
 	StatusCode int
 }
 
+// Identity holds information about the authenticated caller.
+type Identity struct {
+	Subject string            // the public ID of the user
+	Scopes  tixer.Permissions // the scopes of the API key the caller used, nil for user tokens
+}
+
 // GetRequestInfo returns the RequestInfo from the context.
 func GetRequestInfo(ctx context.Context) *RequestInfo {
 	info, ok := ctx.Value(requestInfoKey).(*RequestInfo)
@@ -51,3 +61,34 @@ func GetResponseInfo(ctx context.Context) *ResponseInfo {
 func SetResponseInfo(ctx context.Context, info *ResponseInfo) context.Context {
 	return context.WithValue(ctx, ResponseInfoKey, info)
 }
+
+// GetIdentity returns the Identity from the context.
+// It returns nil if the request is anonymous.
+func GetIdentity(ctx context.Context) *Identity {
+	identity, ok := ctx.Value(identityKey).(*Identity)
+	if !ok {
+		return nil
+	}
+
+	return identity
+}
+
+// SetIdentity sets the Identity in the context.
+func SetIdentity(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, identityKey, identity)
+}
+
+// GetPermissions returns the permissions of the caller from the context.
+func GetPermissions(ctx context.Context) tixer.Permissions {
+	permissions, ok := ctx.Value(permissionsKey).(tixer.Permissions)
+	if !ok {
+		return nil
+	}
+
+	return permissions
+}
+
+// SetPermissions sets the permissions of the caller in the context.
+func SetPermissions(ctx context.Context, permissions tixer.Permissions) context.Context {
+	return context.WithValue(ctx, permissionsKey, permissions)
+}
@@ -0,0 +1,416 @@
+package httpio
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	nanoid "github.com/matoous/go-nanoid/v2"
+	tixer "github.com/mroobert/monorepo-tixer"
+	"github.com/mroobert/monorepo-tixer/psql"
+)
+
+// registerResaleRoutes registers the resale marketplace routes with the server.
+func (s *Server) registerResaleRoutes(r *http.ServeMux) {
+	r.HandleFunc("POST /v1/orders/{id}/resale", s.authorize(tixer.PermOrdersWrite, s.handleCreateResaleListing))
+	r.HandleFunc("GET /v1/resale", s.authorize(tixer.PermTicketsRead, s.handleReadResaleListings))
+	r.HandleFunc("GET /v1/resale/{id}", s.authorize(tixer.PermTicketsRead, s.handleReadResaleListing))
+	r.HandleFunc("DELETE /v1/resale/{id}", s.authorize(tixer.PermOrdersWrite, s.handleWithdrawResaleListing))
+	r.HandleFunc("POST /v1/resale/{id}/purchase", s.authorize(tixer.PermOrdersWrite, s.handlePurchaseResaleListing))
+	r.HandleFunc("GET /v1/payouts", s.authorize(tixer.PermOrdersWrite, s.handleReadPayouts))
+}
+
+// resaleListingResponseBody represents the expected fields in the response body for a resale listing.
+type resaleListingResponseBody struct {
+	PublicID      string     `json:"publicID"`
+	TicketID      string     `json:"ticketID"`
+	Price         moneyBody  `json:"price"`
+	OriginalPrice moneyBody  `json:"originalPrice"`
+	Status        string     `json:"status"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	SoldAt        *time.Time `json:"soldAt,omitempty"`
+}
+
+// payoutResponseBody represents the expected fields in the response body for a payout.
+type payoutResponseBody struct {
+	PublicID  string    `json:"publicID"`
+	ListingID string    `json:"listingID"`
+	Amount    moneyBody `json:"amount"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// createResaleListingRequestBody represents the expected request body for listing a paid order for resale.
+type createResaleListingRequestBody struct {
+	Price moneyBody `json:"price"`
+}
+
+// handleCreateResaleListing handles putting the ticket of a paid order on the resale market.
+// The order stays with the current user until the listing is purchased.
+func (s *Server) handleCreateResaleListing(w http.ResponseWriter, r *http.Request) {
+	var body createResaleListingRequestBody
+
+	id, err := s.readIDParam(r)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	err = s.readJSON(w, r, &body)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	user, ok := s.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	orderDB, err := s.OrderRepository.SelectOne(crossTenantContext(r), id)
+	if err != nil {
+		switch err {
+		case psql.ErrDbRecordNotFound:
+			s.notFoundResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if !orderDB.PlacedBy(user.ID) {
+		s.notOwnerResponse(w, r)
+		return
+	}
+
+	if !orderDB.Transferable() {
+		s.orderNotListableResponse(w, r)
+		return
+	}
+
+	publicID, err := nanoid.Generate(tixer.PublicIDAlphabet, tixer.PublicIDLength)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+		return
+	}
+
+	listing := tixer.ResaleListing{
+		PublicID:      tixer.PublicID(publicID),
+		OrderID:       orderDB.ID,
+		SellerID:      user.ID,
+		Price:         body.Price.toMoney(),
+		OriginalPrice: orderDB.Subtotal,
+	}
+
+	if valid, errs := listing.Validate(); !valid {
+		s.failedValidationResponse(w, r, errs)
+		return
+	}
+
+	listingDB, err := s.ResaleRepository.Insert(crossTenantContext(r), listing)
+	if err != nil {
+		switch err {
+		case psql.ErrDbOrderListed:
+			s.orderListedResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("/v1/resale/%s", listingDB.PublicID))
+
+	err = s.writeJSON(w, http.StatusCreated, envelope{"listing": toResaleListingResponseBody(listingDB)}, headers)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// resaleUrlQs represents the expected query string parameters for reading resale listings.
+type resaleUrlQs struct {
+	ticket   string
+	page     int
+	pageSize int
+}
+
+// handleReadResaleListings handles reading the listings that can be purchased, the cheapest first.
+func (s *Server) handleReadResaleListings(w http.ResponseWriter, r *http.Request) {
+	validator := newValidator()
+	qs := validator.validateResaleUrlValues(r.URL.Query())
+	if !validator.valid() {
+		s.failedValidationResponse(w, r, validator.errors)
+		return
+	}
+
+	paginator := psql.NewPaginator(qs.page, qs.pageSize)
+
+	listingsDB, pagination, err := s.ResaleRepository.SelectMultiple(crossTenantContext(r), psql.ResaleFilter{
+		TicketID: qs.ticket,
+		Limit:    paginator.Limit(),
+		Offset:   paginator.Offset(),
+	})
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+		return
+	}
+
+	listings := make([]resaleListingResponseBody, len(listingsDB))
+	for i, listingDB := range listingsDB {
+		listings[i] = toResaleListingResponseBody(listingDB)
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"listings": listings, "pagination": pagination}, nil)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// handleReadResaleListing handles reading a single resale listing.
+func (s *Server) handleReadResaleListing(w http.ResponseWriter, r *http.Request) {
+	listingDB, ok := s.readResaleListing(w, r)
+	if !ok {
+		return
+	}
+
+	err := s.writeJSON(w, http.StatusOK, envelope{"listing": toResaleListingResponseBody(listingDB)}, nil)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// handleWithdrawResaleListing handles the seller taking an active listing off the market.
+func (s *Server) handleWithdrawResaleListing(w http.ResponseWriter, r *http.Request) {
+	listingDB, ok := s.readResaleListing(w, r)
+	if !ok {
+		return
+	}
+
+	user, ok := s.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if listingDB.SellerID != user.ID {
+		s.notOwnerResponse(w, r)
+		return
+	}
+
+	err := s.ResaleRepository.Withdraw(r.Context(), &listingDB)
+	if err != nil {
+		switch err {
+		case psql.ErrDbEditConflict:
+			s.listingUnavailableResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"listing": toResaleListingResponseBody(listingDB)}, nil)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// purchaseResaleListingRequestBody represents the expected request body for buying a resale listing.
+// The amount is deliberately missing: it is always taken from the listing price.
+type purchaseResaleListingRequestBody struct {
+	PaymentToken string `json:"paymentToken"`
+}
+
+// handlePurchaseResaleListing handles charging the current user the price of a listing.
+// Once the charge succeeds the order moves to the buyer and a payout is recorded for the seller.
+// If the listing was sold, withdrawn or its order left the seller in the meantime, the charge is given back.
+func (s *Server) handlePurchaseResaleListing(w http.ResponseWriter, r *http.Request) {
+	var body purchaseResaleListingRequestBody
+
+	err := s.readJSON(w, r, &body)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	validator := newValidator()
+	if validator.validatePurchaseResaleListingRequestBody(body); !validator.valid() {
+		s.failedValidationResponse(w, r, validator.errors)
+		return
+	}
+
+	listingDB, ok := s.readResaleListing(w, r)
+	if !ok {
+		return
+	}
+
+	user, ok := s.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if listingDB.SellerID == user.ID {
+		s.ownListingResponse(w, r)
+		return
+	}
+
+	orderDB, err := s.OrderRepository.SelectOne(crossTenantContext(r), listingDB.OrderPublicID)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+		return
+	}
+
+	if listingDB.Status != tixer.ResaleActive || !orderDB.Transferable() || !orderDB.PlacedBy(listingDB.SellerID) {
+		s.listingUnavailableResponse(w, r)
+		return
+	}
+
+	publicID, err := nanoid.Generate(tixer.PublicIDAlphabet, tixer.PublicIDLength)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+		return
+	}
+
+	payout := tixer.Payout{PublicID: tixer.PublicID(publicID)}
+
+	reference, err := s.PaymentGateway.Charge(r.Context(), tixer.Charge{
+		Reference: "resale_" + string(payout.PublicID),
+		Amount:    listingDB.Price,
+		Source:    body.PaymentToken,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, tixer.ErrPaymentDeclined):
+			s.paymentDeclinedResponse(w, r)
+		case errors.Is(err, tixer.ErrPaymentTimeout):
+			s.paymentTimeoutResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	listingDB.BuyerID = &user.ID
+	listingDB.GatewayReference = &reference
+
+	_, err = s.ResaleRepository.Purchase(r.Context(), &listingDB, &orderDB, payout)
+	if err != nil {
+		s.reverseResaleCharge(r, listingDB, payout)
+		switch err {
+		case psql.ErrDbListingUnavailable:
+			s.listingUnavailableResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"listing": toResaleListingResponseBody(listingDB), "order": toOrderResponseBody(orderDB)}, nil)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// reverseResaleCharge refunds in full the charge of a resale purchase that could not be completed.
+// Failures are only logged, the gateway reference is logged with them for manual reconciliation.
+func (s *Server) reverseResaleCharge(r *http.Request, listingDB tixer.ResaleListing, payout tixer.Payout) {
+	_, err := s.PaymentGateway.Refund(r.Context(), tixer.RefundRequest{
+		Reference:       "reversal_resale_" + string(payout.PublicID),
+		ChargeReference: *listingDB.GatewayReference,
+		Amount:          listingDB.Price,
+	})
+	if err != nil {
+		s.logError(r, fmt.Errorf("failed to reverse resale charge %s of listing %s: %w", *listingDB.GatewayReference, listingDB.PublicID, err))
+	}
+}
+
+// payoutUrlQs represents the expected query string parameters for reading payouts.
+type payoutUrlQs struct {
+	page     int
+	pageSize int
+}
+
+// handleReadPayouts handles reading the payouts owed to the current user for the tickets they resold.
+func (s *Server) handleReadPayouts(w http.ResponseWriter, r *http.Request) {
+	validator := newValidator()
+	qs := validator.validatePayoutUrlValues(r.URL.Query())
+	if !validator.valid() {
+		s.failedValidationResponse(w, r, validator.errors)
+		return
+	}
+
+	user, ok := s.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	paginator := psql.NewPaginator(qs.page, qs.pageSize)
+
+	payoutsDB, pagination, err := s.ResaleRepository.SelectPayouts(r.Context(), psql.PayoutFilter{
+		UserID: user.ID,
+		Limit:  paginator.Limit(),
+		Offset: paginator.Offset(),
+	})
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+		return
+	}
+
+	payouts := make([]payoutResponseBody, len(payoutsDB))
+	for i, payoutDB := range payoutsDB {
+		payouts[i] = toPayoutResponseBody(payoutDB)
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"payouts": payouts, "pagination": pagination}, nil)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// readResaleListing reads the listing referenced by the id path parameter.
+// It writes the error response itself and returns false if the listing can't be read.
+func (s *Server) readResaleListing(w http.ResponseWriter, r *http.Request) (tixer.ResaleListing, bool) {
+	id, err := s.readIDParam(r)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return tixer.ResaleListing{}, false
+	}
+
+	listingDB, err := s.ResaleRepository.SelectOne(crossTenantContext(r), id)
+	if err != nil {
+		switch err {
+		case psql.ErrDbRecordNotFound:
+			s.notFoundResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return tixer.ResaleListing{}, false
+	}
+
+	return listingDB, true
+}
+
+// toResaleListingResponseBody converts a resale listing that was read from DB
+// to a listing that will be sent in the response body.
+func toResaleListingResponseBody(listingDB tixer.ResaleListing) resaleListingResponseBody {
+	return resaleListingResponseBody{
+		PublicID:      string(listingDB.PublicID),
+		TicketID:      string(listingDB.TicketPublicID),
+		Price:         toMoneyBody(listingDB.Price),
+		OriginalPrice: toMoneyBody(listingDB.OriginalPrice),
+		Status:        string(listingDB.Status),
+		CreatedAt:     listingDB.CreatedAt,
+		SoldAt:        listingDB.SoldAt,
+	}
+}
+
+// toPayoutResponseBody converts a payout that was read from DB
+// to a payout that will be sent in the response body.
+func toPayoutResponseBody(payoutDB tixer.Payout) payoutResponseBody {
+	return payoutResponseBody{
+		PublicID:  string(payoutDB.PublicID),
+		ListingID: string(payoutDB.ListingPublicID),
+		Amount:    toMoneyBody(payoutDB.Amount),
+		Status:    string(payoutDB.Status),
+		CreatedAt: payoutDB.CreatedAt,
+	}
+}
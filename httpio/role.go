@@ -0,0 +1,232 @@
+package httpio
+
+import (
+	"fmt"
+	"net/http"
+
+	tixer "github.com/mroobert/monorepo-tixer"
+	"github.com/mroobert/monorepo-tixer/psql"
+)
+
+// registerRoleRoutes registers the role administration routes with the server.
+func (s *Server) registerRoleRoutes(r *http.ServeMux) {
+	r.HandleFunc("GET /v1/permissions", s.authorize(tixer.PermRolesManage, s.handleReadPermissions))
+	r.HandleFunc("GET /v1/roles", s.authorize(tixer.PermRolesManage, s.handleReadRoles))
+	r.HandleFunc("POST /v1/roles", s.authorize(tixer.PermRolesManage, s.handleCreateRole))
+	r.HandleFunc("PUT /v1/roles/{role}/permissions", s.authorize(tixer.PermRolesManage, s.handleUpdateRolePermissions))
+	r.HandleFunc("DELETE /v1/roles/{role}", s.authorize(tixer.PermRolesManage, s.handleDeleteRole))
+	r.HandleFunc("PUT /v1/users/{id}/roles/{role}", s.authorize(tixer.PermRolesManage, s.handleGrantRole))
+	r.HandleFunc("DELETE /v1/users/{id}/roles/{role}", s.authorize(tixer.PermRolesManage, s.handleRevokeRole))
+}
+
+// roleResponseBody represents the expected fields in the response body for a role resource.
+type roleResponseBody struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Permissions []tixer.Permission `json:"permissions"`
+}
+
+// createRoleRequestBody represents the expected request body for creating a new role.
+type createRoleRequestBody struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Permissions []tixer.Permission `json:"permissions"`
+}
+
+// updateRolePermissionsRequestBody represents the expected request body for replacing the permissions of a role.
+type updateRolePermissionsRequestBody struct {
+	Permissions []tixer.Permission `json:"permissions"`
+}
+
+// handleReadPermissions handles reading the system permissions.
+func (s *Server) handleReadPermissions(w http.ResponseWriter, r *http.Request) {
+	err := s.writeJSON(w, http.StatusOK, envelope{"permissions": tixer.AllPermissions}, nil)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// handleReadRoles handles reading the roles with their permissions.
+func (s *Server) handleReadRoles(w http.ResponseWriter, r *http.Request) {
+	rolesDB, err := s.RoleRepository.SelectAll(r.Context())
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+		return
+	}
+
+	roles := make([]roleResponseBody, len(rolesDB))
+	for i, roleDB := range rolesDB {
+		roles[i] = toRoleResponseBody(roleDB)
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"roles": roles}, nil)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// handleCreateRole handles the creation of a new role.
+func (s *Server) handleCreateRole(w http.ResponseWriter, r *http.Request) {
+	var body createRoleRequestBody
+
+	err := s.readJSON(w, r, &body)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	role := tixer.Role{
+		Name:        body.Name,
+		Description: body.Description,
+		Permissions: body.Permissions,
+	}
+	if valid, errs := role.Validate(); !valid {
+		s.failedValidationResponse(w, r, errs)
+		return
+	}
+
+	roleDB, err := s.RoleRepository.Insert(r.Context(), role)
+	if err != nil {
+		switch err {
+		case psql.ErrDbDuplicateRole:
+			s.duplicateRoleResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("/v1/roles/%s", roleDB.Name))
+
+	err = s.writeJSON(w, http.StatusCreated, envelope{"role": toRoleResponseBody(roleDB)}, headers)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// handleUpdateRolePermissions handles replacing the permissions of a role.
+func (s *Server) handleUpdateRolePermissions(w http.ResponseWriter, r *http.Request) {
+	var body updateRolePermissionsRequestBody
+
+	err := s.readJSON(w, r, &body)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	role := tixer.Role{
+		Name:        r.PathValue("role"),
+		Permissions: body.Permissions,
+	}
+	if valid, errs := role.Validate(); !valid {
+		s.failedValidationResponse(w, r, errs)
+		return
+	}
+
+	err = s.RoleRepository.UpdatePermissions(r.Context(), &role)
+	if err != nil {
+		switch err {
+		case psql.ErrDbRecordNotFound:
+			s.notFoundResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"role": toRoleResponseBody(role)}, nil)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// handleDeleteRole handles the deletion of a role.
+func (s *Server) handleDeleteRole(w http.ResponseWriter, r *http.Request) {
+	role := tixer.Role{Name: r.PathValue("role")}
+	if role.BuiltIn() {
+		s.builtInRoleResponse(w, r)
+		return
+	}
+
+	err := s.RoleRepository.Delete(r.Context(), role.Name)
+	if err != nil {
+		switch err {
+		case psql.ErrDbRecordNotFound:
+			s.notFoundResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"message": "role succesfully deleted"}, nil)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// handleGrantRole handles granting a role to a user.
+func (s *Server) handleGrantRole(w http.ResponseWriter, r *http.Request) {
+	id, err := s.readIDParam(r)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	err = s.RoleRepository.Grant(r.Context(), id, r.PathValue("role"))
+	if err != nil {
+		switch err {
+		case psql.ErrDbRecordNotFound:
+			s.notFoundResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"message": "role succesfully granted"}, nil)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// handleRevokeRole handles revoking a role from a user.
+func (s *Server) handleRevokeRole(w http.ResponseWriter, r *http.Request) {
+	id, err := s.readIDParam(r)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	err = s.RoleRepository.Revoke(r.Context(), id, r.PathValue("role"))
+	if err != nil {
+		switch err {
+		case psql.ErrDbRecordNotFound:
+			s.notFoundResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"message": "role succesfully revoked"}, nil)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// toRoleResponseBody converts a role that was read from DB
+// to a role that will be sent in the response body.
+func toRoleResponseBody(roleDB tixer.Role) roleResponseBody {
+	permissions := roleDB.Permissions
+	if permissions == nil {
+		permissions = tixer.Permissions{}
+	}
+
+	return roleResponseBody{
+		Name:        roleDB.Name,
+		Description: roleDB.Description,
+		Permissions: permissions,
+	}
+}
@@ -0,0 +1,238 @@
+package httpio
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	tixer "github.com/mroobert/monorepo-tixer"
+	"github.com/mroobert/monorepo-tixer/psql"
+)
+
+// registerTicketRulesRoutes registers the ticket rules routes with the server.
+func (s *Server) registerTicketRulesRoutes(r *http.ServeMux) {
+	r.HandleFunc("GET /v1/ticket-rules", s.authorize(tixer.PermTicketsWrite, s.forTenant(s.handleReadOwnTicketRules)))
+	r.HandleFunc("GET /v1/tenants/{id}/ticket-rules", s.authorize(tixer.PermTicketRulesManage, s.handleReadTicketRules))
+	r.HandleFunc("PUT /v1/tenants/{id}/ticket-rules", s.authorize(tixer.PermTicketRulesManage, s.handleUpdateTicketRules))
+	r.HandleFunc("DELETE /v1/tenants/{id}/ticket-rules", s.authorize(tixer.PermTicketRulesManage, s.handleDeleteTicketRules))
+}
+
+// ticketRulesResponseBody represents the expected fields in the response body for the ticket rules an organizer follows.
+type ticketRulesResponseBody struct {
+	MaxTitleLength    int              `json:"maxTitleLength"`
+	MinPrice          int64            `json:"minPrice"`
+	MaxPrices         map[string]int64 `json:"maxPrices"`
+	AllowedCurrencies []string         `json:"allowedCurrencies"`
+	ForbiddenWords    []string         `json:"forbiddenWords"`
+}
+
+// ticketRulesOverrideBody represents the ticket rules of an organizer that replace the deployment rules.
+// The omitted fields keep the deployment rule; the max prices are merged into the deployment prices.
+type ticketRulesOverrideBody struct {
+	MaxTitleLength    *int             `json:"maxTitleLength,omitempty"`
+	MinPrice          *int64           `json:"minPrice,omitempty"`
+	MaxPrices         map[string]int64 `json:"maxPrices,omitempty"`
+	AllowedCurrencies *[]string        `json:"allowedCurrencies,omitempty"`
+	ForbiddenWords    *[]string        `json:"forbiddenWords,omitempty"`
+}
+
+// handleReadOwnTicketRules handles reading the ticket rules the tickets of the tenant the request is made for must follow.
+func (s *Server) handleReadOwnTicketRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := s.ticketRules(r.Context())
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"rules": toTicketRulesResponseBody(rules)}, nil)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// handleReadTicketRules handles reading the ticket rules override of an organizer and the rules it results in.
+func (s *Server) handleReadTicketRules(w http.ResponseWriter, r *http.Request) {
+	organizer, ok := s.readTenant(w, r)
+	if !ok {
+		return
+	}
+
+	overrideDB, err := s.TicketRulesRepository.SelectOne(r.Context(), organizer.ID)
+	if err != nil && err != psql.ErrDbRecordNotFound {
+		s.internalServerErrorResponse(w, r, err)
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{
+		"override": toTicketRulesOverrideBody(overrideDB),
+		"rules":    toTicketRulesResponseBody(s.TicketRules.Apply(overrideDB)),
+	}, nil)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// handleUpdateTicketRules handles replacing the ticket rules override of an organizer.
+// The override is refused if the rules it results in are not valid.
+func (s *Server) handleUpdateTicketRules(w http.ResponseWriter, r *http.Request) {
+	var body ticketRulesOverrideBody
+
+	organizer, ok := s.readTenant(w, r)
+	if !ok {
+		return
+	}
+
+	err := s.readJSON(w, r, &body)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	override := body.toTicketRulesOverride()
+	override.TenantID = organizer.ID
+
+	rules := s.TicketRules.Apply(override)
+	if valid, errs := rules.Validate(); !valid {
+		s.failedValidationResponse(w, r, errs)
+		return
+	}
+
+	overrideDB, err := s.TicketRulesRepository.Upsert(r.Context(), override)
+	if err != nil {
+		switch err {
+		case psql.ErrDbRecordNotFound:
+			s.notFoundResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{
+		"override": toTicketRulesOverrideBody(overrideDB),
+		"rules":    toTicketRulesResponseBody(rules),
+	}, nil)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// handleDeleteTicketRules handles removing the ticket rules override of an organizer,
+// who follows the deployment rules again.
+func (s *Server) handleDeleteTicketRules(w http.ResponseWriter, r *http.Request) {
+	organizer, ok := s.readTenant(w, r)
+	if !ok {
+		return
+	}
+
+	err := s.TicketRulesRepository.Delete(r.Context(), organizer.ID)
+	if err != nil {
+		switch err {
+		case psql.ErrDbRecordNotFound:
+			s.notFoundResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"message": "ticket rules succesfully reset"}, nil)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// ticketRules returns the ticket rules of the tenant in ctx: the deployment rules with the override
+// of the tenant applied. Requests made for no tenant follow the deployment rules.
+func (s *Server) ticketRules(ctx context.Context) (tixer.TicketRules, error) {
+	tenantID, ok := tixer.TenantFromContext(ctx)
+	if !ok {
+		return s.TicketRules, nil
+	}
+
+	overrideDB, err := s.TicketRulesRepository.SelectOne(ctx, tenantID)
+	if err != nil {
+		if err == psql.ErrDbRecordNotFound {
+			return s.TicketRules, nil
+		}
+		return tixer.TicketRules{}, err
+	}
+
+	return s.TicketRules.Apply(overrideDB), nil
+}
+
+// toTicketRulesOverride converts the override of a request body to a domain value.
+// The currency codes are accepted in any letter case.
+func (b ticketRulesOverrideBody) toTicketRulesOverride() tixer.TicketRulesOverride {
+	override := tixer.TicketRulesOverride{
+		MaxTitleLength: b.MaxTitleLength,
+		MinPrice:       b.MinPrice,
+		ForbiddenWords: b.ForbiddenWords,
+	}
+
+	if len(b.MaxPrices) > 0 {
+		override.MaxPrices = make(map[tixer.Currency]int64, len(b.MaxPrices))
+		for currency, amount := range b.MaxPrices {
+			override.MaxPrices[tixer.Currency(strings.ToUpper(currency))] = amount
+		}
+	}
+
+	if b.AllowedCurrencies != nil {
+		allowed := make([]tixer.Currency, len(*b.AllowedCurrencies))
+		for i, currency := range *b.AllowedCurrencies {
+			allowed[i] = tixer.Currency(strings.ToUpper(currency))
+		}
+		override.AllowedCurrencies = &allowed
+	}
+
+	return override
+}
+
+// toTicketRulesOverrideBody converts a ticket rules override that was read from DB
+// to an override that will be sent in the response body.
+func toTicketRulesOverrideBody(overrideDB tixer.TicketRulesOverride) ticketRulesOverrideBody {
+	body := ticketRulesOverrideBody{
+		MaxTitleLength: overrideDB.MaxTitleLength,
+		MinPrice:       overrideDB.MinPrice,
+		ForbiddenWords: overrideDB.ForbiddenWords,
+	}
+
+	if len(overrideDB.MaxPrices) > 0 {
+		body.MaxPrices = make(map[string]int64, len(overrideDB.MaxPrices))
+		for currency, amount := range overrideDB.MaxPrices {
+			body.MaxPrices[string(currency)] = amount
+		}
+	}
+
+	if overrideDB.AllowedCurrencies != nil {
+		allowed := make([]string, len(*overrideDB.AllowedCurrencies))
+		for i, currency := range *overrideDB.AllowedCurrencies {
+			allowed[i] = string(currency)
+		}
+		body.AllowedCurrencies = &allowed
+	}
+
+	return body
+}
+
+// toTicketRulesResponseBody converts the ticket rules an organizer follows
+// to the rules that will be sent in the response body, with the highest price of every allowed currency.
+func toTicketRulesResponseBody(rules tixer.TicketRules) ticketRulesResponseBody {
+	body := ticketRulesResponseBody{
+		MaxTitleLength:    rules.MaxTitleLength,
+		MinPrice:          rules.MinPrice,
+		MaxPrices:         make(map[string]int64),
+		AllowedCurrencies: []string{},
+		ForbiddenWords:    rules.ForbiddenWords,
+	}
+	if body.ForbiddenWords == nil {
+		body.ForbiddenWords = []string{}
+	}
+
+	for _, currency := range rules.Currencies() {
+		body.AllowedCurrencies = append(body.AllowedCurrencies, string(currency))
+		body.MaxPrices[string(currency)] = rules.MaxPrice(currency).Amount
+	}
+
+	return body
+}
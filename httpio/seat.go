@@ -0,0 +1,243 @@
+package httpio
+
+import (
+	"fmt"
+	"net/http"
+
+	nanoid "github.com/matoous/go-nanoid/v2"
+	tixer "github.com/mroobert/monorepo-tixer"
+	"github.com/mroobert/monorepo-tixer/psql"
+)
+
+// registerSeatRoutes registers the seat map routes with the server.
+func (s *Server) registerSeatRoutes(r *http.ServeMux) {
+	r.HandleFunc("PUT /v1/venues/{id}/seats", s.authorize(tixer.PermEventsWrite, s.handleReplaceVenueSeats))
+	r.HandleFunc("GET /v1/venues/{id}/seats", s.authorize(tixer.PermTicketsRead, s.handleReadVenueSeats))
+	r.HandleFunc("GET /v1/events/{id}/seats", s.authorize(tixer.PermTicketsRead, s.handleReadEventSeats))
+}
+
+// seatMapResponseBody represents the expected fields in the response body for a seat map.
+type seatMapResponseBody struct {
+	Sections []sectionResponseBody `json:"sections"`
+}
+
+type sectionResponseBody struct {
+	Name string                `json:"name"`
+	Rows []seatRowResponseBody `json:"rows"`
+}
+
+type seatRowResponseBody struct {
+	Label string             `json:"label"`
+	Seats []seatResponseBody `json:"seats"`
+}
+
+// seatResponseBody represents a seat. The availability fields are only sent
+// when the seat map is read for an event.
+type seatResponseBody struct {
+	PublicID string     `json:"publicID"`
+	Number   int32      `json:"number"`
+	Category string     `json:"category"`
+	Status   string     `json:"status,omitempty"`
+	TicketID *string    `json:"ticketID,omitempty"`
+	Price    *moneyBody `json:"price,omitempty"`
+}
+
+// replaceSeatMapRequestBody represents the expected request body for replacing the seat map of a venue.
+type replaceSeatMapRequestBody struct {
+	Sections []struct {
+		Name string `json:"name"`
+		Rows []struct {
+			Label string `json:"label"`
+			Seats []struct {
+				Number   int32  `json:"number"`
+				Category string `json:"category"`
+			} `json:"seats"`
+		} `json:"rows"`
+	} `json:"sections"`
+}
+
+// handleReplaceVenueSeats handles replacing the seat map of a venue.
+// The seat map can only be replaced while no tickets were issued for its seats.
+func (s *Server) handleReplaceVenueSeats(w http.ResponseWriter, r *http.Request) {
+	var body replaceSeatMapRequestBody
+
+	id, err := s.readIDParam(r)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	user, ok := s.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	venueDB, err := s.VenueRepository.SelectOne(r.Context(), id)
+	if err != nil {
+		switch err {
+		case psql.ErrDbRecordNotFound:
+			s.notFoundResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if !venueDB.OwnedBy(user.ID) && !s.can(r, tixer.PermEventsManage) {
+		s.notOwnerResponse(w, r)
+		return
+	}
+
+	err = s.readJSON(w, r, &body)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	var seatMap tixer.SeatMap
+	for _, sectionBody := range body.Sections {
+		section := tixer.Section{Name: sectionBody.Name}
+		for _, rowBody := range sectionBody.Rows {
+			row := tixer.SeatRow{Label: rowBody.Label}
+			for _, seatBody := range rowBody.Seats {
+				publicID, err := nanoid.Generate(tixer.PublicIDAlphabet, tixer.PublicIDLength)
+				if err != nil {
+					s.internalServerErrorResponse(w, r, err)
+					return
+				}
+
+				row.Seats = append(row.Seats, tixer.Seat{
+					PublicID: tixer.PublicID(publicID),
+					Number:   seatBody.Number,
+					Category: seatBody.Category,
+				})
+			}
+			section.Rows = append(section.Rows, row)
+		}
+		seatMap.Sections = append(seatMap.Sections, section)
+	}
+
+	if valid, errs := seatMap.Validate(); !valid {
+		s.failedValidationResponse(w, r, errs)
+		return
+	}
+
+	if seatMap.Len() > int(venueDB.Capacity) {
+		s.failedValidationResponse(w, r, map[string]string{"sections": fmt.Sprintf("must not hold more seats than the venue capacity of %d", venueDB.Capacity)})
+		return
+	}
+
+	err = s.SeatRepository.ReplaceSeatMap(r.Context(), venueDB.ID, &seatMap)
+	if err != nil {
+		switch err {
+		case psql.ErrDbRecordNotFound:
+			s.notFoundResponse(w, r)
+		case psql.ErrDbRecordInUse:
+			s.recordInUseResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"seatMap": toSeatMapResponseBody(seatMap)}, nil)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// handleReadVenueSeats handles reading the seat map of a venue.
+func (s *Server) handleReadVenueSeats(w http.ResponseWriter, r *http.Request) {
+	id, err := s.readIDParam(r)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	venueDB, err := s.VenueRepository.SelectOne(r.Context(), id)
+	if err != nil {
+		switch err {
+		case psql.ErrDbRecordNotFound:
+			s.notFoundResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	seatMap, err := s.SeatRepository.SelectSeatMap(r.Context(), venueDB.ID)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"seatMap": toSeatMapResponseBody(seatMap)}, nil)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// handleReadEventSeats handles reading the seat map of the venue of an event,
+// with the availability of every seat for the event.
+func (s *Server) handleReadEventSeats(w http.ResponseWriter, r *http.Request) {
+	id, err := s.readIDParam(r)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	eventDB, err := s.EventRepository.SelectOne(r.Context(), id)
+	if err != nil {
+		switch err {
+		case psql.ErrDbRecordNotFound:
+			s.notFoundResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	var seatMap tixer.SeatMap
+	if eventDB.VenueID != nil {
+		seatMap, err = s.SeatRepository.SelectEventSeatMap(crossTenantContext(r), eventDB.ID, *eventDB.VenueID)
+		if err != nil {
+			s.internalServerErrorResponse(w, r, err)
+			return
+		}
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"seatMap": toSeatMapResponseBody(seatMap)}, nil)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// toSeatMapResponseBody converts a seat map that was read from DB
+// to a seat map that will be sent in the response body.
+func toSeatMapResponseBody(seatMap tixer.SeatMap) seatMapResponseBody {
+	sections := make([]sectionResponseBody, len(seatMap.Sections))
+	for i, section := range seatMap.Sections {
+		rows := make([]seatRowResponseBody, len(section.Rows))
+		for j, row := range section.Rows {
+			seats := make([]seatResponseBody, len(row.Seats))
+			for k, seat := range row.Seats {
+				seats[k] = seatResponseBody{
+					PublicID: string(seat.PublicID),
+					Number:   seat.Number,
+					Category: seat.Category,
+					Status:   string(seat.Status),
+				}
+				if seat.Ticket != nil {
+					ticketID := string(seat.Ticket.PublicID)
+					seats[k].TicketID = &ticketID
+					price := toMoneyBody(seat.Ticket.Price)
+					seats[k].Price = &price
+				}
+			}
+			rows[j] = seatRowResponseBody{Label: row.Label, Seats: seats}
+		}
+		sections[i] = sectionResponseBody{Name: section.Name, Rows: rows}
+	}
+
+	return seatMapResponseBody{Sections: sections}
+}
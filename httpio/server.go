@@ -5,6 +5,9 @@ import (
 	"net/http"
 	"time"
 
+	tixer "github.com/mroobert/monorepo-tixer"
+	"github.com/mroobert/monorepo-tixer/auth"
+	"github.com/mroobert/monorepo-tixer/credential"
 	"github.com/mroobert/monorepo-tixer/httpio/mid"
 	"github.com/mroobert/monorepo-tixer/psql"
 )
@@ -28,12 +31,35 @@ type Server struct {
 	router         *http.ServeMux
 	env            string // the environment the server is running in
 	maxReqBodySize int32
+	authenticator  *auth.Authenticator
 
-	TicketRepository *psql.TicketRepository
+	TicketRepository  tixer.TicketStore
+	EventRepository   *psql.EventRepository
+	VenueRepository   *psql.VenueRepository
+	SeatRepository    *psql.SeatRepository
+	OrderRepository   *psql.OrderRepository
+	PaymentRepository *psql.PaymentRepository
+	RefundRepository  *psql.RefundRepository
+	UserRepository    *psql.UserRepository
+	RoleRepository    *psql.RoleRepository
+	APIKeyRepository  *psql.APIKeyRepository
+	PaymentGateway    tixer.PaymentGateway
+
+	WaitlistRepository     *psql.WaitlistRepository
+	PromoCodeRepository    *psql.PromoCodeRepository
+	TransferRepository     *psql.TransferRepository
+	ResaleRepository       *psql.ResaleRepository
+	CheckInRepository      *psql.CheckInRepository
+	CredentialSigner       *credential.Signer
+	ExchangeRateRepository *psql.ExchangeRateRepository
+	TicketRulesRepository  *psql.TicketRulesRepository
+	TenantRepository       *psql.TenantRepository
+	TicketRules            tixer.TicketRules // the ticket rules of the deployment, before the override of the organizer
 }
 
 // NewServer creates a new server with the provided configuration.
-func NewServer(cfg ServerConfig, env string) *Server {
+// The authenticator is used to verify the bearer tokens of the requests and to issue new ones.
+func NewServer(cfg ServerConfig, env string, authenticator *auth.Authenticator) *Server {
 	s := &Server{
 		server: &http.Server{
 			Addr:         cfg.Addr,
@@ -44,12 +70,29 @@ func NewServer(cfg ServerConfig, env string) *Server {
 		router:         http.NewServeMux(),
 		env:            env,
 		maxReqBodySize: cfg.MaxReqBodySize,
+		authenticator:  authenticator,
 	}
 
 	s.router.HandleFunc("/v1/healthcheck", s.handleHealthCheck)
 	s.registerTicketRoutes(s.router)
+	s.registerEventRoutes(s.router)
+	s.registerVenueRoutes(s.router)
+	s.registerSeatRoutes(s.router)
+	s.registerWaitlistRoutes(s.router)
+	s.registerOrderRoutes(s.router)
+	s.registerPromoCodeRoutes(s.router)
+	s.registerTransferRoutes(s.router)
+	s.registerResaleRoutes(s.router)
+	s.registerCheckInRoutes(s.router)
+	s.registerPaymentRoutes(s.router)
+	s.registerUserRoutes(s.router)
+	s.registerRoleRoutes(s.router)
+	s.registerAPIKeyRoutes(s.router)
+	s.registerExchangeRateRoutes(s.router)
+	s.registerTicketRulesRoutes(s.router)
+	s.registerTenantRoutes(s.router)
 
-	s.server.Handler = mid.Cors(mid.Panics(mid.ContextInfo(mid.Logger(s.router))))
+	s.server.Handler = mid.Cors(mid.Panics(mid.ContextInfo(mid.Logger(mid.APIKeys(s.lookupAPIKey)(mid.Authenticate(authenticator)(s.router))))))
 	return s
 }
 
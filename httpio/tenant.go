@@ -0,0 +1,282 @@
+package httpio
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	nanoid "github.com/matoous/go-nanoid/v2"
+	tixer "github.com/mroobert/monorepo-tixer"
+	"github.com/mroobert/monorepo-tixer/httpio/mid"
+	"github.com/mroobert/monorepo-tixer/httpio/rcontext"
+	"github.com/mroobert/monorepo-tixer/psql"
+)
+
+// registerTenantRoutes registers the tenant resource routes with the server.
+func (s *Server) registerTenantRoutes(r *http.ServeMux) {
+	r.HandleFunc("POST /v1/tenants", s.authorize(tixer.PermTenantsManage, s.handleCreateTenant))
+	r.HandleFunc("GET /v1/tenants", s.authorize(tixer.PermTicketsRead, s.handleReadTenants))
+	r.HandleFunc("GET /v1/tenants/{id}", s.authorize(tixer.PermTicketsRead, s.handleReadTenant))
+	r.HandleFunc("PUT /v1/tenants/{id}/members/{user}", s.authorize(tixer.PermTenantsManage, s.handleAddTenantMember))
+	r.HandleFunc("DELETE /v1/tenants/{id}/members/{user}", s.authorize(tixer.PermTenantsManage, s.handleRemoveTenantMember))
+}
+
+// tenantResponseBody represents the expected fields in the response body for a tenant resource.
+type tenantResponseBody struct {
+	PublicID  string    `json:"publicID"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// createTenantRequestBody represents the expected request body for creating a new tenant.
+type createTenantRequestBody struct {
+	Name string `json:"name"`
+}
+
+// handleCreateTenant handles adding a new organizer to the deployment.
+func (s *Server) handleCreateTenant(w http.ResponseWriter, r *http.Request) {
+	var body createTenantRequestBody
+
+	err := s.readJSON(w, r, &body)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	publicID, err := nanoid.Generate(tixer.PublicIDAlphabet, tixer.PublicIDLength)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+		return
+	}
+
+	tenant := tixer.Tenant{
+		PublicID: tixer.PublicID(publicID),
+		Name:     body.Name,
+	}
+	if valid, errs := tenant.Validate(); !valid {
+		s.failedValidationResponse(w, r, errs)
+		return
+	}
+
+	tenantDB, err := s.TenantRepository.Insert(r.Context(), tenant)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+		return
+	}
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("/v1/tenants/%s", tenantDB.PublicID))
+
+	err = s.writeJSON(w, http.StatusCreated, envelope{"tenant": toTenantResponseBody(tenantDB)}, headers)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// tenantUrlQs represents the expected query string parameters for reading tenants.
+type tenantUrlQs struct {
+	page     int
+	pageSize int
+}
+
+// handleReadTenants handles reading the organizers hosted on the deployment.
+func (s *Server) handleReadTenants(w http.ResponseWriter, r *http.Request) {
+	validator := newValidator()
+	qs := validator.validateTenantUrlValues(r.URL.Query())
+	if !validator.valid() {
+		s.failedValidationResponse(w, r, validator.errors)
+		return
+	}
+
+	paginator := psql.NewPaginator(qs.page, qs.pageSize)
+
+	tenantsDB, pagination, err := s.TenantRepository.SelectMultiple(r.Context(), psql.TenantFilter{
+		Limit:  paginator.Limit(),
+		Offset: paginator.Offset(),
+	})
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+		return
+	}
+
+	tenants := make([]tenantResponseBody, len(tenantsDB))
+	for i, tenantDB := range tenantsDB {
+		tenants[i] = toTenantResponseBody(tenantDB)
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"tenants": tenants, "pagination": pagination}, nil)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// handleReadTenant handles reading a single organizer hosted on the deployment.
+func (s *Server) handleReadTenant(w http.ResponseWriter, r *http.Request) {
+	tenant, ok := s.readTenant(w, r)
+	if !ok {
+		return
+	}
+
+	err := s.writeJSON(w, http.StatusOK, envelope{"tenant": toTenantResponseBody(tenant)}, nil)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// handleAddTenantMember handles making a user work for a tenant. A user works for one tenant at most,
+// so the user stops working for the tenant it worked for before.
+func (s *Server) handleAddTenantMember(w http.ResponseWriter, r *http.Request) {
+	tenant, ok := s.readTenant(w, r)
+	if !ok {
+		return
+	}
+
+	userID := r.PathValue("user")
+	if err := tixer.ValidatePublicID(userID); err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	err := s.TenantRepository.AddMember(r.Context(), tenant.ID, tixer.PublicID(userID))
+	if err != nil {
+		switch err {
+		case psql.ErrDbRecordNotFound:
+			s.notFoundResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"message": "tenant member succesfully added"}, nil)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// handleRemoveTenantMember handles stopping a user from working for a tenant.
+func (s *Server) handleRemoveTenantMember(w http.ResponseWriter, r *http.Request) {
+	tenant, ok := s.readTenant(w, r)
+	if !ok {
+		return
+	}
+
+	userID := r.PathValue("user")
+	if err := tixer.ValidatePublicID(userID); err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	err := s.TenantRepository.RemoveMember(r.Context(), tenant.ID, tixer.PublicID(userID))
+	if err != nil {
+		switch err {
+		case psql.ErrDbRecordNotFound:
+			s.notFoundResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"message": "tenant member succesfully removed"}, nil)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// readTenant reads the tenant referenced by the id path parameter.
+// It writes the error response itself and returns false if the tenant cannot be read.
+func (s *Server) readTenant(w http.ResponseWriter, r *http.Request) (tixer.Tenant, bool) {
+	id, err := s.readIDParam(r)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return tixer.Tenant{}, false
+	}
+
+	tenantDB, err := s.TenantRepository.SelectOne(r.Context(), id)
+	if err != nil {
+		switch err {
+		case psql.ErrDbRecordNotFound:
+			s.notFoundResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return tixer.Tenant{}, false
+	}
+
+	return tenantDB, true
+}
+
+// forTenant puts the tenant the request is made for into the context before calling next.
+// Only the routes that work on the data of a tenant resolve it, the others never pay for the lookup.
+func (s *Server) forTenant(next http.HandlerFunc) http.HandlerFunc {
+	return mid.Tenant(s.lookupTenant)(next).ServeHTTP
+}
+
+// lookupTenant resolves the tenant a request is made for, for the Tenant middleware.
+// The users that work for a tenant always act for it; the other users and the anonymous
+// callers act for the tenant named by the request, if any.
+func (s *Server) lookupTenant(ctx context.Context, requested string) (int64, bool, error) {
+	var member *int64
+	if identity := rcontext.GetIdentity(ctx); identity != nil {
+		user, err := s.UserRepository.SelectOne(ctx, tixer.PublicID(identity.Subject))
+		// An unknown user is refused later, by the permission check of the route.
+		if err != nil && err != psql.ErrDbRecordNotFound {
+			return 0, false, err
+		}
+		if err == nil {
+			member = user.TenantID
+		}
+	}
+
+	if requested == "" {
+		if member == nil {
+			return 0, false, nil
+		}
+		return *member, true, nil
+	}
+
+	if err := tixer.ValidatePublicID(requested); err != nil {
+		return 0, false, tixer.Errorf(tixer.EINVALID, "the %s header must be a valid tenant id", mid.TenantHeader)
+	}
+
+	tenantDB, err := s.TenantRepository.SelectOne(ctx, tixer.PublicID(requested))
+	if err != nil {
+		switch err {
+		case psql.ErrDbRecordNotFound:
+			return 0, false, tixer.Errorf(tixer.EINVALID, "the %s header names an unknown tenant", mid.TenantHeader)
+		default:
+			return 0, false, err
+		}
+	}
+
+	if member != nil && *member != tenantDB.ID {
+		return 0, false, tixer.Errorf(tixer.EFORBIDDEN, "you can only act for the tenant you work for")
+	}
+
+	return tenantDB.ID, true, nil
+}
+
+// crossTenantContext returns the context of the request for the records that are not kept per tenant,
+// e.g. the orders of a buyer or the resale market, whose queries reach the tickets of every tenant.
+// The handlers must keep the caller to the records it may reach, as the tenants are no longer kept apart.
+func crossTenantContext(r *http.Request) context.Context {
+	return tixer.NewTenantBypassContext(r.Context())
+}
+
+// orderTenantContext returns the context of the request for the tenant that sold the ticket of the order.
+// The buyer or holder of the order may not act for that tenant, the order gives access to its ticket on its own.
+func orderTenantContext(r *http.Request, orderDB tixer.Order) context.Context {
+	return tixer.NewTenantContext(r.Context(), orderDB.TenantID)
+}
+
+// toTenantResponseBody converts a tenant that was read from DB
+// to a tenant that will be sent in the response body.
+func toTenantResponseBody(tenantDB tixer.Tenant) tenantResponseBody {
+	return tenantResponseBody{
+		PublicID:  string(tenantDB.PublicID),
+		Name:      tenantDB.Name,
+		CreatedAt: tenantDB.CreatedAt,
+	}
+}
@@ -3,6 +3,7 @@ package httpio
 import (
 	"fmt"
 	"net/http"
+	"time"
 
 	nanoid "github.com/matoous/go-nanoid/v2"
 	tixer "github.com/mroobert/monorepo-tixer"
@@ -11,25 +12,76 @@ import (
 
 // registerTicketRoutes registers the ticket resource routes with the server.
 func (s *Server) registerTicketRoutes(r *http.ServeMux) {
-	r.HandleFunc("POST /v1/tickets", s.handleCreateTicket)
-	r.HandleFunc("GET /v1/tickets", s.handleReadTickets)
-	r.HandleFunc("GET /v1/tickets/{id}", s.handleReadTicket)
-	r.HandleFunc("DELETE /v1/tickets/{id}", s.handleDeleteTicket)
-	r.HandleFunc("PATCH /v1/tickets/{id}", s.handleUpdateTicket)
+	r.HandleFunc("POST /v1/tickets", s.authorize(tixer.PermTicketsWrite, s.forTenant(s.handleCreateTicket)))
+	r.HandleFunc("GET /v1/tickets", s.authorize(tixer.PermTicketsRead, s.forTenant(s.handleReadTickets)))
+	r.HandleFunc("GET /v1/tickets/{id}", s.authorize(tixer.PermTicketsRead, s.forTenant(s.handleReadTicket)))
+	r.HandleFunc("DELETE /v1/tickets/{id}", s.authorize(tixer.PermTicketsDelete, s.forTenant(s.handleDeleteTicket)))
+	r.HandleFunc("PATCH /v1/tickets/{id}", s.authorize(tixer.PermTicketsWrite, s.forTenant(s.handleUpdateTicket)))
+	r.HandleFunc("POST /v1/tickets/{id}/publish", s.authorize(tixer.PermTicketsWrite, s.forTenant(s.handlePublishTicket)))
+	r.HandleFunc("POST /v1/tickets/{id}/cancel", s.authorize(tixer.PermTicketsWrite, s.forTenant(s.handleCancelTicket)))
+	r.HandleFunc("POST /v1/tickets/{id}/archive", s.authorize(tixer.PermTicketsWrite, s.forTenant(s.handleArchiveTicket)))
 }
 
 // ticketResponseBody represents the expected fields in the response body for a ticket resource.
 type ticketResponseBody struct {
-	PublicID string `json:"publicID"`
-	Title    string `json:"title"`
-	Price    int64  `json:"price"`
-	Version  int32  `json:"version"`
+	PublicID         string                  `json:"publicID"`
+	EventID          *string                 `json:"eventID,omitempty"`
+	SeatID           *string                 `json:"seatID,omitempty"`
+	Title            string                  `json:"title"`
+	Status           string                  `json:"status"`
+	BasePrice        moneyBody               `json:"basePrice"`
+	EffectivePrice   moneyBody               `json:"effectivePrice"`
+	ConvertedPrice   *convertedPriceBody     `json:"convertedPrice,omitempty"`
+	CurrentTier      *string                 `json:"currentTier,omitempty"`
+	NextTierStartsAt *time.Time              `json:"nextTierStartsAt,omitempty"`
+	PriceTiers       []priceTierResponseBody `json:"priceTiers"`
+	PricingRules     []pricingRuleBody       `json:"pricingRules"`
+	RefundDeadline   *time.Time              `json:"refundDeadline,omitempty"`
+	RefundPercentage int32                   `json:"refundPercentage"`
+	Capacity         int32                   `json:"capacity"`
+	Sold             int32                   `json:"sold"`
+	Held             int32                   `json:"held"`
+	Remaining        int32                   `json:"remaining"`
+	Version          int32                   `json:"version"`
+}
+
+// priceTierBody represents a price tier in the request bodies.
+// The price must be in the currency of the ticket.
+type priceTierBody struct {
+	Name     string    `json:"name"`
+	Price    moneyBody `json:"price"`
+	StartsAt time.Time `json:"startsAt"`
+	Quantity *int32    `json:"quantity"`
+}
+
+// priceTierResponseBody represents the expected fields in the response body for a price tier.
+type priceTierResponseBody struct {
+	Name     string    `json:"name"`
+	Price    moneyBody `json:"price"`
+	StartsAt time.Time `json:"startsAt"`
+	Quantity *int32    `json:"quantity,omitempty"`
+	Sold     int32     `json:"sold"`
+}
+
+// pricingRuleBody represents a pricing rule in the request and response bodies.
+type pricingRuleBody struct {
+	SoldAbove     int32 `json:"soldAbove"`
+	AdjustPercent int32 `json:"adjustPercent"`
 }
 
 // createTicketRequestBody represents the expected request body for creating a new ticket.
+// Price is the base price, used when no price tier is current. The pricing rules adjust the
+// price of the current tier, or the base price, as the ticket sells out.
 type createTicketRequestBody struct {
-	Title string `json:"title"`
-	Price int64  `json:"price"`
+	EventID          string            `json:"eventID"`
+	SeatID           string            `json:"seatID"`
+	Title            string            `json:"title"`
+	Price            moneyBody         `json:"price"`
+	PriceTiers       []priceTierBody   `json:"priceTiers"`
+	PricingRules     []pricingRuleBody `json:"pricingRules"`
+	RefundDeadline   *time.Time        `json:"refundDeadline"`
+	RefundPercentage *int32            `json:"refundPercentage"`
+	Capacity         *int32            `json:"capacity"`
 }
 
 // handleCreateTicket handles the creation of a new ticket in the system.
@@ -42,12 +94,67 @@ func (s *Server) handleCreateTicket(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	user, ok := s.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	tenantID, ok := tixer.TenantFromContext(r.Context())
+	if !ok {
+		s.tenantRequiredResponse(w, r)
+		return
+	}
+	if (user.TenantID == nil || *user.TenantID != tenantID) && !s.can(r, tixer.PermTicketsManage) {
+		s.notTenantMemberResponse(w, r)
+		return
+	}
+
+	rules, err := s.ticketRules(r.Context())
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+		return
+	}
+
 	validator := newValidator()
-	if validator.validateCreateTicketRequestBody(body); !validator.valid() {
+	if validator.validateCreateTicketRequestBody(body, rules); !validator.valid() {
 		s.failedValidationResponse(w, r, validator.errors)
 		return
 	}
 
+	var eventID, seatID *int64
+	var eventPublicID, seatPublicID *tixer.PublicID
+	if body.EventID != "" {
+		eventDB, err := s.EventRepository.SelectOne(r.Context(), tixer.PublicID(body.EventID))
+		if err != nil {
+			switch err {
+			case psql.ErrDbRecordNotFound:
+				s.failedValidationResponse(w, r, map[string]string{"eventID": "must reference an existing event"})
+			default:
+				s.internalServerErrorResponse(w, r, err)
+			}
+			return
+		}
+
+		if !eventDB.OwnedBy(user.ID) && !s.can(r, tixer.PermEventsManage) {
+			s.notOwnerResponse(w, r)
+			return
+		}
+		eventID, eventPublicID = &eventDB.ID, &eventDB.PublicID
+
+		if body.SeatID != "" {
+			seatDB, err := s.SeatRepository.SelectSeat(r.Context(), tixer.PublicID(body.SeatID))
+			if err != nil && err != psql.ErrDbRecordNotFound {
+				s.internalServerErrorResponse(w, r, err)
+				return
+			}
+			if err != nil || eventDB.VenueID == nil || seatDB.VenueID != *eventDB.VenueID {
+				s.failedValidationResponse(w, r, map[string]string{"seatID": "must reference a seat of the event venue"})
+				return
+			}
+			seatID, seatPublicID = &seatDB.ID, &seatDB.PublicID
+		}
+	}
+
 	publicID, err := nanoid.Generate(tixer.PublicIDAlphabet, tixer.PublicIDLength)
 	if err != nil {
 		s.internalServerErrorResponse(w, r, err)
@@ -55,30 +162,47 @@ func (s *Server) handleCreateTicket(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ticket := tixer.Ticket{
-		PublicID: tixer.PublicID(publicID),
-		Title:    body.Title,
-		Price:    body.Price,
+		PublicID:         tixer.PublicID(publicID),
+		OwnerID:          &user.ID,
+		EventID:          eventID,
+		EventPublicID:    eventPublicID,
+		SeatID:           seatID,
+		SeatPublicID:     seatPublicID,
+		Title:            body.Title,
+		Status:           tixer.TicketDraft,
+		Price:            body.Price.toMoney(),
+		PriceTiers:       toPriceTiers(body.PriceTiers, nil),
+		PricingRules:     toPricingRules(body.PricingRules),
+		RefundDeadline:   body.RefundDeadline,
+		RefundPercentage: 100,
+		Capacity:         1,
+	}
+	if body.RefundPercentage != nil {
+		ticket.RefundPercentage = *body.RefundPercentage
 	}
-	if valid, errs := ticket.Validate(); !valid {
+	if body.Capacity != nil {
+		ticket.Capacity = *body.Capacity
+	}
+	if valid, errs := ticket.Validate(rules); !valid {
 		s.failedValidationResponse(w, r, errs)
 		return
 	}
 
 	ticketDB, err := s.TicketRepository.Insert(r.Context(), ticket)
 	if err != nil {
-		s.internalServerErrorResponse(w, r, err)
+		switch err {
+		case psql.ErrDbSeatTaken:
+			s.seatTakenResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
 		return
 	}
 
 	headers := make(http.Header)
 	headers.Set("Location", fmt.Sprintf("/v1/tickets/%d", ticket.ID))
 
-	err = s.writeJSON(w, http.StatusCreated, envelope{"ticket": ticketResponseBody{
-		PublicID: string(ticketDB.PublicID),
-		Title:    ticketDB.Title,
-		Price:    ticketDB.Price,
-		Version:  ticket.Version,
-	}}, headers)
+	err = s.writeJSON(w, http.StatusCreated, envelope{"ticket": toTicketResponseBody([]tixer.Ticket{ticketDB}, time.Now(), rules)[0]}, headers)
 	if err != nil {
 		s.internalServerErrorResponse(w, r, err)
 	}
@@ -87,6 +211,8 @@ func (s *Server) handleCreateTicket(w http.ResponseWriter, r *http.Request) {
 // urlQs represents the expected query string parameters for reading tickets.
 type ticketUrlQs struct {
 	title    string
+	event    string
+	currency tixer.Currency
 	page     int
 	pageSize int
 	sort     string
@@ -111,19 +237,44 @@ func (s *Server) handleReadTickets(w http.ResponseWriter, r *http.Request) {
 
 	paginator := psql.NewPaginator(qs.page, qs.pageSize)
 
-	ticketsDB, pagination, err := s.TicketRepository.SelectMultiple(r.Context(), psql.TicketFilter{
+	filter := tixer.TicketFilter{
 		Title:         qs.title,
 		Limit:         paginator.Limit(),
 		Offset:        paginator.Offset(),
 		SortColumn:    sorter.Column(),
 		SortDirection: sorter.SortDirection(),
-	})
+	}
+	if qs.event != "" {
+		eventID := tixer.PublicID(qs.event)
+		filter.EventID = &eventID
+	}
+
+	ticketsDB, pagination, err := s.TicketRepository.SelectMultiple(r.Context(), filter)
+	if err != nil {
+		switch err {
+		case psql.ErrDbTenantRequired:
+			s.tenantRequiredResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	rules, err := s.ticketRules(r.Context())
 	if err != nil {
 		s.internalServerErrorResponse(w, r, err)
 		return
 	}
 
-	err = s.writeJSON(w, http.StatusOK, envelope{"tickets": toTicketResponseBody(ticketsDB), "pagination": pagination}, nil)
+	now := time.Now()
+	tickets := toTicketResponseBody(ticketsDB, now, rules)
+	if qs.currency != "" {
+		if !s.convertTicketPrices(w, r, qs.currency, tickets, ticketsDB, now, rules) {
+			return
+		}
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"tickets": tickets, "pagination": pagination}, nil)
 	if err != nil {
 		s.internalServerErrorResponse(w, r, err)
 	}
@@ -137,9 +288,18 @@ func (s *Server) handleReadTicket(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	validator := newValidator()
+	currency := validator.readCurrency(r.URL.Query())
+	if !validator.valid() {
+		s.failedValidationResponse(w, r, validator.errors)
+		return
+	}
+
 	ticketDB, err := s.TicketRepository.SelectOne(r.Context(), id)
 	if err != nil {
 		switch err {
+		case psql.ErrDbTenantRequired:
+			s.tenantRequiredResponse(w, r)
 		case psql.ErrDbRecordNotFound:
 			s.notFoundResponse(w, r)
 		default:
@@ -148,12 +308,21 @@ func (s *Server) handleReadTicket(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = s.writeJSON(w, http.StatusOK, envelope{"ticket": ticketResponseBody{
-		PublicID: string(ticketDB.PublicID),
-		Title:    ticketDB.Title,
-		Price:    ticketDB.Price,
-		Version:  ticketDB.Version,
-	}}, nil)
+	rules, err := s.ticketRules(r.Context())
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+		return
+	}
+
+	now := time.Now()
+	tickets := toTicketResponseBody([]tixer.Ticket{ticketDB}, now, rules)
+	if currency != "" {
+		if !s.convertTicketPrices(w, r, currency, tickets, []tixer.Ticket{ticketDB}, now, rules) {
+			return
+		}
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"ticket": tickets[0]}, nil)
 	if err != nil {
 		s.internalServerErrorResponse(w, r, err)
 	}
@@ -161,8 +330,13 @@ func (s *Server) handleReadTicket(w http.ResponseWriter, r *http.Request) {
 
 // updateTicketRequestBody represents the expected request body for updating an existing ticket.
 type updateTicketRequestBody struct {
-	Title *string `json:"title"`
-	Price *int64  `json:"price"`
+	Title            *string            `json:"title"`
+	Price            *moneyBody         `json:"price"`
+	PriceTiers       *[]priceTierBody   `json:"priceTiers"`   // replaces all the tiers, an empty list removes them
+	PricingRules     *[]pricingRuleBody `json:"pricingRules"` // replaces all the rules, an empty list removes them
+	RefundDeadline   *time.Time         `json:"refundDeadline"`
+	RefundPercentage *int32             `json:"refundPercentage"`
+	Capacity         *int32             `json:"capacity"`
 }
 
 // handleUpdateTicket handles updating a ticket in the system.
@@ -172,11 +346,19 @@ func (s *Server) handleUpdateTicket(w http.ResponseWriter, r *http.Request) {
 	id, err := s.readIDParam(r)
 	if err != nil {
 		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	user, ok := s.currentUser(w, r)
+	if !ok {
+		return
 	}
 
 	ticketDB, err := s.TicketRepository.SelectOne(r.Context(), id)
 	if err != nil {
 		switch err {
+		case psql.ErrDbTenantRequired:
+			s.tenantRequiredResponse(w, r)
 		case psql.ErrDbRecordNotFound:
 			s.notFoundResponse(w, r)
 		default:
@@ -185,21 +367,61 @@ func (s *Server) handleUpdateTicket(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !ticketDB.OwnedBy(user.ID) && !s.can(r, tixer.PermTicketsManage) {
+		s.notOwnerResponse(w, r)
+		return
+	}
+
 	err = s.readJSON(w, r, &body)
 	if err != nil {
 		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	titleOrPriceChanged := (body.Title != nil && *body.Title != ticketDB.Title) ||
+		(body.Price != nil && body.Price.toMoney() != ticketDB.Price)
+	if err := ticketDB.CheckUpdate(titleOrPriceChanged); err != nil {
+		s.domainErrorResponse(w, r, err)
+		return
 	}
 
 	if body.Title != nil {
 		ticketDB.Title = *body.Title
 	}
 	if body.Price != nil {
-		ticketDB.Price = *body.Price
+		ticketDB.Price = body.Price.toMoney()
+	}
+	if body.PriceTiers != nil {
+		tiers := toPriceTiers(*body.PriceTiers, ticketDB.PriceTiers)
+		if reason := tixer.CheckRemovedPriceTiers(ticketDB.PriceTiers, tiers); reason != "" {
+			s.failedValidationResponse(w, r, map[string]string{"priceTiers": reason})
+			return
+		}
+		ticketDB.PriceTiers = tiers
+	}
+	if body.PricingRules != nil {
+		ticketDB.PricingRules = toPricingRules(*body.PricingRules)
+	}
+	if body.RefundDeadline != nil {
+		ticketDB.RefundDeadline = body.RefundDeadline
+	}
+	if body.RefundPercentage != nil {
+		ticketDB.RefundPercentage = *body.RefundPercentage
+	}
+	if body.Capacity != nil {
+		ticketDB.Capacity = *body.Capacity
+	}
+
+	rules, err := s.ticketRules(r.Context())
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+		return
 	}
 
-	valid, errs := ticketDB.Validate()
+	valid, errs := ticketDB.Validate(rules)
 	if !valid {
 		s.failedValidationResponse(w, r, errs)
+		return
 	}
 
 	err = s.TicketRepository.Update(r.Context(), &ticketDB)
@@ -213,12 +435,83 @@ func (s *Server) handleUpdateTicket(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = s.writeJSON(w, http.StatusOK, envelope{"ticket": ticketResponseBody{
-		PublicID: string(ticketDB.PublicID),
-		Title:    ticketDB.Title,
-		Price:    ticketDB.Price,
-		Version:  ticketDB.Version,
-	}}, nil)
+	err = s.writeJSON(w, http.StatusOK, envelope{"ticket": toTicketResponseBody([]tixer.Ticket{ticketDB}, time.Now(), rules)[0]}, nil)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// handlePublishTicket handles the owner putting a draft ticket on sale.
+func (s *Server) handlePublishTicket(w http.ResponseWriter, r *http.Request) {
+	s.transitionTicket(w, r, tixer.TicketPublished)
+}
+
+// handleCancelTicket handles the owner taking a ticket off sale for good.
+// The orders already placed for it are kept.
+func (s *Server) handleCancelTicket(w http.ResponseWriter, r *http.Request) {
+	s.transitionTicket(w, r, tixer.TicketCancelled)
+}
+
+// handleArchiveTicket handles the owner archiving a ticket that is no longer on sale or sold.
+func (s *Server) handleArchiveTicket(w http.ResponseWriter, r *http.Request) {
+	s.transitionTicket(w, r, tixer.TicketArchived)
+}
+
+// transitionTicket moves the ticket referenced by the id path parameter to the given state
+// on behalf of its owner and writes the response.
+func (s *Server) transitionTicket(w http.ResponseWriter, r *http.Request, status tixer.TicketStatus) {
+	id, err := s.readIDParam(r)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	user, ok := s.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	ticketDB, err := s.TicketRepository.SelectOne(r.Context(), id)
+	if err != nil {
+		switch err {
+		case psql.ErrDbTenantRequired:
+			s.tenantRequiredResponse(w, r)
+		case psql.ErrDbRecordNotFound:
+			s.notFoundResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if !ticketDB.OwnedBy(user.ID) && !s.can(r, tixer.PermTicketsManage) {
+		s.notOwnerResponse(w, r)
+		return
+	}
+
+	if err := ticketDB.Transition(status); err != nil {
+		s.domainErrorResponse(w, r, err)
+		return
+	}
+
+	err = s.TicketRepository.UpdateStatus(r.Context(), &ticketDB)
+	if err != nil {
+		switch err {
+		case psql.ErrDbEditConflict:
+			s.editConflictResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	rules, err := s.ticketRules(r.Context())
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"ticket": toTicketResponseBody([]tixer.Ticket{ticketDB}, time.Now(), rules)[0]}, nil)
 	if err != nil {
 		s.internalServerErrorResponse(w, r, err)
 	}
@@ -232,11 +525,36 @@ func (s *Server) handleDeleteTicket(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	user, ok := s.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	ticketDB, err := s.TicketRepository.SelectOne(r.Context(), id)
+	if err != nil {
+		switch err {
+		case psql.ErrDbTenantRequired:
+			s.tenantRequiredResponse(w, r)
+		case psql.ErrDbRecordNotFound:
+			s.notFoundResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if !ticketDB.OwnedBy(user.ID) && !s.can(r, tixer.PermTicketsManage) {
+		s.notOwnerResponse(w, r)
+		return
+	}
+
 	err = s.TicketRepository.Delete(r.Context(), id)
 	if err != nil {
 		switch err {
 		case psql.ErrDbRecordNotFound:
 			s.notFoundResponse(w, r)
+		case psql.ErrDbRecordInUse:
+			s.recordInUseResponse(w, r)
 		default:
 			s.internalServerErrorResponse(w, r, err)
 		}
@@ -249,17 +567,118 @@ func (s *Server) handleDeleteTicket(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// convertTicketPrices sets the effective price of the tickets at the given moment, bound by the rules
+// of their tenant, converted to the currency requested by the caller.
+// It writes the error response itself and returns false if a price cannot be converted.
+func (s *Server) convertTicketPrices(w http.ResponseWriter, r *http.Request, currency tixer.Currency, tickets []ticketResponseBody, ticketsDB []tixer.Ticket, at time.Time, rules tixer.TicketRules) bool {
+	converter := s.newPriceConverter(currency)
+	for i, ticketDB := range ticketsDB {
+		convertedPrice, err := converter.convert(r.Context(), ticketDB.EffectivePrice(at, rules))
+		if err != nil {
+			switch err {
+			case psql.ErrDbRecordNotFound:
+				message := fmt.Sprintf("no exchange rate from %s is available", ticketDB.Price.Currency)
+				s.failedValidationResponse(w, r, map[string]string{"currency": message})
+			default:
+				s.domainErrorResponse(w, r, err)
+			}
+			return false
+		}
+		tickets[i].ConvertedPrice = convertedPrice
+	}
+
+	return true
+}
+
 // toTicketResponseBody converts a slice of tickets that was read from DB
-// to a slice of tickets that will be sent in the response body.
-func toTicketResponseBody(ticketsDB []tixer.Ticket) []ticketResponseBody {
+// to a slice of tickets that will be sent in the response body,
+// with their current price tier and effective price at the given moment, bound by the rules of their tenant.
+func toTicketResponseBody(ticketsDB []tixer.Ticket, at time.Time, rules tixer.TicketRules) []ticketResponseBody {
 	tickets := make([]ticketResponseBody, len(ticketsDB))
 	for i, ticketDB := range ticketsDB {
 		tickets[i] = ticketResponseBody{
-			PublicID: string(ticketDB.PublicID),
-			Title:    ticketDB.Title,
-			Price:    ticketDB.Price,
-			Version:  ticketDB.Version,
+			PublicID:         string(ticketDB.PublicID),
+			EventID:          (*string)(ticketDB.EventPublicID),
+			SeatID:           (*string)(ticketDB.SeatPublicID),
+			Title:            ticketDB.Title,
+			Status:           string(ticketDB.Status),
+			BasePrice:        toMoneyBody(ticketDB.Price),
+			EffectivePrice:   toMoneyBody(ticketDB.EffectivePrice(at, rules)),
+			PriceTiers:       toPriceTierBodies(ticketDB.PriceTiers),
+			PricingRules:     toPricingRuleBodies(ticketDB.PricingRules),
+			RefundDeadline:   ticketDB.RefundDeadline,
+			RefundPercentage: ticketDB.RefundPercentage,
+			Capacity:         ticketDB.Capacity,
+			Sold:             ticketDB.Sold,
+			Held:             ticketDB.Held,
+			Remaining:        ticketDB.Remaining(),
+			Version:          ticketDB.Version,
+		}
+
+		current, next := ticketDB.CurrentTier(at)
+		if current != nil {
+			tickets[i].CurrentTier = &current.Name
+		}
+		if next != nil {
+			tickets[i].NextTierStartsAt = &next.StartsAt
 		}
 	}
 	return tickets
 }
+
+// toPriceTiers converts the price tiers of a request body to domain values.
+// The tiers that keep the name of a current tier keep its ID and the count of units sold at its price.
+func toPriceTiers(bodies []priceTierBody, current []tixer.PriceTier) []tixer.PriceTier {
+	kept := make(map[string]tixer.PriceTier, len(current))
+	for _, tier := range current {
+		kept[tier.Name] = tier
+	}
+
+	tiers := make([]tixer.PriceTier, len(bodies))
+	for i, body := range bodies {
+		tiers[i] = tixer.PriceTier{
+			ID:       kept[body.Name].ID,
+			Name:     body.Name,
+			Price:    body.Price.toMoney(),
+			StartsAt: body.StartsAt,
+			Quantity: body.Quantity,
+			Sold:     kept[body.Name].Sold,
+		}
+	}
+	return tiers
+}
+
+// toPriceTierBodies converts price tiers that were read from DB
+// to price tiers that will be sent in the response body.
+func toPriceTierBodies(tiers []tixer.PriceTier) []priceTierResponseBody {
+	bodies := make([]priceTierResponseBody, len(tiers))
+	for i, tier := range tiers {
+		bodies[i] = priceTierResponseBody{
+			Name:     tier.Name,
+			Price:    toMoneyBody(tier.Price),
+			StartsAt: tier.StartsAt,
+			Quantity: tier.Quantity,
+			Sold:     tier.Sold,
+		}
+	}
+	return bodies
+}
+
+// toPricingRules converts the pricing rules of a request body to domain values.
+func toPricingRules(bodies []pricingRuleBody) []tixer.PricingRule {
+	rules := make([]tixer.PricingRule, len(bodies))
+	for i, body := range bodies {
+		rules[i] = tixer.PricingRule{SoldAbove: body.SoldAbove, AdjustPercent: body.AdjustPercent}
+	}
+	return rules
+}
+
+// toPricingRuleBodies converts pricing rules that were read from DB
+// to pricing rules that will be sent in the response body.
+func toPricingRuleBodies(rules []tixer.PricingRule) []pricingRuleBody {
+	bodies := make([]pricingRuleBody, len(rules))
+	for i, rule := range rules {
+		bodies[i] = pricingRuleBody{SoldAbove: rule.SoldAbove, AdjustPercent: rule.AdjustPercent}
+	}
+	return bodies
+}
@@ -0,0 +1,318 @@
+package httpio
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	nanoid "github.com/matoous/go-nanoid/v2"
+	tixer "github.com/mroobert/monorepo-tixer"
+	"github.com/mroobert/monorepo-tixer/psql"
+)
+
+// registerTransferRoutes registers the ticket transfer routes with the server.
+func (s *Server) registerTransferRoutes(r *http.ServeMux) {
+	r.HandleFunc("POST /v1/tickets/{id}/transfers", s.authorize(tixer.PermTicketsWrite, s.forTenant(s.handleCreateTransfer)))
+	r.HandleFunc("GET /v1/transfers", s.authorize(tixer.PermTicketsRead, s.handleReadTransfers))
+	r.HandleFunc("GET /v1/transfers/{id}", s.authorize(tixer.PermTicketsRead, s.handleReadTransfer))
+	r.HandleFunc("POST /v1/transfers/{id}/accept", s.authorize(tixer.PermTicketsWrite, s.handleAcceptTransfer))
+	r.HandleFunc("POST /v1/transfers/{id}/decline", s.authorize(tixer.PermTicketsRead, s.handleDeclineTransfer))
+	r.HandleFunc("POST /v1/transfers/{id}/cancel", s.authorize(tixer.PermTicketsWrite, s.handleCancelTransfer))
+}
+
+// transferResponseBody represents the expected fields in the response body for a ticket transfer.
+type transferResponseBody struct {
+	PublicID    string     `json:"publicID"`
+	TicketID    string     `json:"ticketID"`
+	FromEmail   string     `json:"fromEmail"`
+	ToEmail     string     `json:"toEmail"`
+	Status      string     `json:"status"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	RespondedAt *time.Time `json:"respondedAt,omitempty"`
+}
+
+// createTransferRequestBody represents the expected request body for transferring a ticket.
+type createTransferRequestBody struct {
+	Email string `json:"email"`
+}
+
+// handleCreateTransfer handles offering a ticket to another user, addressed by email.
+// The ticket stays with its owner until the recipient accepts the transfer. The response does not
+// depend on whether the email has an account, the recipient can accept the transfer after signing up.
+func (s *Server) handleCreateTransfer(w http.ResponseWriter, r *http.Request) {
+	var body createTransferRequestBody
+
+	id, err := s.readIDParam(r)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	err = s.readJSON(w, r, &body)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	validator := newValidator()
+	if validator.validateCreateTransferRequestBody(body); !validator.valid() {
+		s.failedValidationResponse(w, r, validator.errors)
+		return
+	}
+
+	user, ok := s.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	ticketDB, err := s.TicketRepository.SelectOne(r.Context(), id)
+	if err != nil {
+		switch err {
+		case psql.ErrDbTenantRequired:
+			s.tenantRequiredResponse(w, r)
+		case psql.ErrDbRecordNotFound:
+			s.notFoundResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if !ticketDB.OwnedBy(user.ID) {
+		s.notOwnerResponse(w, r)
+		return
+	}
+
+	email := strings.ToLower(body.Email)
+	if email == user.Email {
+		s.failedValidationResponse(w, r, map[string]string{"email": "must not be your own email"})
+		return
+	}
+
+	publicID, err := nanoid.Generate(tixer.PublicIDAlphabet, tixer.PublicIDLength)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+		return
+	}
+
+	transferDB, err := s.TransferRepository.Insert(r.Context(), tixer.Transfer{
+		PublicID:   tixer.PublicID(publicID),
+		TicketID:   ticketDB.ID,
+		FromUserID: user.ID,
+		ToEmail:    email,
+	})
+	if err != nil {
+		switch err {
+		case psql.ErrDbTransferPending:
+			s.transferPendingResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("/v1/transfers/%s", transferDB.PublicID))
+
+	err = s.writeJSON(w, http.StatusCreated, envelope{"transfer": toTransferResponseBody(transferDB)}, headers)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// transferUrlQs represents the expected query string parameters for reading transfers.
+type transferUrlQs struct {
+	page     int
+	pageSize int
+}
+
+// handleReadTransfers handles reading the transfers sent by the current user or addressed to their email.
+func (s *Server) handleReadTransfers(w http.ResponseWriter, r *http.Request) {
+	validator := newValidator()
+	qs := validator.validateTransferUrlValues(r.URL.Query())
+	if !validator.valid() {
+		s.failedValidationResponse(w, r, validator.errors)
+		return
+	}
+
+	user, ok := s.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	paginator := psql.NewPaginator(qs.page, qs.pageSize)
+
+	transfersDB, pagination, err := s.TransferRepository.SelectMultiple(crossTenantContext(r), psql.TransferFilter{
+		UserID: user.ID,
+		Email:  user.Email,
+		Limit:  paginator.Limit(),
+		Offset: paginator.Offset(),
+	})
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+		return
+	}
+
+	transfers := make([]transferResponseBody, len(transfersDB))
+	for i, transferDB := range transfersDB {
+		transfers[i] = toTransferResponseBody(transferDB)
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"transfers": transfers, "pagination": pagination}, nil)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// handleReadTransfer handles reading a single transfer sent or received by the current user.
+func (s *Server) handleReadTransfer(w http.ResponseWriter, r *http.Request) {
+	transferDB, _, ok := s.readTransfer(w, r, func(t tixer.Transfer, user tixer.User) bool {
+		return t.SentBy(user) || t.SentTo(user)
+	})
+	if !ok {
+		return
+	}
+
+	err := s.writeJSON(w, http.StatusOK, envelope{"transfer": toTransferResponseBody(transferDB)}, nil)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// handleAcceptTransfer handles the recipient accepting a transfer, which makes them the owner of the ticket.
+// Only a recipient that works for the organizer of the ticket can take it over.
+func (s *Server) handleAcceptTransfer(w http.ResponseWriter, r *http.Request) {
+	transferDB, user, ok := s.readTransfer(w, r, tixer.Transfer.SentTo)
+	if !ok {
+		return
+	}
+
+	if user.TenantID == nil {
+		s.recipientOutsideTenantResponse(w, r)
+		return
+	}
+	ctx := tixer.NewTenantContext(r.Context(), *user.TenantID)
+
+	ticketDB, err := s.TicketRepository.SelectOne(ctx, transferDB.TicketPublicID)
+	if err != nil {
+		switch err {
+		case psql.ErrDbRecordNotFound:
+			s.recipientOutsideTenantResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = s.TransferRepository.Accept(ctx, &transferDB, &ticketDB, user)
+	if err != nil {
+		switch err {
+		case psql.ErrDbEditConflict:
+			s.editConflictResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	rules, err := s.ticketRules(ctx)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+		return
+	}
+
+	tickets := toTicketResponseBody([]tixer.Ticket{ticketDB}, time.Now(), rules)
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"transfer": toTransferResponseBody(transferDB), "ticket": tickets[0]}, nil)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// handleDeclineTransfer handles the recipient declining a transfer.
+func (s *Server) handleDeclineTransfer(w http.ResponseWriter, r *http.Request) {
+	transferDB, user, ok := s.readTransfer(w, r, tixer.Transfer.SentTo)
+	if !ok {
+		return
+	}
+
+	s.closeTransfer(w, r, transferDB, tixer.TransferDeclined, &user.ID)
+}
+
+// handleCancelTransfer handles the sender withdrawing a transfer before it is answered.
+func (s *Server) handleCancelTransfer(w http.ResponseWriter, r *http.Request) {
+	transferDB, _, ok := s.readTransfer(w, r, tixer.Transfer.SentBy)
+	if !ok {
+		return
+	}
+
+	s.closeTransfer(w, r, transferDB, tixer.TransferCancelled, nil)
+}
+
+// closeTransfer ends a pending transfer with the given status and writes the response.
+func (s *Server) closeTransfer(w http.ResponseWriter, r *http.Request, transferDB tixer.Transfer, status tixer.TransferStatus, responderID *int64) {
+	err := s.TransferRepository.Close(r.Context(), &transferDB, status, responderID)
+	if err != nil {
+		switch err {
+		case psql.ErrDbEditConflict:
+			s.editConflictResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"transfer": toTransferResponseBody(transferDB)}, nil)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// readTransfer reads the transfer referenced by the id path parameter and checks that the current
+// user is allowed to act on it. It returns the transfer with the current user, or writes the error
+// response itself and returns false.
+func (s *Server) readTransfer(w http.ResponseWriter, r *http.Request, allowed func(tixer.Transfer, tixer.User) bool) (tixer.Transfer, tixer.User, bool) {
+	id, err := s.readIDParam(r)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return tixer.Transfer{}, tixer.User{}, false
+	}
+
+	user, ok := s.currentUser(w, r)
+	if !ok {
+		return tixer.Transfer{}, tixer.User{}, false
+	}
+
+	transferDB, err := s.TransferRepository.SelectOne(crossTenantContext(r), id)
+	if err != nil {
+		switch err {
+		case psql.ErrDbRecordNotFound:
+			s.notFoundResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return tixer.Transfer{}, tixer.User{}, false
+	}
+
+	if !allowed(transferDB, user) {
+		s.notOwnerResponse(w, r)
+		return tixer.Transfer{}, tixer.User{}, false
+	}
+
+	return transferDB, user, true
+}
+
+// toTransferResponseBody converts a transfer that was read from DB
+// to a transfer that will be sent in the response body.
+func toTransferResponseBody(transferDB tixer.Transfer) transferResponseBody {
+	return transferResponseBody{
+		PublicID:    string(transferDB.PublicID),
+		TicketID:    string(transferDB.TicketPublicID),
+		FromEmail:   transferDB.FromEmail,
+		ToEmail:     transferDB.ToEmail,
+		Status:      string(transferDB.Status),
+		CreatedAt:   transferDB.CreatedAt,
+		RespondedAt: transferDB.RespondedAt,
+	}
+}
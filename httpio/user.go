@@ -0,0 +1,171 @@
+package httpio
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	nanoid "github.com/matoous/go-nanoid/v2"
+	tixer "github.com/mroobert/monorepo-tixer"
+	"github.com/mroobert/monorepo-tixer/psql"
+)
+
+// registerUserRoutes registers the user resource routes with the server.
+func (s *Server) registerUserRoutes(r *http.ServeMux) {
+	r.HandleFunc("POST /v1/users", s.handleCreateUser)
+	r.HandleFunc("POST /v1/users/signin", s.handleSigninUser)
+}
+
+// userResponseBody represents the expected fields in the response body for a user resource.
+type userResponseBody struct {
+	PublicID string `json:"publicID"`
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Version  int32  `json:"version"`
+}
+
+// createUserRequestBody represents the expected request body for signing up a new user.
+type createUserRequestBody struct {
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// handleCreateUser handles the sign up of a new user.
+func (s *Server) handleCreateUser(w http.ResponseWriter, r *http.Request) {
+	var body createUserRequestBody
+
+	err := s.readJSON(w, r, &body)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	validator := newValidator()
+	if validator.validateCreateUserRequestBody(body); !validator.valid() {
+		s.failedValidationResponse(w, r, validator.errors)
+		return
+	}
+
+	publicID, err := nanoid.Generate(tixer.PublicIDAlphabet, tixer.PublicIDLength)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+		return
+	}
+
+	user := tixer.User{
+		PublicID: tixer.PublicID(publicID),
+		Name:     body.Name,
+		Email:    strings.ToLower(body.Email),
+	}
+	if valid, errs := user.Validate(); !valid {
+		s.failedValidationResponse(w, r, errs)
+		return
+	}
+
+	err = user.SetPassword(body.Password)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+		return
+	}
+
+	userDB, err := s.UserRepository.Insert(r.Context(), user)
+	if err != nil {
+		switch err {
+		case psql.ErrDbDuplicateEmail:
+			s.duplicateEmailResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("/v1/users/%s", userDB.PublicID))
+
+	err = s.writeJSON(w, http.StatusCreated, envelope{"user": toUserResponseBody(userDB)}, headers)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// tokenResponseBody represents the expected fields in the response body for an authentication token.
+type tokenResponseBody struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// signinUserRequestBody represents the expected request body for signing in a user.
+type signinUserRequestBody struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// handleSigninUser handles the sign in of a user with email and password
+// and issues an authentication token for the user.
+func (s *Server) handleSigninUser(w http.ResponseWriter, r *http.Request) {
+	var body signinUserRequestBody
+
+	err := s.readJSON(w, r, &body)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	validator := newValidator()
+	if validator.validateSigninUserRequestBody(body); !validator.valid() {
+		s.failedValidationResponse(w, r, validator.errors)
+		return
+	}
+
+	userDB, err := s.UserRepository.SelectByEmail(r.Context(), strings.ToLower(body.Email))
+	if err != nil {
+		switch err {
+		case psql.ErrDbRecordNotFound:
+			tixer.ComparePasswordToDummy(body.Password)
+			s.invalidCredentialsResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	match, err := userDB.PasswordMatches(body.Password)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+		return
+	}
+	if !match {
+		s.invalidCredentialsResponse(w, r)
+		return
+	}
+
+	token, expiresAt, err := s.authenticator.Issue(string(userDB.PublicID))
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{
+		"user": toUserResponseBody(userDB),
+		"authenticationToken": tokenResponseBody{
+			Token:     token,
+			ExpiresAt: expiresAt,
+		},
+	}, nil)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// toUserResponseBody converts a user that was read from DB
+// to a user that will be sent in the response body.
+func toUserResponseBody(userDB tixer.User) userResponseBody {
+	return userResponseBody{
+		PublicID: string(userDB.PublicID),
+		Name:     userDB.Name,
+		Email:    userDB.Email,
+		Version:  userDB.Version,
+	}
+}
@@ -3,6 +3,9 @@ package httpio
 import (
 	"net/url"
 	"strconv"
+	"strings"
+
+	tixer "github.com/mroobert/monorepo-tixer"
 )
 
 // validator represents a data parser & validator for the http request payload.
@@ -34,6 +37,13 @@ func (v *validator) check(ok bool, key, message string) {
 	}
 }
 
+// checkRule adds the violation of a ticket rule, named after the rule, to the validator.
+func (v *validator) checkRule(violation *tixer.RuleViolation, key string) {
+	if violation != nil {
+		v.addError(key, violation.String())
+	}
+}
+
 // readString reads a string value from the url query string.
 func (v *validator) readString(qs url.Values, key string, defaultValue string) string {
 	value := qs.Get(key)
@@ -58,15 +68,39 @@ func (v *validator) readInt(qs url.Values, key string, defaultValue int) int {
 	return intValue
 }
 
-// validateCreateTicketBody validates the create ticket request body.
-func (v *validator) validateCreateTicketRequestBody(body createTicketRequestBody) {
+// validateCreateTicketBody validates the create ticket request body against the ticket rules of its organizer.
+func (v *validator) validateCreateTicketRequestBody(body createTicketRequestBody, rules tixer.TicketRules) {
 	v.check(body.Title != "", "title", "must be provided")
-	v.check(body.Price > 0, "price", "must be provided")
+	v.checkRule(rules.CheckTitle(body.Title), "title")
+	v.check(body.Price.Amount > 0, "price.amount", "must be provided")
+	v.check(body.Price.Currency != "", "price.currency", "must be provided")
+	if body.Price.Amount > 0 && body.Price.Currency != "" {
+		v.checkRule(rules.CheckPrice(body.Price.toMoney()), "price")
+	}
+	if body.EventID != "" {
+		v.check(tixer.ValidatePublicID(body.EventID) == nil, "eventID", "must be a valid event id")
+	}
+	if body.SeatID != "" {
+		v.check(body.EventID != "", "eventID", "must be provided for a seated ticket")
+		v.check(tixer.ValidatePublicID(body.SeatID) == nil, "seatID", "must be a valid seat id")
+	}
+}
+
+// readCurrency reads the optional currency query string parameter in which the prices are converted.
+// The currency code is accepted in any letter case.
+func (v *validator) readCurrency(qs url.Values) tixer.Currency {
+	currency := strings.ToUpper(v.readString(qs, "currency", ""))
+	if currency != "" {
+		v.check(tixer.ValidateCurrency(currency) == nil, "currency", "must be a supported currency")
+	}
+	return tixer.Currency(currency)
 }
 
 // validateTicketUrlValues validates the url query string parameters used for reading multiple rows of tickets.
 func (v *validator) validateTicketUrlValues(qs url.Values, sortSafeList []string) ticketUrlQs {
 	title := v.readString(qs, "title", "")
+	event := v.readString(qs, "event", "")
+	currency := v.readCurrency(qs)
 	page := v.readInt(qs, "page", 1)
 	pageSize := v.readInt(qs, "pageSize", 10)
 	sort := v.readString(qs, "sort", "id")
@@ -76,14 +110,258 @@ func (v *validator) validateTicketUrlValues(qs url.Values, sortSafeList []string
 
 	v.check(permittedValue(sort, sortSafeList...), "sort", "invalid sort value")
 
+	if event != "" {
+		v.check(tixer.ValidatePublicID(event) == nil, "event", "must be a valid event id")
+	}
+
 	return ticketUrlQs{
 		title:    title,
+		event:    event,
+		currency: currency,
+		page:     page,
+		pageSize: pageSize,
+		sort:     sort,
+	}
+}
+
+// validateCreateOrderRequestBody validates the create order request body.
+func (v *validator) validateCreateOrderRequestBody(body createOrderRequestBody) {
+	v.check(body.TicketID != "", "ticketID", "must be provided")
+	if body.TicketID != "" {
+		v.check(tixer.ValidatePublicID(body.TicketID) == nil, "ticketID", "must be a valid ticket id")
+	}
+}
+
+// validateCreatePromoCodeRequestBody validates the create promo code request body.
+// The remaining business rules are checked by the promo code itself.
+func (v *validator) validateCreatePromoCodeRequestBody(body createPromoCodeRequestBody) {
+	switch tixer.PromoScope(body.Scope) {
+	case tixer.PromoScopeEvent:
+		v.check(tixer.ValidatePublicID(body.EventID) == nil, "eventID", "must be a valid event id")
+	case tixer.PromoScopeTicket:
+		v.check(tixer.ValidatePublicID(body.TicketID) == nil, "ticketID", "must be a valid ticket id")
+	}
+}
+
+// validatePromoCodeUrlValues validates the url query string parameters used for reading multiple rows of promo codes.
+func (v *validator) validatePromoCodeUrlValues(qs url.Values) promoCodeUrlQs {
+	page := v.readInt(qs, "page", 1)
+	pageSize := v.readInt(qs, "pageSize", 10)
+
+	v.check(page >= 1, "page", "must be greater than 0")
+	v.check(page <= 1000, "page", "must be a maximum of 1000")
+	v.check(pageSize >= 1, "pageSize", "must be greater than 0")
+	v.check(pageSize <= 25, "pageSize", "must be a maximum of 25")
+
+	return promoCodeUrlQs{
+		page:     page,
+		pageSize: pageSize,
+	}
+}
+
+// validateOrderUrlValues validates the url query string parameters used for reading multiple rows of orders.
+func (v *validator) validateOrderUrlValues(qs url.Values) orderUrlQs {
+	status := v.readString(qs, "status", "")
+	page := v.readInt(qs, "page", 1)
+	pageSize := v.readInt(qs, "pageSize", 10)
+
+	v.check(page >= 1, "page", "must be greater than 0")
+	v.check(page <= 1000, "page", "must be a maximum of 1000")
+	v.check(pageSize >= 1, "pageSize", "must be greater than 0")
+	v.check(pageSize <= 25, "pageSize", "must be a maximum of 25")
+
+	if status != "" {
+		v.check(permittedValue(tixer.OrderStatus(status), tixer.OrderPending, tixer.OrderPaying, tixer.OrderPaid, tixer.OrderRefunding, tixer.OrderCancelled, tixer.OrderExpired, tixer.OrderRefunded), "status", "invalid status value")
+	}
+
+	return orderUrlQs{
+		status:   status,
+		page:     page,
+		pageSize: pageSize,
+	}
+}
+
+// validateCreatePaymentRequestBody validates the create payment request body.
+func (v *validator) validateCreatePaymentRequestBody(body createPaymentRequestBody) {
+	v.check(body.OrderID != "", "orderID", "must be provided")
+	if body.OrderID != "" {
+		v.check(tixer.ValidatePublicID(body.OrderID) == nil, "orderID", "must be a valid order id")
+	}
+	v.check(body.PaymentToken != "", "paymentToken", "must be provided")
+}
+
+// validateCancelOrderRequestBody validates the cancel order request body.
+func (v *validator) validateCancelOrderRequestBody(body cancelOrderRequestBody) {
+	if body.Amount != nil {
+		v.check(*body.Amount >= 0, "amount", "must not be negative")
+	}
+	v.check(len(body.Reason) <= 500, "reason", "must not be more than 500 characters long")
+}
+
+// validateCreateTransferRequestBody validates the create transfer request body.
+func (v *validator) validateCreateTransferRequestBody(body createTransferRequestBody) {
+	v.check(body.Email != "", "email", "must be provided")
+	v.check(tixer.ValidEmail(body.Email), "email", "must be a valid email address")
+}
+
+// validateTransferUrlValues validates the url query string parameters used for reading multiple rows of transfers.
+func (v *validator) validateTransferUrlValues(qs url.Values) transferUrlQs {
+	page := v.readInt(qs, "page", 1)
+	pageSize := v.readInt(qs, "pageSize", 10)
+
+	v.check(page >= 1, "page", "must be greater than 0")
+	v.check(page <= 1000, "page", "must be a maximum of 1000")
+	v.check(pageSize >= 1, "pageSize", "must be greater than 0")
+	v.check(pageSize <= 25, "pageSize", "must be a maximum of 25")
+
+	return transferUrlQs{
+		page:     page,
+		pageSize: pageSize,
+	}
+}
+
+// validatePurchaseResaleListingRequestBody validates the purchase resale listing request body.
+func (v *validator) validatePurchaseResaleListingRequestBody(body purchaseResaleListingRequestBody) {
+	v.check(body.PaymentToken != "", "paymentToken", "must be provided")
+}
+
+// validateResaleUrlValues validates the url query string parameters used for reading multiple rows of resale listings.
+func (v *validator) validateResaleUrlValues(qs url.Values) resaleUrlQs {
+	ticket := v.readString(qs, "ticket", "")
+	page := v.readInt(qs, "page", 1)
+	pageSize := v.readInt(qs, "pageSize", 10)
+
+	v.check(page >= 1, "page", "must be greater than 0")
+	v.check(page <= 1000, "page", "must be a maximum of 1000")
+	v.check(pageSize >= 1, "pageSize", "must be greater than 0")
+	v.check(pageSize <= 25, "pageSize", "must be a maximum of 25")
+
+	if ticket != "" {
+		v.check(tixer.ValidatePublicID(ticket) == nil, "ticket", "must be a valid ticket id")
+	}
+
+	return resaleUrlQs{
+		ticket:   ticket,
+		page:     page,
+		pageSize: pageSize,
+	}
+}
+
+// validatePayoutUrlValues validates the url query string parameters used for reading multiple rows of payouts.
+func (v *validator) validatePayoutUrlValues(qs url.Values) payoutUrlQs {
+	page := v.readInt(qs, "page", 1)
+	pageSize := v.readInt(qs, "pageSize", 10)
+
+	v.check(page >= 1, "page", "must be greater than 0")
+	v.check(page <= 1000, "page", "must be a maximum of 1000")
+	v.check(pageSize >= 1, "pageSize", "must be greater than 0")
+	v.check(pageSize <= 25, "pageSize", "must be a maximum of 25")
+
+	return payoutUrlQs{
+		page:     page,
+		pageSize: pageSize,
+	}
+}
+
+// validateCreateCheckInRequestBody validates the create check-in request body.
+func (v *validator) validateCreateCheckInRequestBody(body createCheckInRequestBody) {
+	v.check(body.Credential != "", "credential", "must be provided")
+	if body.EventID != "" {
+		v.check(tixer.ValidatePublicID(body.EventID) == nil, "eventID", "must be a valid event id")
+	}
+}
+
+// validateCreateUserRequestBody validates the create user request body.
+func (v *validator) validateCreateUserRequestBody(body createUserRequestBody) {
+	v.check(body.Name != "", "name", "must be provided")
+	v.check(body.Email != "", "email", "must be provided")
+	v.check(body.Password != "", "password", "must be provided")
+	v.check(len(body.Password) >= 8, "password", "must be at least 8 bytes long")
+	v.check(len(body.Password) <= 72, "password", "must not be more than 72 bytes long")
+}
+
+// validateSigninUserRequestBody validates the sign in user request body.
+func (v *validator) validateSigninUserRequestBody(body signinUserRequestBody) {
+	v.check(body.Email != "", "email", "must be provided")
+	v.check(body.Password != "", "password", "must be provided")
+}
+
+// validateVenueUrlValues validates the url query string parameters used for reading multiple rows of venues.
+func (v *validator) validateVenueUrlValues(qs url.Values) venueUrlQs {
+	name := v.readString(qs, "name", "")
+	page := v.readInt(qs, "page", 1)
+	pageSize := v.readInt(qs, "pageSize", 10)
+
+	v.check(page >= 1, "page", "must be greater than 0")
+	v.check(page <= 1000, "page", "must be a maximum of 1000")
+	v.check(pageSize >= 1, "pageSize", "must be greater than 0")
+	v.check(pageSize <= 25, "pageSize", "must be a maximum of 25")
+
+	return venueUrlQs{
+		name:     name,
+		page:     page,
+		pageSize: pageSize,
+	}
+}
+
+// validateCreateEventRequestBody validates the create event request body.
+func (v *validator) validateCreateEventRequestBody(body createEventRequestBody) {
+	if body.VenueID != "" {
+		v.check(tixer.ValidatePublicID(body.VenueID) == nil, "venueID", "must be a valid venue id")
+	}
+}
+
+// validateUpdateEventRequestBody validates the update event request body.
+func (v *validator) validateUpdateEventRequestBody(body updateEventRequestBody) {
+	if body.VenueID != nil && *body.VenueID != "" {
+		v.check(tixer.ValidatePublicID(*body.VenueID) == nil, "venueID", "must be a valid venue id")
+	}
+}
+
+// validateEventUrlValues validates the url query string parameters used for reading multiple rows of events.
+func (v *validator) validateEventUrlValues(qs url.Values, sortSafeList []string) eventUrlQs {
+	name := v.readString(qs, "name", "")
+	venue := v.readString(qs, "venue", "")
+	page := v.readInt(qs, "page", 1)
+	pageSize := v.readInt(qs, "pageSize", 10)
+	sort := v.readString(qs, "sort", "starts_at")
+
+	v.check(page >= 1, "page", "must be greater than 0")
+	v.check(page <= 1000, "page", "must be a maximum of 1000")
+	v.check(pageSize >= 1, "pageSize", "must be greater than 0")
+	v.check(pageSize <= 25, "pageSize", "must be a maximum of 25")
+
+	v.check(permittedValue(sort, sortSafeList...), "sort", "invalid sort value")
+
+	if venue != "" {
+		v.check(tixer.ValidatePublicID(venue) == nil, "venue", "must be a valid venue id")
+	}
+
+	return eventUrlQs{
+		name:     name,
+		venue:    venue,
 		page:     page,
 		pageSize: pageSize,
 		sort:     sort,
 	}
 }
 
+// validateTenantUrlValues validates the url query string parameters used for reading multiple rows of tenants.
+func (v *validator) validateTenantUrlValues(qs url.Values) tenantUrlQs {
+	page := v.readInt(qs, "page", 1)
+	pageSize := v.readInt(qs, "pageSize", 10)
+
+	v.check(page >= 1, "page", "must be greater than 0")
+	v.check(page <= 1000, "page", "must be a maximum of 1000")
+	v.check(pageSize >= 1, "pageSize", "must be greater than 0")
+	v.check(pageSize <= 25, "pageSize", "must be a maximum of 25")
+
+	return tenantUrlQs{
+		page:     page,
+		pageSize: pageSize,
+	}
+}
+
 func permittedValue[T comparable](value T, permittedValues ...T) bool {
 	for _, val := range permittedValues {
 		if val == value {
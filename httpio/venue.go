@@ -0,0 +1,283 @@
+package httpio
+
+import (
+	"fmt"
+	"net/http"
+
+	nanoid "github.com/matoous/go-nanoid/v2"
+	tixer "github.com/mroobert/monorepo-tixer"
+	"github.com/mroobert/monorepo-tixer/psql"
+)
+
+// registerVenueRoutes registers the venue resource routes with the server.
+func (s *Server) registerVenueRoutes(r *http.ServeMux) {
+	r.HandleFunc("POST /v1/venues", s.authorize(tixer.PermEventsWrite, s.handleCreateVenue))
+	r.HandleFunc("GET /v1/venues", s.authorize(tixer.PermTicketsRead, s.handleReadVenues))
+	r.HandleFunc("GET /v1/venues/{id}", s.authorize(tixer.PermTicketsRead, s.handleReadVenue))
+	r.HandleFunc("PATCH /v1/venues/{id}", s.authorize(tixer.PermEventsWrite, s.handleUpdateVenue))
+	r.HandleFunc("DELETE /v1/venues/{id}", s.authorize(tixer.PermEventsWrite, s.handleDeleteVenue))
+}
+
+// venueResponseBody represents the expected fields in the response body for a venue resource.
+type venueResponseBody struct {
+	PublicID string `json:"publicID"`
+	Name     string `json:"name"`
+	Address  string `json:"address"`
+	Capacity int32  `json:"capacity"`
+	Version  int32  `json:"version"`
+}
+
+// createVenueRequestBody represents the expected request body for creating a new venue.
+type createVenueRequestBody struct {
+	Name     string `json:"name"`
+	Address  string `json:"address"`
+	Capacity int32  `json:"capacity"`
+}
+
+// handleCreateVenue handles the creation of a new venue.
+func (s *Server) handleCreateVenue(w http.ResponseWriter, r *http.Request) {
+	var body createVenueRequestBody
+
+	err := s.readJSON(w, r, &body)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	user, ok := s.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	publicID, err := nanoid.Generate(tixer.PublicIDAlphabet, tixer.PublicIDLength)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+		return
+	}
+
+	venue := tixer.Venue{
+		PublicID: tixer.PublicID(publicID),
+		OwnerID:  &user.ID,
+		Name:     body.Name,
+		Address:  body.Address,
+		Capacity: body.Capacity,
+	}
+	if valid, errs := venue.Validate(); !valid {
+		s.failedValidationResponse(w, r, errs)
+		return
+	}
+
+	venueDB, err := s.VenueRepository.Insert(r.Context(), venue)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+		return
+	}
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("/v1/venues/%s", venueDB.PublicID))
+
+	err = s.writeJSON(w, http.StatusCreated, envelope{"venue": toVenueResponseBody(venueDB)}, headers)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// venueUrlQs represents the expected query string parameters for reading venues.
+type venueUrlQs struct {
+	name     string
+	page     int
+	pageSize int
+}
+
+// handleReadVenues handles reading venues from the system.
+func (s *Server) handleReadVenues(w http.ResponseWriter, r *http.Request) {
+	validator := newValidator()
+	qs := validator.validateVenueUrlValues(r.URL.Query())
+	if !validator.valid() {
+		s.failedValidationResponse(w, r, validator.errors)
+		return
+	}
+
+	paginator := psql.NewPaginator(qs.page, qs.pageSize)
+
+	venuesDB, pagination, err := s.VenueRepository.SelectMultiple(r.Context(), psql.VenueFilter{
+		Name:   qs.name,
+		Limit:  paginator.Limit(),
+		Offset: paginator.Offset(),
+	})
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+		return
+	}
+
+	venues := make([]venueResponseBody, len(venuesDB))
+	for i, venueDB := range venuesDB {
+		venues[i] = toVenueResponseBody(venueDB)
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"venues": venues, "pagination": pagination}, nil)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// handleReadVenue handles reading a single venue from the system.
+func (s *Server) handleReadVenue(w http.ResponseWriter, r *http.Request) {
+	id, err := s.readIDParam(r)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	venueDB, err := s.VenueRepository.SelectOne(r.Context(), id)
+	if err != nil {
+		switch err {
+		case psql.ErrDbRecordNotFound:
+			s.notFoundResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"venue": toVenueResponseBody(venueDB)}, nil)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// updateVenueRequestBody represents the expected request body for updating an existing venue.
+type updateVenueRequestBody struct {
+	Name     *string `json:"name"`
+	Address  *string `json:"address"`
+	Capacity *int32  `json:"capacity"`
+}
+
+// handleUpdateVenue handles updating a venue in the system.
+func (s *Server) handleUpdateVenue(w http.ResponseWriter, r *http.Request) {
+	var body updateVenueRequestBody
+
+	id, err := s.readIDParam(r)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	user, ok := s.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	venueDB, err := s.VenueRepository.SelectOne(r.Context(), id)
+	if err != nil {
+		switch err {
+		case psql.ErrDbRecordNotFound:
+			s.notFoundResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if !venueDB.OwnedBy(user.ID) && !s.can(r, tixer.PermEventsManage) {
+		s.notOwnerResponse(w, r)
+		return
+	}
+
+	err = s.readJSON(w, r, &body)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	if body.Name != nil {
+		venueDB.Name = *body.Name
+	}
+	if body.Address != nil {
+		venueDB.Address = *body.Address
+	}
+	if body.Capacity != nil {
+		venueDB.Capacity = *body.Capacity
+	}
+
+	if valid, errs := venueDB.Validate(); !valid {
+		s.failedValidationResponse(w, r, errs)
+		return
+	}
+
+	err = s.VenueRepository.Update(r.Context(), &venueDB)
+	if err != nil {
+		switch err {
+		case psql.ErrDbEditConflict:
+			s.editConflictResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"venue": toVenueResponseBody(venueDB)}, nil)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// handleDeleteVenue handles the deletion of a venue from the system.
+func (s *Server) handleDeleteVenue(w http.ResponseWriter, r *http.Request) {
+	id, err := s.readIDParam(r)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	user, ok := s.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	venueDB, err := s.VenueRepository.SelectOne(r.Context(), id)
+	if err != nil {
+		switch err {
+		case psql.ErrDbRecordNotFound:
+			s.notFoundResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if !venueDB.OwnedBy(user.ID) && !s.can(r, tixer.PermEventsManage) {
+		s.notOwnerResponse(w, r)
+		return
+	}
+
+	err = s.VenueRepository.Delete(r.Context(), id)
+	if err != nil {
+		switch err {
+		case psql.ErrDbRecordNotFound:
+			s.notFoundResponse(w, r)
+		case psql.ErrDbRecordInUse:
+			s.recordInUseResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"message": "venue succesfully deleted"}, nil)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// toVenueResponseBody converts a venue that was read from DB
+// to a venue that will be sent in the response body.
+func toVenueResponseBody(venueDB tixer.Venue) venueResponseBody {
+	return venueResponseBody{
+		PublicID: string(venueDB.PublicID),
+		Name:     venueDB.Name,
+		Address:  venueDB.Address,
+		Capacity: venueDB.Capacity,
+		Version:  venueDB.Version,
+	}
+}
@@ -0,0 +1,128 @@
+package httpio
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	nanoid "github.com/matoous/go-nanoid/v2"
+	tixer "github.com/mroobert/monorepo-tixer"
+	"github.com/mroobert/monorepo-tixer/psql"
+)
+
+// registerWaitlistRoutes registers the waitlist routes with the server.
+func (s *Server) registerWaitlistRoutes(r *http.ServeMux) {
+	r.HandleFunc("POST /v1/tickets/{id}/waitlist", s.authorize(tixer.PermOrdersWrite, s.forTenant(s.handleJoinWaitlist)))
+	r.HandleFunc("GET /v1/tickets/{id}/waitlist", s.authorize(tixer.PermOrdersWrite, s.forTenant(s.handleReadWaitlistEntry)))
+}
+
+// waitlistEntryResponseBody represents the expected fields in the response body for a waitlist entry.
+// Once offered, the user can order the held unit until the claim expires.
+type waitlistEntryResponseBody struct {
+	PublicID       string     `json:"publicID"`
+	TicketID       string     `json:"ticketID"`
+	Status         string     `json:"status"`
+	Position       *int32     `json:"position,omitempty"`
+	ClaimExpiresAt *time.Time `json:"claimExpiresAt,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+}
+
+// handleJoinWaitlist handles adding the current user to the waitlist of a sold out ticket.
+func (s *Server) handleJoinWaitlist(w http.ResponseWriter, r *http.Request) {
+	id, err := s.readIDParam(r)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	user, ok := s.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	publicID, err := nanoid.Generate(tixer.PublicIDAlphabet, tixer.PublicIDLength)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+		return
+	}
+
+	entryDB, err := s.WaitlistRepository.Insert(r.Context(), tixer.WaitlistEntry{
+		PublicID:       tixer.PublicID(publicID),
+		TicketPublicID: id,
+		UserID:         user.ID,
+	})
+	if err != nil {
+		switch err {
+		case psql.ErrDbTenantRequired:
+			s.tenantRequiredResponse(w, r)
+		case psql.ErrDbRecordNotFound:
+			s.notFoundResponse(w, r)
+		case psql.ErrDbTicketNotPublished:
+			s.ticketNotPublishedResponse(w, r)
+		case psql.ErrDbTicketOnSale:
+			s.ticketOnSaleResponse(w, r)
+		case psql.ErrDbAlreadyWaiting:
+			s.alreadyWaitingResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("/v1/tickets/%s/waitlist", entryDB.TicketPublicID))
+
+	err = s.writeJSON(w, http.StatusCreated, envelope{"waitlistEntry": toWaitlistEntryResponseBody(entryDB)}, headers)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// handleReadWaitlistEntry handles reading the latest waitlist entry of the current user for a ticket.
+func (s *Server) handleReadWaitlistEntry(w http.ResponseWriter, r *http.Request) {
+	id, err := s.readIDParam(r)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	user, ok := s.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	entryDB, err := s.WaitlistRepository.SelectOne(r.Context(), id, user.ID)
+	if err != nil {
+		switch err {
+		case psql.ErrDbTenantRequired:
+			s.tenantRequiredResponse(w, r)
+		case psql.ErrDbRecordNotFound:
+			s.notFoundResponse(w, r)
+		default:
+			s.internalServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"waitlistEntry": toWaitlistEntryResponseBody(entryDB)}, nil)
+	if err != nil {
+		s.internalServerErrorResponse(w, r, err)
+	}
+}
+
+// toWaitlistEntryResponseBody converts a waitlist entry that was read from DB
+// to a waitlist entry that will be sent in the response body.
+func toWaitlistEntryResponseBody(entryDB tixer.WaitlistEntry) waitlistEntryResponseBody {
+	body := waitlistEntryResponseBody{
+		PublicID:       string(entryDB.PublicID),
+		TicketID:       string(entryDB.TicketPublicID),
+		Status:         string(entryDB.Status),
+		ClaimExpiresAt: entryDB.ClaimExpiresAt,
+		CreatedAt:      entryDB.CreatedAt,
+	}
+	if entryDB.Status == tixer.WaitlistWaiting {
+		body.Position = &entryDB.Position
+	}
+
+	return body
+}
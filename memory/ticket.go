@@ -0,0 +1,343 @@
+// This package provides a ticket store that keeps the tickets in the process only, for demos and tests.
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	tixer "github.com/mroobert/monorepo-tixer"
+)
+
+// TicketStore is an in-process ticket store that never talks to the database, which allows
+// the ticket routes to be exercised in demos and tests. It follows the semantics of the psql store:
+// the tickets are scoped by tenant, the versions guard against lost updates and the errors are the same.
+// The store knows nothing of events, seats and orders, so the public IDs of the event and the seat
+// are kept as given, no unit is ever sold or held and a ticket can always be deleted.
+type TicketStore struct {
+	mu      sync.RWMutex
+	tickets map[tixer.PublicID]storedTicket
+	lastID  int64 // the last ID given to a ticket or a price tier
+}
+
+// storedTicket is a ticket kept by the TicketStore, with the tenant it belongs to.
+type storedTicket struct {
+	tenantID int64
+	ticket   tixer.Ticket
+}
+
+// NewTicketStore creates an empty ticket store.
+func NewTicketStore() *TicketStore {
+	return &TicketStore{
+		tickets: make(map[tixer.PublicID]storedTicket),
+	}
+}
+
+// Insert stores a new ticket with its price tiers and pricing rules.
+// It returns tixer.ErrSeatTaken if another ticket was already issued for the seat at the same event.
+func (ms *TicketStore) Insert(ctx context.Context, ticket tixer.Ticket) (tixer.Ticket, error) {
+	tenantID, ok := tixer.TenantFromContext(ctx)
+	if !ok {
+		return tixer.Ticket{}, tixer.ErrTenantRequired
+	}
+
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	// Like the unique constraint of the table, the seat is taken across tenants.
+	if ticket.EventID != nil && ticket.SeatID != nil {
+		for _, stored := range ms.tickets {
+			if stored.ticket.EventID != nil && *stored.ticket.EventID == *ticket.EventID &&
+				stored.ticket.SeatID != nil && *stored.ticket.SeatID == *ticket.SeatID {
+				return tixer.Ticket{}, tixer.ErrSeatTaken
+			}
+		}
+	}
+
+	now := time.Now().Truncate(time.Second)
+
+	ms.lastID++
+	ticket.ID = ms.lastID
+	ticket.Sold, ticket.Held, ticket.Pending = 0, 0, 0
+	ticket.Version = 1
+	ticket.CreatedAt = now
+	ticket.UpdatedAt = now
+	ticket.PricingRules = sortPricingRules(ticket.PricingRules)
+	ticket.PriceTiers = ms.replacePriceTiers(nil, ticket.PriceTiers)
+
+	ms.tickets[ticket.PublicID] = storedTicket{tenantID: tenantID, ticket: copyTicket(ticket)}
+
+	ticket.SettleStatus()
+	return ticket, nil
+}
+
+// SelectOne reads a ticket of the tenant.
+func (ms *TicketStore) SelectOne(ctx context.Context, id tixer.PublicID) (tixer.Ticket, error) {
+	tenantID, ok := tixer.TenantFromContext(ctx)
+	if !ok {
+		return tixer.Ticket{}, tixer.ErrTenantRequired
+	}
+
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	stored, ok := ms.tickets[id]
+	if !ok || stored.tenantID != tenantID {
+		return tixer.Ticket{}, tixer.ErrNotFound
+	}
+
+	ticket := copyTicket(stored.ticket)
+	ticket.SettleStatus()
+	return ticket, nil
+}
+
+// SelectMultiple reads the tickets of the tenant based on filters. Like the full text search of
+// the database, a ticket matches the title filter if its title contains every word of the filter,
+// in any letter case.
+func (ms *TicketStore) SelectMultiple(ctx context.Context, filter tixer.TicketFilter) ([]tixer.Ticket, tixer.Pagination, error) {
+	tenantID, ok := tixer.TenantFromContext(ctx)
+	if !ok {
+		return nil, tixer.Pagination{}, tixer.ErrTenantRequired
+	}
+
+	searched := titleWords(filter.Title)
+
+	ms.mu.RLock()
+	matching := []tixer.Ticket{}
+	for _, stored := range ms.tickets {
+		ticket := stored.ticket
+		switch {
+		case stored.tenantID != tenantID:
+			continue
+		case filter.EventID != nil && (ticket.EventPublicID == nil || *ticket.EventPublicID != *filter.EventID):
+			continue
+		case filter.Title != "" && !containsWords(titleWords(ticket.Title), searched):
+			continue
+		}
+		matching = append(matching, copyTicket(ticket))
+	}
+	ms.mu.RUnlock()
+
+	// Like the database, the titles are sorted by byte order and the ties are broken by ascending ID.
+	slices.SortFunc(matching, func(a, b tixer.Ticket) int {
+		var c int
+		switch filter.SortColumn {
+		case "title":
+			c = strings.Compare(a.Title, b.Title)
+		case "price":
+			c = cmp.Compare(a.Price.Amount, b.Price.Amount)
+		default:
+			c = cmp.Compare(a.ID, b.ID)
+		}
+		if filter.SortDirection == "DESC" {
+			c = -c
+		}
+		if c == 0 {
+			c = cmp.Compare(a.ID, b.ID)
+		}
+		return c
+	})
+
+	totalRecords := len(matching)
+	tickets := matching[min(filter.Offset, totalRecords):min(filter.Offset+filter.Limit, totalRecords)]
+	for i := range tickets {
+		tickets[i].SettleStatus()
+	}
+
+	// Like the database count, the total is only known from the rows of the page.
+	if len(tickets) == 0 {
+		totalRecords = 0
+	}
+
+	return tickets, tixer.NewPagination(totalRecords, filter.Offset/filter.Limit+1, filter.Limit), nil
+}
+
+// Update stores the changes of a ticket and replaces its price tiers and pricing rules.
+// The status and units are read back, the status being settled against them.
+// It returns tixer.ErrEditConflict if the ticket was changed in the meantime.
+func (ms *TicketStore) Update(ctx context.Context, ticket *tixer.Ticket) error {
+	tenantID, ok := tixer.TenantFromContext(ctx)
+	if !ok {
+		return tixer.ErrTenantRequired
+	}
+
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	stored, ok := ms.tickets[ticket.PublicID]
+	if !ok || stored.tenantID != tenantID || stored.ticket.Version != ticket.Version {
+		return tixer.ErrEditConflict
+	}
+	current := stored.ticket
+
+	// units were sold or held since the ticket was read, leaving the new capacity below them
+	if ticket.Capacity < current.Sold+current.Held {
+		return tixer.ErrEditConflict
+	}
+
+	// units were sold at a tier since the ticket was read, so orders point to it and it cannot be removed
+	if tixer.CheckRemovedPriceTiers(current.PriceTiers, ticket.PriceTiers) != "" {
+		return tixer.ErrEditConflict
+	}
+
+	tiers := ms.replacePriceTiers(current.PriceTiers, ticket.PriceTiers)
+	for _, tier := range tiers {
+		if tier.Quantity != nil && *tier.Quantity < tier.Sold {
+			return tixer.ErrEditConflict
+		}
+	}
+
+	current.Title = ticket.Title
+	current.Price = ticket.Price
+	current.RefundDeadline = ticket.RefundDeadline
+	current.RefundPercentage = ticket.RefundPercentage
+	current.Capacity = ticket.Capacity
+	current.PricingRules = sortPricingRules(ticket.PricingRules)
+	current.PriceTiers = tiers
+	current.Version++
+	current.UpdatedAt = time.Now().Truncate(time.Second)
+
+	ms.tickets[current.PublicID] = storedTicket{tenantID: tenantID, ticket: copyTicket(current)}
+
+	ticket.Status = current.Status
+	ticket.Sold = current.Sold
+	ticket.Held = current.Held
+	ticket.Pending = current.Pending
+	ticket.Version = current.Version
+	ticket.PricingRules = current.PricingRules
+	ticket.PriceTiers = current.PriceTiers
+	ticket.SettleStatus()
+
+	return nil
+}
+
+// UpdateStatus stores the status the ticket was moved to by its owner. The reserved and sold
+// states are not stored, they are the published state settled against the units taken.
+// It returns tixer.ErrEditConflict if the ticket was changed in the meantime.
+func (ms *TicketStore) UpdateStatus(ctx context.Context, ticket *tixer.Ticket) error {
+	tenantID, ok := tixer.TenantFromContext(ctx)
+	if !ok {
+		return tixer.ErrTenantRequired
+	}
+
+	status := ticket.Status
+	if status.Published() {
+		status = tixer.TicketPublished
+	}
+
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	stored, ok := ms.tickets[ticket.PublicID]
+	if !ok || stored.tenantID != tenantID || stored.ticket.ID != ticket.ID || stored.ticket.Version != ticket.Version {
+		return tixer.ErrEditConflict
+	}
+
+	stored.ticket.Status = status
+	stored.ticket.Version++
+	stored.ticket.UpdatedAt = time.Now().Truncate(time.Second)
+	ms.tickets[ticket.PublicID] = stored
+
+	ticket.Version = stored.ticket.Version
+	ticket.UpdatedAt = stored.ticket.UpdatedAt
+	ticket.SettleStatus()
+
+	return nil
+}
+
+// Delete removes a ticket of the tenant.
+func (ms *TicketStore) Delete(ctx context.Context, id tixer.PublicID) error {
+	tenantID, ok := tixer.TenantFromContext(ctx)
+	if !ok {
+		return tixer.ErrTenantRequired
+	}
+
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	stored, ok := ms.tickets[id]
+	if !ok || stored.tenantID != tenantID {
+		return tixer.ErrNotFound
+	}
+
+	delete(ms.tickets, id)
+	return nil
+}
+
+// replacePriceTiers returns the new price tiers of a ticket ordered by start. Like the psql store, tiers are matched by name, so a tier that is kept keeps its ID and the count
+// of the units sold at its price. It must be called with the lock held.
+func (ms *TicketStore) replacePriceTiers(current, tiers []tixer.PriceTier) []tixer.PriceTier {
+	replaced := make([]tixer.PriceTier, len(tiers))
+	for i, tier := range tiers {
+		j := slices.IndexFunc(current, func(c tixer.PriceTier) bool { return c.Name == tier.Name })
+		if j >= 0 {
+			tier.ID = current[j].ID
+			tier.Sold = current[j].Sold
+		} else {
+			ms.lastID++
+			tier.ID = ms.lastID
+			tier.Sold = 0
+		}
+		replaced[i] = tier
+	}
+
+	slices.SortStableFunc(replaced, func(a, b tixer.PriceTier) int {
+		return a.StartsAt.Compare(b.StartsAt)
+	})
+
+	return replaced
+}
+
+// sortPricingRules returns a copy of the pricing rules ordered by threshold.
+func sortPricingRules(rules []tixer.PricingRule) []tixer.PricingRule {
+	sorted := make([]tixer.PricingRule, len(rules))
+	copy(sorted, rules)
+
+	slices.SortFunc(sorted, func(a, b tixer.PricingRule) int {
+		return cmp.Compare(a.SoldAbove, b.SoldAbove)
+	})
+
+	return sorted
+}
+
+// copyTicket returns a copy of the ticket that shares no price tiers or pricing rules with it,
+// so the tickets kept by the store cannot be changed by the callers.
+func copyTicket(ticket tixer.Ticket) tixer.Ticket {
+	ticket.PriceTiers = slices.Clone(ticket.PriceTiers)
+	ticket.PricingRules = slices.Clone(ticket.PricingRules)
+	if ticket.PriceTiers == nil {
+		ticket.PriceTiers = []tixer.PriceTier{}
+	}
+	if ticket.PricingRules == nil {
+		ticket.PricingRules = []tixer.PricingRule{}
+	}
+
+	return ticket
+}
+
+// titleWords splits a title into its lower case words, like the simple text search configuration.
+func titleWords(title string) []string {
+	return strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// containsWords reports whether words contains every one of searched.
+// Like an empty text search query, no searched word matches no title.
+func containsWords(words, searched []string) bool {
+	if len(searched) == 0 {
+		return false
+	}
+
+	for _, word := range searched {
+		if !slices.Contains(words, word) {
+			return false
+		}
+	}
+
+	return true
+}
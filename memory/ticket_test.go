@@ -0,0 +1,24 @@
+package memory_test
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/mroobert/monorepo-tixer/memory"
+	"github.com/mroobert/monorepo-tixer/tixertest"
+)
+
+func TestTicketStore(t *testing.T) {
+	// The store knows nothing of tenants, events and seats, any ID will do.
+	var lastID atomic.Int64
+
+	tixertest.TestTicketStore(t, tixertest.TicketStoreFixture{
+		Store: memory.NewTicketStore(),
+		NewTenant: func(t *testing.T) int64 {
+			return lastID.Add(1)
+		},
+		NewSeat: func(t *testing.T) (int64, int64) {
+			return lastID.Add(1), lastID.Add(1)
+		},
+	})
+}
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS orders (
+    id bigserial PRIMARY KEY NOT NULL,
+    public_id char(12) NOT NULL UNIQUE,
+    ticket_id bigint NOT NULL REFERENCES tickets (id) ON DELETE RESTRICT,
+    status text NOT NULL DEFAULT 'pending',
+    version integer NOT NULL DEFAULT 1,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
+
+-- A ticket can be held by at most one active (pending or paid) order at a time.
+CREATE UNIQUE INDEX IF NOT EXISTS orders_active_ticket_id_idx ON orders (ticket_id)
+    WHERE status IN ('pending', 'paid');
@@ -0,0 +1,11 @@
+-- The orders placed before expiry existed get the default expiry window of 15 minutes from their
+-- creation, rather than expiring as soon as the expiry worker first runs.
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS expires_at timestamp(0) with time zone;
+
+UPDATE orders SET expires_at = created_at + interval '15 minutes' WHERE expires_at IS NULL;
+
+ALTER TABLE orders ALTER COLUMN expires_at SET DEFAULT NOW();
+ALTER TABLE orders ALTER COLUMN expires_at SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS orders_pending_expires_at_idx ON orders (expires_at)
+    WHERE status = 'pending';
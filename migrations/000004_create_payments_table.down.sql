@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS payments;
+
+UPDATE orders SET status = 'pending' WHERE status = 'paying';
+
+DROP INDEX IF EXISTS orders_active_ticket_id_idx;
+
+CREATE UNIQUE INDEX IF NOT EXISTS orders_active_ticket_id_idx ON orders (ticket_id)
+    WHERE status IN ('pending', 'paid');
+
+DROP INDEX IF EXISTS orders_pending_expires_at_idx;
+
+CREATE INDEX IF NOT EXISTS orders_pending_expires_at_idx ON orders (expires_at)
+    WHERE status = 'pending';
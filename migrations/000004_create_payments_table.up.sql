@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS payments (
+    id bigserial PRIMARY KEY NOT NULL,
+    public_id char(12) NOT NULL UNIQUE,
+    order_id bigint NOT NULL REFERENCES orders (id) ON DELETE RESTRICT,
+    amount bigint NOT NULL,
+    status text NOT NULL,
+    gateway_reference text NOT NULL DEFAULT '',
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS payments_order_id_idx ON payments (order_id);
+
+-- An order is paying while the payment gateway charges it. It still holds its ticket, and the expiry
+-- worker also expires the paying orders whose expiry time has passed, e.g. when the server stopped during a charge.
+DROP INDEX IF EXISTS orders_active_ticket_id_idx;
+
+CREATE UNIQUE INDEX IF NOT EXISTS orders_active_ticket_id_idx ON orders (ticket_id)
+    WHERE status IN ('pending', 'paying', 'paid');
+
+DROP INDEX IF EXISTS orders_pending_expires_at_idx;
+
+CREATE INDEX IF NOT EXISTS orders_pending_expires_at_idx ON orders (expires_at)
+    WHERE status IN ('pending', 'paying');
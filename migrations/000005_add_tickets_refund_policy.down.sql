@@ -0,0 +1,2 @@
+ALTER TABLE tickets DROP COLUMN IF EXISTS refund_percentage;
+ALTER TABLE tickets DROP COLUMN IF EXISTS refund_deadline;
@@ -0,0 +1,3 @@
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS refund_deadline timestamp(0) with time zone;
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS refund_percentage integer NOT NULL DEFAULT 100
+    CHECK (refund_percentage BETWEEN 0 AND 100);
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS refunds;
+
+UPDATE orders SET status = 'paid' WHERE status = 'refunding';
+
+DROP INDEX IF EXISTS orders_active_ticket_id_idx;
+
+CREATE UNIQUE INDEX IF NOT EXISTS orders_active_ticket_id_idx ON orders (ticket_id)
+    WHERE status IN ('pending', 'paying', 'paid');
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS refunds (
+    id bigserial PRIMARY KEY NOT NULL,
+    public_id char(12) NOT NULL UNIQUE,
+    order_id bigint NOT NULL REFERENCES orders (id) ON DELETE RESTRICT,
+    payment_id bigint NOT NULL REFERENCES payments (id) ON DELETE RESTRICT,
+    amount bigint NOT NULL CHECK (amount >= 0),
+    reason text NOT NULL DEFAULT '',
+    -- pending from the moment it is recorded until the payment gateway gave the money back
+    status text NOT NULL DEFAULT 'pending',
+    gateway_reference text NOT NULL DEFAULT '',
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
+
+-- An order is refunded at most once.
+CREATE UNIQUE INDEX IF NOT EXISTS refunds_order_id_idx ON refunds (order_id);
+
+-- The order of a pending refund is refunding, which keeps it from changing under the refund.
+-- It still holds its ticket until the refund succeeded.
+DROP INDEX IF EXISTS orders_active_ticket_id_idx;
+
+CREATE UNIQUE INDEX IF NOT EXISTS orders_active_ticket_id_idx ON orders (ticket_id)
+    WHERE status IN ('pending', 'paying', 'paid', 'refunding');
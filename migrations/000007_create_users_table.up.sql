@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS users (
+    id bigserial PRIMARY KEY NOT NULL,
+    public_id char(12) NOT NULL UNIQUE,
+    name text NOT NULL,
+    email text NOT NULL,
+    password_hash bytea NOT NULL,
+    version integer NOT NULL DEFAULT 1,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    CONSTRAINT users_email_key UNIQUE (email)
+);
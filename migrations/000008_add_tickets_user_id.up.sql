@@ -0,0 +1,3 @@
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS user_id bigint REFERENCES users (id) ON DELETE RESTRICT;
+
+CREATE INDEX IF NOT EXISTS tickets_user_id_idx ON tickets (user_id);
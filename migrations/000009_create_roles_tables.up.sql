@@ -0,0 +1,53 @@
+CREATE TABLE IF NOT EXISTS roles (
+    id bigserial PRIMARY KEY NOT NULL,
+    name text NOT NULL UNIQUE,
+    description text NOT NULL DEFAULT '',
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS permissions (
+    id bigserial PRIMARY KEY NOT NULL,
+    code text NOT NULL UNIQUE,
+    description text NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role_id bigint NOT NULL REFERENCES roles (id) ON DELETE CASCADE,
+    permission_id bigint NOT NULL REFERENCES permissions (id) ON DELETE CASCADE,
+    PRIMARY KEY (role_id, permission_id)
+);
+
+CREATE TABLE IF NOT EXISTS user_roles (
+    user_id bigint NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role_id bigint NOT NULL REFERENCES roles (id) ON DELETE CASCADE,
+    PRIMARY KEY (user_id, role_id)
+);
+
+INSERT INTO permissions (code, description) VALUES
+    ('tickets:read', 'Read tickets'),
+    ('tickets:write', 'Create tickets and update your own tickets'),
+    ('tickets:delete', 'Delete your own tickets'),
+    ('tickets:manage', 'Update and delete tickets of other users'),
+    ('orders:write', 'Place, pay, read and cancel your own orders'),
+    ('orders:manage', 'Read and cancel orders of other users'),
+    ('roles:manage', 'Manage roles and grant them to users')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO roles (name, description) VALUES
+    ('admin', 'Full access to the system'),
+    ('seller', 'Puts tickets on sale'),
+    ('buyer', 'Buys tickets, granted to every new user'),
+    ('guest', 'Permissions of anonymous callers')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON
+    r.name = 'admin'
+    OR (r.name = 'seller' AND p.code IN ('tickets:read', 'tickets:write', 'tickets:delete', 'orders:write'))
+    OR (r.name = 'buyer' AND p.code IN ('tickets:read', 'orders:write'))
+    OR (r.name = 'guest' AND p.code IN ('tickets:read'))
+ON CONFLICT DO NOTHING;
+
+-- The first administrator has to be granted by hand:
+-- INSERT INTO user_roles (user_id, role_id)
+-- SELECT u.id, r.id FROM users u, roles r WHERE u.email = '<email>' AND r.name = 'admin';
@@ -0,0 +1,3 @@
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS user_id bigint REFERENCES users (id) ON DELETE RESTRICT;
+
+CREATE INDEX IF NOT EXISTS orders_user_id_idx ON orders (user_id);
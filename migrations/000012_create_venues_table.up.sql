@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS venues (
+    id bigserial PRIMARY KEY NOT NULL,
+    public_id char(12) NOT NULL UNIQUE,
+    user_id bigint REFERENCES users (id) ON DELETE RESTRICT,
+    name text NOT NULL,
+    address text NOT NULL,
+    capacity integer NOT NULL CONSTRAINT venues_capacity_check CHECK (capacity > 0),
+    version integer NOT NULL DEFAULT 1,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS venues_user_id_idx ON venues (user_id);
@@ -0,0 +1,3 @@
+DELETE FROM permissions WHERE code IN ('events:write', 'events:manage');
+
+DROP TABLE IF EXISTS events;
@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS events (
+    id bigserial PRIMARY KEY NOT NULL,
+    public_id char(12) NOT NULL UNIQUE,
+    user_id bigint REFERENCES users (id) ON DELETE RESTRICT,
+    venue_id bigint REFERENCES venues (id) ON DELETE RESTRICT,
+    name text NOT NULL,
+    starts_at timestamp(0) with time zone NOT NULL,
+    ends_at timestamp(0) with time zone NOT NULL,
+    time_zone text NOT NULL,
+    version integer NOT NULL DEFAULT 1,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    CONSTRAINT events_time_range_check CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS events_user_id_idx ON events (user_id);
+CREATE INDEX IF NOT EXISTS events_venue_id_idx ON events (venue_id);
+CREATE INDEX IF NOT EXISTS events_starts_at_idx ON events (starts_at);
+
+INSERT INTO permissions (code, description) VALUES
+    ('events:write', 'Create events and venues and change your own'),
+    ('events:manage', 'Change and delete events and venues of other users')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON
+    (r.name = 'admin' AND p.code IN ('events:write', 'events:manage'))
+    OR (r.name = 'seller' AND p.code IN ('events:write'))
+ON CONFLICT DO NOTHING;
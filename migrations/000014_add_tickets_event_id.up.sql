@@ -0,0 +1,3 @@
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS event_id bigint REFERENCES events (id) ON DELETE RESTRICT;
+
+CREATE INDEX IF NOT EXISTS tickets_event_id_idx ON tickets (event_id);
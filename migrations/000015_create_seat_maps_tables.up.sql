@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS sections (
+    id bigserial PRIMARY KEY NOT NULL,
+    venue_id bigint NOT NULL REFERENCES venues (id) ON DELETE CASCADE,
+    name text NOT NULL,
+    position integer NOT NULL,
+    CONSTRAINT sections_venue_id_name_key UNIQUE (venue_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS seat_rows (
+    id bigserial PRIMARY KEY NOT NULL,
+    section_id bigint NOT NULL REFERENCES sections (id) ON DELETE CASCADE,
+    label text NOT NULL,
+    position integer NOT NULL,
+    CONSTRAINT seat_rows_section_id_label_key UNIQUE (section_id, label)
+);
+
+CREATE TABLE IF NOT EXISTS seats (
+    id bigserial PRIMARY KEY NOT NULL,
+    public_id char(12) NOT NULL UNIQUE,
+    row_id bigint NOT NULL REFERENCES seat_rows (id) ON DELETE CASCADE,
+    number integer NOT NULL CONSTRAINT seats_number_check CHECK (number > 0),
+    category text NOT NULL,
+    CONSTRAINT seats_row_id_number_key UNIQUE (row_id, number)
+);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS tickets_event_id_seat_id_key;
+ALTER TABLE tickets DROP CONSTRAINT IF EXISTS tickets_seat_requires_event_check;
+ALTER TABLE tickets DROP COLUMN IF EXISTS seat_id;
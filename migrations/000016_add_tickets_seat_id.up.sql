@@ -0,0 +1,6 @@
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS seat_id bigint REFERENCES seats (id) ON DELETE RESTRICT;
+
+-- A seat can only be sold for an event through a single ticket, and a seated ticket admits
+-- a single buyer, which guarantees at most one sold ticket per seat per event.
+ALTER TABLE tickets ADD CONSTRAINT tickets_seat_requires_event_check CHECK (seat_id IS NULL OR event_id IS NOT NULL);
+CREATE UNIQUE INDEX IF NOT EXISTS tickets_event_id_seat_id_key ON tickets (event_id, seat_id) WHERE seat_id IS NOT NULL;
@@ -0,0 +1,4 @@
+ALTER TABLE refunds DROP COLUMN IF EXISTS currency;
+ALTER TABLE payments DROP COLUMN IF EXISTS currency;
+ALTER TABLE tickets DROP COLUMN IF EXISTS currency;
+ALTER TABLE tickets ALTER COLUMN price TYPE integer;
@@ -0,0 +1,10 @@
+-- Amounts recorded before currencies were supported are euro cents.
+ALTER TABLE tickets ALTER COLUMN price TYPE bigint;
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS currency char(3) NOT NULL DEFAULT 'EUR';
+ALTER TABLE tickets ALTER COLUMN currency DROP DEFAULT;
+
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS currency char(3) NOT NULL DEFAULT 'EUR';
+ALTER TABLE payments ALTER COLUMN currency DROP DEFAULT;
+
+ALTER TABLE refunds ADD COLUMN IF NOT EXISTS currency char(3) NOT NULL DEFAULT 'EUR';
+ALTER TABLE refunds ALTER COLUMN currency DROP DEFAULT;
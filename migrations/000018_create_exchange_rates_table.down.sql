@@ -0,0 +1,3 @@
+DELETE FROM permissions WHERE code = 'exchangerates:manage';
+
+DROP TABLE IF EXISTS exchange_rates;
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    base char(3) NOT NULL,
+    quote char(3) NOT NULL,
+    rate numeric(20, 10) NOT NULL,
+    valid_at timestamp(0) with time zone NOT NULL,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (base, quote, valid_at),
+    CONSTRAINT exchange_rates_rate_check CHECK (rate > 0),
+    CONSTRAINT exchange_rates_pair_check CHECK (base <> quote)
+);
+
+INSERT INTO permissions (code, description) VALUES
+    ('exchangerates:manage', 'Load the exchange rates used to convert prices')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON
+    p.code = 'exchangerates:manage' AND r.name = 'admin'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,9 @@
+-- Fails if a ticket is held by more than one active order.
+CREATE UNIQUE INDEX IF NOT EXISTS orders_active_ticket_id_idx ON orders (ticket_id)
+    WHERE status IN ('pending', 'paying', 'paid', 'refunding');
+
+ALTER TABLE tickets DROP CONSTRAINT IF EXISTS tickets_seat_capacity_check;
+ALTER TABLE tickets DROP CONSTRAINT IF EXISTS tickets_sold_check;
+ALTER TABLE tickets DROP CONSTRAINT IF EXISTS tickets_capacity_check;
+ALTER TABLE tickets DROP COLUMN IF EXISTS sold;
+ALTER TABLE tickets DROP COLUMN IF EXISTS capacity;
@@ -0,0 +1,15 @@
+-- A ticket is now a ticket type that can be sold up to its capacity. The sold count
+-- holds the units taken by the active orders and replaces the single active order per ticket.
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS capacity integer NOT NULL DEFAULT 1;
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS sold integer NOT NULL DEFAULT 0;
+
+UPDATE tickets t SET sold = (
+    SELECT count(*) FROM orders o WHERE o.ticket_id = t.id AND o.status IN ('pending', 'paying', 'paid', 'refunding')
+);
+
+ALTER TABLE tickets ADD CONSTRAINT tickets_capacity_check CHECK (capacity > 0);
+ALTER TABLE tickets ADD CONSTRAINT tickets_sold_check CHECK (sold >= 0 AND sold <= capacity);
+-- A seated ticket still admits a single buyer.
+ALTER TABLE tickets ADD CONSTRAINT tickets_seat_capacity_check CHECK (seat_id IS NULL OR capacity = 1);
+
+DROP INDEX IF EXISTS orders_active_ticket_id_idx;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS waitlist_entries;
+
+ALTER TABLE tickets DROP CONSTRAINT IF EXISTS tickets_held_check;
+ALTER TABLE tickets DROP COLUMN IF EXISTS held;
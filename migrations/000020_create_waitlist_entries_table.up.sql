@@ -0,0 +1,22 @@
+-- Units released while buyers are waiting are held for them instead of going back on sale.
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS held integer NOT NULL DEFAULT 0;
+ALTER TABLE tickets ADD CONSTRAINT tickets_held_check CHECK (held >= 0 AND sold + held <= capacity);
+
+CREATE TABLE IF NOT EXISTS waitlist_entries (
+    id bigserial PRIMARY KEY NOT NULL,
+    public_id char(12) NOT NULL UNIQUE,
+    ticket_id bigint NOT NULL REFERENCES tickets (id) ON DELETE CASCADE,
+    user_id bigint NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    status text NOT NULL DEFAULT 'waiting',
+    claim_expires_at timestamp(0) with time zone,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
+
+-- A user can wait at most once at a time for the same ticket.
+CREATE UNIQUE INDEX IF NOT EXISTS waitlist_entries_active_ticket_id_user_id_idx ON waitlist_entries (ticket_id, user_id)
+    WHERE status IN ('waiting', 'offered');
+CREATE INDEX IF NOT EXISTS waitlist_entries_waiting_ticket_id_idx ON waitlist_entries (ticket_id, id)
+    WHERE status = 'waiting';
+CREATE INDEX IF NOT EXISTS waitlist_entries_offered_claim_expires_at_idx ON waitlist_entries (claim_expires_at)
+    WHERE status = 'offered';
@@ -0,0 +1,10 @@
+DELETE FROM permissions WHERE code = 'promocodes:manage';
+
+DROP INDEX IF EXISTS orders_promo_code_id_user_id_idx;
+ALTER TABLE orders DROP COLUMN IF EXISTS currency;
+ALTER TABLE orders DROP COLUMN IF EXISTS total;
+ALTER TABLE orders DROP COLUMN IF EXISTS discount;
+ALTER TABLE orders DROP COLUMN IF EXISTS subtotal;
+ALTER TABLE orders DROP COLUMN IF EXISTS promo_code_id;
+
+DROP TABLE IF EXISTS promo_codes;
@@ -0,0 +1,47 @@
+CREATE TABLE IF NOT EXISTS promo_codes (
+    id bigserial PRIMARY KEY NOT NULL,
+    public_id char(12) NOT NULL UNIQUE,
+    code text NOT NULL UNIQUE,
+    discount_type text NOT NULL,
+    percent_off integer NOT NULL DEFAULT 0,
+    amount_off bigint NOT NULL DEFAULT 0,
+    currency char(3),
+    scope text NOT NULL,
+    event_id bigint REFERENCES events (id) ON DELETE CASCADE,
+    ticket_id bigint REFERENCES tickets (id) ON DELETE CASCADE,
+    valid_from timestamp(0) with time zone,
+    valid_until timestamp(0) with time zone,
+    max_redemptions integer,
+    max_redemptions_per_user integer,
+    redemptions integer NOT NULL DEFAULT 0,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    -- The guarded increment at checkout relies on this check to never overuse a capped code.
+    CONSTRAINT promo_codes_redemptions_check CHECK (redemptions >= 0 AND (max_redemptions IS NULL OR redemptions <= max_redemptions))
+);
+
+-- Orders keep the price they were placed at, so later price changes and promo codes
+-- do not change what the buyer is charged. Existing orders are priced at the current ticket price.
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS promo_code_id bigint REFERENCES promo_codes (id) ON DELETE RESTRICT;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS subtotal bigint;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS discount bigint NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS total bigint;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS currency char(3);
+
+UPDATE orders o SET subtotal = t.price, total = t.price, currency = t.currency
+FROM tickets t WHERE t.id = o.ticket_id;
+
+ALTER TABLE orders ALTER COLUMN subtotal SET NOT NULL;
+ALTER TABLE orders ALTER COLUMN total SET NOT NULL;
+ALTER TABLE orders ALTER COLUMN currency SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS orders_promo_code_id_user_id_idx ON orders (promo_code_id, user_id)
+    WHERE promo_code_id IS NOT NULL;
+
+INSERT INTO permissions (code, description) VALUES
+    ('promocodes:manage', 'Create, read and delete promo codes')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON
+    p.code = 'promocodes:manage' AND r.name = 'admin'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,10 @@
+-- A ticket's price changes once more than sold_above percent of its capacity is sold or held.
+-- Only the rule with the highest exceeded threshold applies.
+CREATE TABLE IF NOT EXISTS ticket_pricing_rules (
+    ticket_id bigint NOT NULL REFERENCES tickets (id) ON DELETE CASCADE,
+    sold_above integer NOT NULL,
+    adjust_percent integer NOT NULL,
+    PRIMARY KEY (ticket_id, sold_above),
+    CONSTRAINT ticket_pricing_rules_sold_above_check CHECK (sold_above BETWEEN 0 AND 99),
+    CONSTRAINT ticket_pricing_rules_adjust_percent_check CHECK (adjust_percent BETWEEN -99 AND 1000 AND adjust_percent <> 0)
+);
@@ -0,0 +1,3 @@
+ALTER TABLE orders DROP COLUMN IF EXISTS price_tier_id;
+
+DROP TABLE IF EXISTS ticket_price_tiers;
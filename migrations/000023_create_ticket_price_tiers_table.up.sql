@@ -0,0 +1,19 @@
+-- A ticket is sold at the price of its current tier, e.g. early bird, regular or door.
+-- The sold count of a capped tier is incremented by the orders placed at its price and never given back.
+CREATE TABLE IF NOT EXISTS ticket_price_tiers (
+    id bigserial PRIMARY KEY NOT NULL,
+    ticket_id bigint NOT NULL REFERENCES tickets (id) ON DELETE CASCADE,
+    name text NOT NULL,
+    price bigint NOT NULL,
+    starts_at timestamp(0) with time zone NOT NULL,
+    quantity integer,
+    sold integer NOT NULL DEFAULT 0,
+    CONSTRAINT ticket_price_tiers_ticket_id_name_key UNIQUE (ticket_id, name),
+    CONSTRAINT ticket_price_tiers_price_check CHECK (price > 0),
+    -- The guarded increment at checkout relies on this check to never oversell a capped tier.
+    CONSTRAINT ticket_price_tiers_sold_check CHECK (sold >= 0 AND (quantity IS NULL OR sold <= quantity))
+);
+
+CREATE INDEX IF NOT EXISTS ticket_price_tiers_ticket_id_starts_at_idx ON ticket_price_tiers (ticket_id, starts_at);
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS price_tier_id bigint REFERENCES ticket_price_tiers (id) ON DELETE SET NULL;
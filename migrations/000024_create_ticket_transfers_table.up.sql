@@ -0,0 +1,19 @@
+-- The history of the handovers of tickets between users. A transfer is addressed to an email
+-- rather than to an account, so it can be sent before the recipient signs up; to_user_id is
+-- set to the account that answered it.
+CREATE TABLE IF NOT EXISTS ticket_transfers (
+    id bigserial PRIMARY KEY NOT NULL,
+    public_id char(12) NOT NULL UNIQUE,
+    ticket_id bigint NOT NULL REFERENCES tickets (id) ON DELETE CASCADE,
+    from_user_id bigint NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    to_email text NOT NULL,
+    to_user_id bigint REFERENCES users (id) ON DELETE SET NULL,
+    status text NOT NULL,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    responded_at timestamp(0) with time zone
+);
+
+-- A ticket can only be on its way to one recipient at a time.
+CREATE UNIQUE INDEX IF NOT EXISTS ticket_transfers_pending_ticket_id_idx ON ticket_transfers (ticket_id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS ticket_transfers_from_user_id_idx ON ticket_transfers (from_user_id);
+CREATE INDEX IF NOT EXISTS ticket_transfers_to_email_idx ON ticket_transfers (to_email);
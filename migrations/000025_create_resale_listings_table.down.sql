@@ -0,0 +1,4 @@
+ALTER TABLE orders DROP COLUMN IF EXISTS transferred_at;
+
+DROP TABLE IF EXISTS payouts;
+DROP TABLE IF EXISTS resale_listings;
@@ -0,0 +1,35 @@
+CREATE TABLE IF NOT EXISTS resale_listings (
+    id bigserial PRIMARY KEY NOT NULL,
+    public_id char(12) NOT NULL UNIQUE,
+    order_id bigint NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+    seller_id bigint NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    buyer_id bigint REFERENCES users (id) ON DELETE SET NULL,
+    price bigint NOT NULL,
+    original_price bigint NOT NULL,
+    currency char(3) NOT NULL,
+    status text NOT NULL,
+    gateway_reference text,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    sold_at timestamp(0) with time zone,
+    CONSTRAINT resale_listings_price_check CHECK (price > 0)
+);
+
+-- An order can only be on the market once at a time.
+CREATE UNIQUE INDEX IF NOT EXISTS resale_listings_active_order_id_idx ON resale_listings (order_id) WHERE status = 'active';
+CREATE INDEX IF NOT EXISTS resale_listings_status_idx ON resale_listings (status);
+
+-- The money owed to sellers for their resold tickets, paid out outside of the service.
+CREATE TABLE IF NOT EXISTS payouts (
+    id bigserial PRIMARY KEY NOT NULL,
+    public_id char(12) NOT NULL UNIQUE,
+    listing_id bigint NOT NULL UNIQUE REFERENCES resale_listings (id) ON DELETE RESTRICT,
+    user_id bigint NOT NULL REFERENCES users (id) ON DELETE RESTRICT,
+    amount bigint NOT NULL,
+    currency char(3) NOT NULL,
+    status text NOT NULL,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
+
+-- Set when a paid order changes hands by a resale. The payment of such an order
+-- was made by a previous holder, so the order can no longer be refunded.
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS transferred_at timestamp(0) with time zone;
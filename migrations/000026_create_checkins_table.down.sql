@@ -0,0 +1,3 @@
+DELETE FROM permissions WHERE code = 'checkins:write';
+
+DROP TABLE IF EXISTS checkins;
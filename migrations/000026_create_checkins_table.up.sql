@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS checkins (
+    id bigserial PRIMARY KEY NOT NULL,
+    -- A ticket is admitted once: the unique order makes a second scan fail atomically.
+    order_id bigint NOT NULL UNIQUE REFERENCES orders (id) ON DELETE CASCADE,
+    user_id bigint NOT NULL REFERENCES users (id) ON DELETE RESTRICT,
+    scanned_at timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO permissions (code, description) VALUES
+    ('checkins:write', 'Scan the credentials of ticket holders at the gate')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON
+    p.code = 'checkins:write' AND r.name IN ('admin', 'seller')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,5 @@
+-- Only the states set by the owner are stored. A published ticket is reported as reserved
+-- or sold from its sold, held and pending units. Existing tickets are already on sale.
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS status text NOT NULL DEFAULT 'published';
+ALTER TABLE tickets ALTER COLUMN status SET DEFAULT 'draft';
+ALTER TABLE tickets ADD CONSTRAINT tickets_status_check CHECK (status IN ('draft', 'published', 'cancelled', 'archived'));
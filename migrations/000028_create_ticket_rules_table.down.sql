@@ -0,0 +1,3 @@
+DELETE FROM permissions WHERE code = 'ticketrules:manage';
+
+DROP TABLE IF EXISTS ticket_rules;
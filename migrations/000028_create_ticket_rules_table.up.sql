@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS ticket_rules (
+    -- The ticket rules of an organizer; a NULL column keeps the rule of the deployment.
+    user_id bigint PRIMARY KEY NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    max_title_length integer,
+    min_price bigint,
+    max_prices jsonb,
+    allowed_currencies text[],
+    forbidden_words text[],
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO permissions (code, description) VALUES
+    ('ticketrules:manage', 'Override the ticket rules of organizers')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON
+    p.code = 'ticketrules:manage' AND r.name = 'admin'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,22 @@
+-- The override of an organizer goes back to the first user that works for it.
+ALTER TABLE ticket_rules ADD COLUMN IF NOT EXISTS user_id bigint REFERENCES users (id) ON DELETE CASCADE;
+
+UPDATE ticket_rules tr SET user_id = (SELECT MIN(u.id) FROM users u WHERE u.tenant_id = tr.tenant_id);
+
+DELETE FROM ticket_rules WHERE user_id IS NULL;
+
+ALTER TABLE ticket_rules DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE ticket_rules ALTER COLUMN user_id SET NOT NULL;
+ALTER TABLE ticket_rules ADD PRIMARY KEY (user_id);
+
+DELETE FROM permissions WHERE code = 'tenants:manage';
+
+DROP POLICY IF EXISTS tickets_tenant_bypass ON tickets;
+DROP POLICY IF EXISTS tickets_tenant_isolation ON tickets;
+ALTER TABLE tickets NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE tickets DISABLE ROW LEVEL SECURITY;
+
+ALTER TABLE tickets DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE users DROP COLUMN IF EXISTS tenant_id;
+
+DROP TABLE IF EXISTS tenants;
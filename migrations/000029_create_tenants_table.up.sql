@@ -0,0 +1,65 @@
+CREATE TABLE IF NOT EXISTS tenants (
+    id bigserial PRIMARY KEY NOT NULL,
+    public_id char(12) NOT NULL UNIQUE,
+    name text NOT NULL,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
+
+-- The tickets, and the users that put them on sale, created before tenants existed belong to a default organizer.
+INSERT INTO tenants (public_id, name) VALUES ('default00000', 'Default organizer')
+ON CONFLICT (public_id) DO NOTHING;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS tenant_id bigint REFERENCES tenants (id) ON DELETE SET NULL;
+
+UPDATE users SET tenant_id = (SELECT id FROM tenants WHERE public_id = 'default00000')
+WHERE id IN (SELECT user_id FROM tickets WHERE user_id IS NOT NULL);
+
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS tenant_id bigint REFERENCES tenants (id) ON DELETE RESTRICT;
+
+UPDATE tickets SET tenant_id = (SELECT id FROM tenants WHERE public_id = 'default00000');
+
+ALTER TABLE tickets ALTER COLUMN tenant_id SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS tickets_tenant_id_idx ON tickets (tenant_id);
+
+-- A transaction that sets app.tenant_id, as the ticket repository always does, only sees and
+-- changes the tickets of that tenant, and a transaction that sets no tenant sees no ticket at all.
+-- The work done across tenants, such as the order expiry, must set app.bypass_rls to on instead.
+-- The policies are forced on the table owner as well, but they are bypassed by superusers
+-- and roles with BYPASSRLS, so the application must not use one.
+ALTER TABLE tickets ENABLE ROW LEVEL SECURITY;
+ALTER TABLE tickets FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY tickets_tenant_isolation ON tickets
+    USING (tenant_id = NULLIF(current_setting('app.tenant_id', true), '')::bigint)
+    WITH CHECK (tenant_id = NULLIF(current_setting('app.tenant_id', true), '')::bigint);
+
+CREATE POLICY tickets_tenant_bypass ON tickets
+    USING (current_setting('app.bypass_rls', true) = 'on')
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'on');
+
+-- The ticket rules belong to the organizer, i.e. the tenant, rather than to one of the users that work for it.
+-- An organizer whose users had several overrides keeps the one that was changed last; the overrides of
+-- users that work for no tenant are dropped.
+ALTER TABLE ticket_rules ADD COLUMN IF NOT EXISTS tenant_id bigint REFERENCES tenants (id) ON DELETE CASCADE;
+
+UPDATE ticket_rules tr SET tenant_id = u.tenant_id FROM users u WHERE u.id = tr.user_id;
+
+DELETE FROM ticket_rules WHERE tenant_id IS NULL;
+
+DELETE FROM ticket_rules tr USING ticket_rules newer
+WHERE newer.tenant_id = tr.tenant_id
+    AND (newer.updated_at, newer.user_id) > (tr.updated_at, tr.user_id);
+
+ALTER TABLE ticket_rules DROP COLUMN IF EXISTS user_id;
+ALTER TABLE ticket_rules ALTER COLUMN tenant_id SET NOT NULL;
+ALTER TABLE ticket_rules ADD PRIMARY KEY (tenant_id);
+
+INSERT INTO permissions (code, description) VALUES
+    ('tenants:manage', 'Add organizers and the users that work for them')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON
+    p.code = 'tenants:manage' AND r.name = 'admin'
+ON CONFLICT DO NOTHING;
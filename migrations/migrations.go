@@ -0,0 +1,10 @@
+// The migrations package embeds the SQL migrations of the database schema, so the binary
+// can apply them without the files being shipped next to it.
+package migrations
+
+import "embed"
+
+// Files holds the <version>_<name>.up.sql and <version>_<name>.down.sql migration files.
+//
+//go:embed *.sql
+var Files embed.FS
@@ -0,0 +1,90 @@
+package tixer
+
+import (
+	"fmt"
+)
+
+// Currency represents an ISO 4217 currency code.
+type Currency string
+
+// Supported currencies.
+const (
+	EUR Currency = "EUR"
+	USD Currency = "USD"
+	GBP Currency = "GBP"
+	CHF Currency = "CHF"
+	RON Currency = "RON"
+	JPY Currency = "JPY"
+)
+
+// DefaultCurrency is the currency of the amounts recorded before currencies were supported.
+const DefaultCurrency = EUR
+
+// currencyInfo holds the rules that depend on the currency.
+type currencyInfo struct {
+	minorUnits     int   // the number of decimals of the currency
+	maxTicketPrice int64 // the highest ticket price, in minor units
+}
+
+// currencies lists the supported currencies.
+var currencies = map[Currency]currencyInfo{
+	EUR: {minorUnits: 2, maxTicketPrice: 50_000},
+	USD: {minorUnits: 2, maxTicketPrice: 50_000},
+	GBP: {minorUnits: 2, maxTicketPrice: 50_000},
+	CHF: {minorUnits: 2, maxTicketPrice: 50_000},
+	RON: {minorUnits: 2, maxTicketPrice: 250_000},
+	JPY: {minorUnits: 0, maxTicketPrice: 80_000},
+}
+
+// ValidateCurrency checks if a given value is a supported currency.
+func ValidateCurrency(value string) error {
+	if _, ok := currencies[Currency(value)]; !ok {
+		return fmt.Errorf("unsupported currency %q", value)
+	}
+
+	return nil
+}
+
+// Money represents an amount of money in the minor unit of its currency, e.g. cents for EUR.
+type Money struct {
+	Amount   int64
+	Currency Currency
+}
+
+// MaxTicketPrice returns the highest price a ticket can have in the currency.
+// It returns false if the currency is not supported.
+func MaxTicketPrice(currency Currency) (Money, bool) {
+	info, ok := currencies[currency]
+	if !ok {
+		return Money{}, false
+	}
+
+	return Money{Amount: info.maxTicketPrice, Currency: currency}, true
+}
+
+// Percent returns the given percentage of the money, rounded down to the minor unit.
+func (m Money) Percent(pct int32) Money {
+	return Money{Amount: m.Amount * int64(pct) / 100, Currency: m.Currency}
+}
+
+// String formats the money in major units followed by the currency code, e.g. 12.50 EUR.
+func (m Money) String() string {
+	minorUnits := currencies[m.Currency].minorUnits
+	if minorUnits == 0 {
+		return fmt.Sprintf("%d %s", m.Amount, m.Currency)
+	}
+
+	sign := ""
+	amount := m.Amount
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	scale := int64(1)
+	for range minorUnits {
+		scale *= 10
+	}
+
+	return fmt.Sprintf("%s%d.%0*d %s", sign, amount/scale, minorUnits, amount%scale, m.Currency)
+}
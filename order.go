@@ -0,0 +1,66 @@
+package tixer
+
+import (
+	"time"
+)
+
+// OrderStatus represents the state of an order.
+type OrderStatus string
+
+// Order statuses.
+const (
+	OrderPending   OrderStatus = "pending"
+	OrderPaying    OrderStatus = "paying" // being charged through the payment gateway
+	OrderPaid      OrderStatus = "paid"
+	OrderRefunding OrderStatus = "refunding" // paid, its money is being given back through the payment gateway
+	OrderCancelled OrderStatus = "cancelled"
+	OrderExpired   OrderStatus = "expired"  // cancelled because it was not paid in time
+	OrderRefunded  OrderStatus = "refunded" // cancelled after it was paid
+)
+
+// Order represents the reservation of a ticket by a buyer.
+type Order struct {
+	ID             int64
+	PublicID       PublicID
+	BuyerID        *int64 // the ID of the user that placed the order, nil for orders placed before accounts existed
+	TicketID       int64
+	TicketPublicID PublicID
+	TenantID       int64   // the ID of the tenant the ticket belongs to
+	PromoCodeID    *int64  // the ID of the promo code redeemed at checkout, nil if none was applied
+	PromoCode      *string // the code redeemed at checkout
+	PriceTierID    *int64  // the ID of the price tier the ticket was sold at, nil if no tier was current
+	PriceTier      *string // the name of the price tier
+	Subtotal       Money   // the ticket price at checkout
+	Discount       Money   // the discount given by the promo code
+	Total          Money   // the amount the buyer is charged
+	Status         OrderStatus
+	Version        int32
+	ExpiresAt      time.Time  // the moment a pending order is cancelled if still unpaid
+	TransferredAt  *time.Time // the last moment the paid order changed hands, nil if it never did
+	CheckedInAt    *time.Time // the moment the ticket was scanned at the gate, nil if it was not yet
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// Active reports whether the order still holds its ticket.
+func (o Order) Active() bool {
+	return o.Status == OrderPending || o.Status == OrderPaying || o.Status == OrderPaid || o.Status == OrderRefunding
+}
+
+// Refundable reports whether the payment of a paid order can still be given back.
+// An order that changed hands was paid by a previous holder, so it is not refundable,
+// and neither is a ticket that was already used at the gate.
+func (o Order) Refundable() bool {
+	return o.Status == OrderPaid && o.TransferredAt == nil && o.CheckedInAt == nil
+}
+
+// Transferable reports whether the ticket of the order can be handed over to another user
+// by a resale. Only the tickets of paid orders that were not used at the gate can.
+func (o Order) Transferable() bool {
+	return o.Status == OrderPaid && o.CheckedInAt == nil
+}
+
+// PlacedBy reports whether the order was placed by the user with the given ID.
+func (o Order) PlacedBy(userID int64) bool {
+	return o.BuyerID != nil && *o.BuyerID == userID
+}
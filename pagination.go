@@ -0,0 +1,28 @@
+package tixer
+
+import "math"
+
+// Pagination represents the pagination information of a page of records.
+type Pagination struct {
+	CurrentPage  int `json:"currentPage,omitempty"`
+	PageSize     int `json:"pageSize,omitempty"`
+	FirstPage    int `json:"firstPage,omitempty"`
+	LastPage     int `json:"lastPage,omitempty"`
+	TotalRecords int `json:"totalRecords,omitempty"`
+}
+
+// NewPagination returns the pagination information of a page of records.
+// It is empty if there are no records.
+func NewPagination(totalRecords, page, pageSize int) Pagination {
+	if totalRecords == 0 {
+		return Pagination{}
+	}
+
+	return Pagination{
+		CurrentPage:  page,
+		PageSize:     pageSize,
+		FirstPage:    1,
+		LastPage:     int(math.Ceil(float64(totalRecords) / float64(pageSize))),
+		TotalRecords: totalRecords,
+	}
+}
@@ -0,0 +1,65 @@
+package tixer
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Payment gateway errors.
+var (
+	ErrPaymentDeclined = errors.New("payment declined")
+	ErrPaymentTimeout  = errors.New("payment timed out")
+)
+
+// PaymentStatus represents the outcome of a payment attempt.
+type PaymentStatus string
+
+// Payment statuses.
+const (
+	PaymentSucceeded PaymentStatus = "succeeded"
+	PaymentDeclined  PaymentStatus = "declined"
+	PaymentFailed    PaymentStatus = "failed"
+)
+
+// Payment represents an attempt to charge a buyer for an order.
+type Payment struct {
+	ID               int64
+	PublicID         PublicID
+	OrderID          int64
+	OrderPublicID    PublicID
+	Amount           Money
+	Status           PaymentStatus
+	GatewayReference string // the identifier of the charge assigned by the payment gateway
+	CreatedAt        time.Time
+}
+
+// Charge represents a request to move money from a buyer through a payment gateway.
+type Charge struct {
+	Reference string // identifies the charge on our side, used by gateways for idempotency
+	Amount    Money
+	Source    string // the payment method token collected by the client
+}
+
+// RefundRequest represents a request to give back money of a previous charge through a payment gateway.
+type RefundRequest struct {
+	Reference       string // identifies the refund on our side, used by gateways for idempotency
+	ChargeReference string // the gateway reference of the refunded charge
+	Amount          Money
+}
+
+// PaymentGateway represents an external payment provider.
+type PaymentGateway interface {
+	// Charge charges the buyer and returns the gateway reference of the charge.
+	// Repeated calls with the same reference must not charge the buyer twice,
+	// they return the outcome of the first one. It returns ErrPaymentDeclined if
+	// the provider refused the charge and ErrPaymentTimeout if the provider did
+	// not answer in time.
+	Charge(ctx context.Context, charge Charge) (string, error)
+
+	// Refund gives back the requested amount of a charge and returns the gateway
+	// reference of the refund. Repeated calls with the same reference must not
+	// refund the money twice. It returns ErrPaymentTimeout if the provider did
+	// not answer in time.
+	Refund(ctx context.Context, refund RefundRequest) (string, error)
+}
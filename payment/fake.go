@@ -0,0 +1,85 @@
+package payment
+
+import (
+	"context"
+	"sync"
+
+	nanoid "github.com/matoous/go-nanoid/v2"
+	tixer "github.com/mroobert/monorepo-tixer"
+)
+
+// Payment method tokens understood by the FakeGateway.
+// Any other token results in a successful charge.
+const (
+	FakeTokenDecline = "tok_decline"
+	FakeTokenTimeout = "tok_timeout"
+)
+
+// FakeGateway is an in-process payment gateway that never talks to a real provider.
+// The outcome of a charge is chosen by the payment method token, which allows the
+// whole purchase flow to be exercised offline.
+type FakeGateway struct {
+	mu      sync.Mutex
+	charges map[string]fakeCharge // the outcome of the charges by their reference
+}
+
+// fakeCharge represents the outcome of a charge made through the FakeGateway.
+type fakeCharge struct {
+	reference string
+	err       error
+}
+
+func NewFakeGateway() *FakeGateway {
+	return &FakeGateway{
+		charges: make(map[string]fakeCharge),
+	}
+}
+
+// Charge simulates a charge: FakeTokenDecline is declined, FakeTokenTimeout is charged but
+// its answer is lost, i.e. it blocks until ctx is done, e.g. when the gateway timeout elapses,
+// and everything else succeeds. A repeated charge returns the outcome of the first one.
+func (g *FakeGateway) Charge(ctx context.Context, charge tixer.Charge) (string, error) {
+	g.mu.Lock()
+	previous, ok := g.charges[charge.Reference]
+	g.mu.Unlock()
+	if ok {
+		return previous.reference, previous.err
+	}
+
+	var outcome fakeCharge
+	switch charge.Source {
+	case FakeTokenDecline:
+		outcome.err = tixer.ErrPaymentDeclined
+	default:
+		reference, err := g.reference("ch")
+		if err != nil {
+			return "", err
+		}
+		outcome.reference = reference
+	}
+
+	g.mu.Lock()
+	g.charges[charge.Reference] = outcome
+	g.mu.Unlock()
+
+	if charge.Source == FakeTokenTimeout {
+		<-ctx.Done()
+		return "", tixer.ErrPaymentTimeout
+	}
+
+	return outcome.reference, outcome.err
+}
+
+// Refund simulates a refund. Refunds of charges created by the fake gateway always succeed.
+func (g *FakeGateway) Refund(ctx context.Context, refund tixer.RefundRequest) (string, error) {
+	return g.reference("re")
+}
+
+// reference generates a gateway reference with the given prefix.
+func (g *FakeGateway) reference(prefix string) (string, error) {
+	id, err := nanoid.Generate(tixer.PublicIDAlphabet, 24)
+	if err != nil {
+		return "", err
+	}
+	return "fake_" + prefix + "_" + id, nil
+}
@@ -0,0 +1,67 @@
+// This package provides the payment gateway implementations.
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	tixer "github.com/mroobert/monorepo-tixer"
+)
+
+// Config represents the configuration details for the payment gateway.
+type Config struct {
+	Gateway string        // the gateway implementation to use, e.g. "fake"
+	Timeout time.Duration // sets the maximum time a gateway call can take before it is abandoned
+}
+
+// NewGateway creates the payment gateway selected by the configuration.
+// Every call of the gateway is abandoned once the configured timeout elapses.
+func NewGateway(cfg Config) (tixer.PaymentGateway, error) {
+	var gateway tixer.PaymentGateway
+	switch cfg.Gateway {
+	case "fake":
+		gateway = NewFakeGateway()
+	default:
+		return nil, fmt.Errorf("unknown payment gateway: %s", cfg.Gateway)
+	}
+
+	return &timeoutGateway{
+		Gateway: gateway,
+		Timeout: cfg.Timeout,
+	}, nil
+}
+
+// timeoutGateway bounds the calls of the gateway it wraps with a timeout, so a provider
+// that does not answer cannot hold a request longer than the configured time.
+type timeoutGateway struct {
+	Gateway tixer.PaymentGateway
+	Timeout time.Duration
+}
+
+// Charge charges the buyer through the wrapped gateway. It returns ErrPaymentTimeout if the timeout elapses.
+func (g *timeoutGateway) Charge(ctx context.Context, charge tixer.Charge) (string, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, g.Timeout)
+	defer cancel()
+
+	reference, err := g.Gateway.Charge(timeoutCtx, charge)
+	return reference, timeoutError(err)
+}
+
+// Refund gives money back through the wrapped gateway. It returns ErrPaymentTimeout if the timeout elapses.
+func (g *timeoutGateway) Refund(ctx context.Context, refund tixer.RefundRequest) (string, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, g.Timeout)
+	defer cancel()
+
+	reference, err := g.Gateway.Refund(timeoutCtx, refund)
+	return reference, timeoutError(err)
+}
+
+// timeoutError converts the error of a gateway call that was abandoned at its deadline to ErrPaymentTimeout.
+func timeoutError(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return tixer.ErrPaymentTimeout
+	}
+	return err
+}
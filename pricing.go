@@ -0,0 +1,174 @@
+package tixer
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// MaxPricingRules is the highest number of pricing rules a ticket can have.
+const MaxPricingRules = 10
+
+// MaxPriceTiers is the highest number of price tiers a ticket can have.
+const MaxPriceTiers = 10
+
+// PriceTier is a price a ticket is sold at from a given moment, e.g. early bird, regular or door.
+// A tier with a quantity is sold out once that many units were sold at its price; units given back
+// by cancelled, expired or refunded orders are put on sale at the current tier, not at this one.
+type PriceTier struct {
+	ID       int64
+	Name     string
+	Price    Money
+	StartsAt time.Time
+	Quantity *int32 // nil if the tier is not capped
+	Sold     int32  // the number of units sold at the tier price
+}
+
+// SoldOut reports whether the units of a capped tier were all sold.
+func (p PriceTier) SoldOut() bool {
+	return p.Quantity != nil && p.Sold >= *p.Quantity
+}
+
+// PricingRule changes the price of a ticket once more than SoldAbove percent
+// of its capacity is sold or held, e.g. +10% when more than 80% is sold.
+type PricingRule struct {
+	SoldAbove     int32 // the share of the capacity, in percent, that must be exceeded
+	AdjustPercent int32 // the change of the base price in percent, negative to lower it
+}
+
+// validatePricingRules checks the pricing rules of a ticket.
+// It returns an empty string if the rules are valid and the reason they are not otherwise.
+func validatePricingRules(rules []PricingRule) string {
+	if len(rules) > MaxPricingRules {
+		return fmt.Sprintf("must not be more than %d rules", MaxPricingRules)
+	}
+
+	seen := make(map[int32]bool, len(rules))
+	for _, rule := range rules {
+		switch {
+		case rule.SoldAbove < 0 || rule.SoldAbove > 99:
+			return "soldAbove must be between 0 and 99"
+		case rule.AdjustPercent == 0 || rule.AdjustPercent < -99 || rule.AdjustPercent > 1000:
+			return "adjustPercent must be between -99 and 1000 and not 0"
+		case seen[rule.SoldAbove]:
+			return fmt.Sprintf("soldAbove %d must not be used by more than one rule", rule.SoldAbove)
+		}
+		seen[rule.SoldAbove] = true
+	}
+
+	return ""
+}
+
+// validatePriceTiers checks the price tiers of a ticket sold in the given currency under the given rules.
+// It returns an empty string if the tiers are valid and the reason they are not otherwise.
+func validatePriceTiers(tiers []PriceTier, currency Currency, rules TicketRules) string {
+	if len(tiers) > MaxPriceTiers {
+		return fmt.Sprintf("must not be more than %d tiers", MaxPriceTiers)
+	}
+
+	names := make(map[string]bool, len(tiers))
+	for i, tier := range tiers {
+		switch {
+		case tier.Name == "" || len(tier.Name) > 50:
+			return "name must be between 1 and 50 characters long"
+		case names[tier.Name]:
+			return fmt.Sprintf("name %q must not be used by more than one tier", tier.Name)
+		case tier.Price.Currency != currency:
+			return "price must be in the currency of the ticket"
+		case !tier.StartsAt.Equal(tier.StartsAt.Truncate(time.Second)):
+			return fmt.Sprintf("startsAt of %q must be a whole second", tier.Name)
+		case i > 0 && !tier.StartsAt.After(tiers[i-1].StartsAt):
+			return "must be ordered by startsAt, with a different startsAt for every tier"
+		case tier.Quantity != nil && *tier.Quantity <= 0:
+			return "quantity must be greater than 0"
+		case tier.Quantity != nil && *tier.Quantity < tier.Sold:
+			return fmt.Sprintf("quantity of %q must not be less than the %d units already sold", tier.Name, tier.Sold)
+		}
+		if violation := rules.CheckPrice(tier.Price); violation != nil {
+			return fmt.Sprintf("price of %q %s", tier.Name, violation)
+		}
+		names[tier.Name] = true
+	}
+
+	return ""
+}
+
+// CheckRemovedPriceTiers checks the price tiers of a ticket that the updated tiers no longer have.
+// A tier that units were sold at cannot be removed, the orders placed at its price point to it.
+// It returns an empty string if the removal is allowed and the reason it is not otherwise.
+func CheckRemovedPriceTiers(current, updated []PriceTier) string {
+	for _, tier := range current {
+		kept := slices.ContainsFunc(updated, func(u PriceTier) bool { return u.Name == tier.Name })
+		if !kept && tier.Sold > 0 {
+			return fmt.Sprintf("tier %q must not be removed, %d units were sold at its price", tier.Name, tier.Sold)
+		}
+	}
+
+	return ""
+}
+
+// CurrentTier returns the tier the ticket is sold at the given moment and the tier after it.
+// The current tier is the last tier that started, or, when its units are sold out, the first
+// tier after it that is not sold out, which then starts early. It returns a nil current tier
+// if no tier started yet or all of them are sold out; the price of the ticket applies then.
+func (t Ticket) CurrentTier(at time.Time) (current, next *PriceTier) {
+	started := -1
+	for i := range t.PriceTiers {
+		if t.PriceTiers[i].StartsAt.After(at) {
+			break
+		}
+		started = i
+	}
+
+	if started < 0 {
+		if len(t.PriceTiers) > 0 {
+			return nil, &t.PriceTiers[0]
+		}
+		return nil, nil
+	}
+
+	for i := started; i < len(t.PriceTiers); i++ {
+		if t.PriceTiers[i].SoldOut() {
+			continue
+		}
+		if i+1 < len(t.PriceTiers) {
+			return &t.PriceTiers[i], &t.PriceTiers[i+1]
+		}
+		return &t.PriceTiers[i], nil
+	}
+
+	return nil, nil
+}
+
+// EffectivePrice returns the price the ticket is sold at the given moment. The price of the current
+// tier, or of the ticket if there is none, is adjusted by the pricing rules. Only the rule with the
+// highest threshold exceeded by the units sold or held applies, the rules do not add up. The adjustment
+// is rounded toward zero to the minor unit and the result is kept between the lowest and highest
+// price the ticket rules of its organizer allow in the currency, so a rule can never make a ticket
+// free or more expensive than the organizer may sell it.
+func (t Ticket) EffectivePrice(at time.Time, rules TicketRules) Money {
+	base := t.Price
+	if tier, _ := t.CurrentTier(at); tier != nil {
+		base = tier.Price
+	}
+
+	taken := int64(t.Sold + t.Held)
+
+	var rule *PricingRule
+	for i := range t.PricingRules {
+		if taken*100 <= int64(t.PricingRules[i].SoldAbove)*int64(t.Capacity) {
+			continue
+		}
+		if rule == nil || t.PricingRules[i].SoldAbove > rule.SoldAbove {
+			rule = &t.PricingRules[i]
+		}
+	}
+
+	if rule == nil {
+		return base
+	}
+
+	price := Money{Amount: base.Amount + base.Percent(rule.AdjustPercent).Amount, Currency: base.Currency}
+
+	return rules.ClampPrice(price)
+}
@@ -0,0 +1,146 @@
+package tixer
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// DiscountType represents how a promo code lowers the price.
+type DiscountType string
+
+// Discount types.
+const (
+	DiscountPercentage DiscountType = "percentage" // a share of the price, rounded down to the minor unit
+	DiscountFixed      DiscountType = "fixed"      // a fixed amount, never more than the price
+)
+
+// PromoScope represents the tickets a promo code can be applied to.
+type PromoScope string
+
+// Promo code scopes.
+const (
+	PromoScopeAll    PromoScope = "all"
+	PromoScopeEvent  PromoScope = "event"
+	PromoScopeTicket PromoScope = "ticket"
+)
+
+// promoCodeRX matches the accepted promo codes, e.g. SUMMER-10.
+var promoCodeRX = regexp.MustCompile(`^[A-Z0-9_-]{3,32}$`)
+
+// PromoCode represents a code that gives a discount on the price of a ticket at checkout.
+type PromoCode struct {
+	ID                    int64
+	PublicID              PublicID
+	Code                  string // upper case, unique
+	DiscountType          DiscountType
+	PercentOff            int32 // set for percentage discounts
+	AmountOff             Money // set for fixed discounts
+	Scope                 PromoScope
+	EventID               *int64 // set when the code is scoped to an event
+	EventPublicID         *PublicID
+	TicketID              *int64 // set when the code is scoped to a ticket
+	TicketPublicID        *PublicID
+	ValidFrom             *time.Time // nil if the code is valid right away
+	ValidUntil            *time.Time // nil if the code never expires
+	MaxRedemptions        *int32     // nil if the code can be redeemed any number of times
+	MaxRedemptionsPerUser *int32     // nil if a user can redeem the code any number of times
+	Redemptions           int32      // the number of orders that redeemed the code and were not cancelled unpaid
+	CreatedAt             time.Time
+}
+
+// Validate checks promo code's fields to ensure that the basic business rules are met.
+// It returns a boolean indicating if the promo code is valid and a map of errors if it's not.
+func (p PromoCode) Validate() (bool, map[string]string) {
+	errors := make(map[string]string)
+
+	if !promoCodeRX.MatchString(p.Code) {
+		errors["code"] = "must be 3 to 32 letters, digits, dashes or underscores"
+	}
+
+	switch p.DiscountType {
+	case DiscountPercentage:
+		if p.PercentOff < 1 || p.PercentOff > 100 {
+			errors["percentOff"] = "must be between 1 and 100"
+		}
+	case DiscountFixed:
+		if p.AmountOff.Amount <= 0 {
+			errors["amountOff"] = "must be greater than 0"
+		}
+		if err := ValidateCurrency(string(p.AmountOff.Currency)); err != nil {
+			errors["amountOff"] = "must be in a supported currency"
+		}
+	default:
+		errors["discountType"] = "must be percentage or fixed"
+	}
+
+	switch p.Scope {
+	case PromoScopeAll:
+	case PromoScopeEvent:
+		if p.EventID == nil {
+			errors["eventID"] = "must be provided for an event scoped code"
+		}
+	case PromoScopeTicket:
+		if p.TicketID == nil {
+			errors["ticketID"] = "must be provided for a ticket scoped code"
+		}
+	default:
+		errors["scope"] = "must be all, event or ticket"
+	}
+
+	if p.ValidFrom != nil && p.ValidUntil != nil && !p.ValidUntil.After(*p.ValidFrom) {
+		errors["validUntil"] = "must be after validFrom"
+	}
+
+	if p.MaxRedemptions != nil && *p.MaxRedemptions <= 0 {
+		errors["maxRedemptions"] = "must be greater than 0"
+	}
+
+	if p.MaxRedemptionsPerUser != nil && *p.MaxRedemptionsPerUser <= 0 {
+		errors["maxRedemptionsPerUser"] = "must be greater than 0"
+	}
+
+	if len(errors) > 0 {
+		return false, errors
+	}
+
+	return true, nil
+}
+
+// Discount returns the discount the promo code gives on the effective price of the ticket at the given moment,
+// the ticket following the given rules.
+// It returns an EUNPROCESSABLE error if the code cannot be applied to the ticket at that moment.
+// The redemption caps are not checked here, they are enforced when the order is stored.
+func (p PromoCode) Discount(ticket Ticket, at time.Time, rules TicketRules) (Money, error) {
+	if p.ValidFrom != nil && at.Before(*p.ValidFrom) {
+		return Money{}, Errorf(EUNPROCESSABLE, "the promo code is valid from %s", p.ValidFrom.Format(time.RFC3339))
+	}
+
+	if p.ValidUntil != nil && !at.Before(*p.ValidUntil) {
+		return Money{}, Errorf(EUNPROCESSABLE, "the promo code expired on %s", p.ValidUntil.Format(time.RFC3339))
+	}
+
+	switch p.Scope {
+	case PromoScopeEvent:
+		if ticket.EventID == nil || *ticket.EventID != *p.EventID {
+			return Money{}, Errorf(EUNPROCESSABLE, "the promo code does not apply to the event of this ticket")
+		}
+	case PromoScopeTicket:
+		if ticket.ID != *p.TicketID {
+			return Money{}, Errorf(EUNPROCESSABLE, "the promo code does not apply to this ticket")
+		}
+	}
+
+	price := ticket.EffectivePrice(at, rules)
+	switch p.DiscountType {
+	case DiscountPercentage:
+		return price.Percent(p.PercentOff), nil
+	case DiscountFixed:
+		if p.AmountOff.Currency != price.Currency {
+			return Money{}, Errorf(EUNPROCESSABLE, "the promo code only applies to prices in %s", p.AmountOff.Currency)
+		}
+		return Money{Amount: min(p.AmountOff.Amount, price.Amount), Currency: price.Currency}, nil
+	default:
+		return Money{}, fmt.Errorf("unknown discount type %q", p.DiscountType)
+	}
+}
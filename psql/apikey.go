@@ -0,0 +1,170 @@
+package psql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	tixer "github.com/mroobert/monorepo-tixer"
+)
+
+const apiKeysTable = "api_keys"
+
+// APIKeyRepository persists API keys in the database.
+type APIKeyRepository struct {
+	DB           *pgxpool.Pool
+	QueryTimeout time.Duration
+}
+
+func NewAPIKeyRepository(db *pgxpool.Pool, queryTimeout time.Duration) *APIKeyRepository {
+	return &APIKeyRepository{
+		DB:           db,
+		QueryTimeout: queryTimeout,
+	}
+}
+
+// Insert inserts a new API key for the user referenced by key.UserID in the database.
+func (kr *APIKeyRepository) Insert(ctx context.Context, key tixer.APIKey) (tixer.APIKey, error) {
+	query := `WITH k AS (INSERT INTO ` + apiKeysTable +
+		` (public_id, user_id, name, hint, key_hash, scopes, expires_at) VALUES ($1, $2, $3, $4, $5, $6, $7)
+        RETURNING id, public_id, user_id, name, hint, key_hash, scopes, expires_at, last_used_at, created_at)` +
+		` SELECT k.id, k.public_id, k.user_id, u.public_id, k.name, k.hint, k.key_hash, k.scopes, k.expires_at, k.last_used_at, k.created_at` +
+		` FROM k JOIN ` + usersTable + ` u ON u.id = k.user_id`
+
+	args := []any{key.PublicID, key.UserID, key.Name, key.Hint, key.Hash, key.Scopes, key.ExpiresAt}
+
+	queryCtx, cancel := context.WithTimeout(ctx, kr.QueryTimeout)
+	defer cancel()
+
+	createdKey, err := scanAPIKey(kr.DB.QueryRow(queryCtx, query, args...))
+	if err != nil {
+		return tixer.APIKey{}, fmt.Errorf("failed to insert api key in database: %w", err)
+	}
+
+	return createdKey, nil
+}
+
+// SelectOne reads an API key of a user from the database.
+func (kr *APIKeyRepository) SelectOne(ctx context.Context, id tixer.PublicID, userID int64) (tixer.APIKey, error) {
+	query := `SELECT k.id, k.public_id, k.user_id, u.public_id, k.name, k.hint, k.key_hash, k.scopes, k.expires_at, k.last_used_at, k.created_at` +
+		` FROM ` + apiKeysTable + ` k JOIN ` + usersTable + ` u ON u.id = k.user_id` +
+		` WHERE k.public_id = $1 AND k.user_id = $2`
+
+	queryCtx, cancel := context.WithTimeout(ctx, kr.QueryTimeout)
+	defer cancel()
+
+	key, err := scanAPIKey(kr.DB.QueryRow(queryCtx, query, id, userID))
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return tixer.APIKey{}, ErrDbRecordNotFound
+		default:
+			return tixer.APIKey{}, fmt.Errorf("failed to select api key from database: %w", err)
+		}
+	}
+
+	return key, nil
+}
+
+// SelectMultiple reads the API keys of a user from the database.
+func (kr *APIKeyRepository) SelectMultiple(ctx context.Context, userID int64) ([]tixer.APIKey, error) {
+	query := `SELECT k.id, k.public_id, k.user_id, u.public_id, k.name, k.hint, k.key_hash, k.scopes, k.expires_at, k.last_used_at, k.created_at` +
+		` FROM ` + apiKeysTable + ` k JOIN ` + usersTable + ` u ON u.id = k.user_id` +
+		` WHERE k.user_id = $1 ORDER BY k.id`
+
+	queryCtx, cancel := context.WithTimeout(ctx, kr.QueryTimeout)
+	defer cancel()
+
+	rows, err := kr.DB.Query(queryCtx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select api keys from database: %w", err)
+	}
+
+	defer rows.Close()
+
+	keys := []tixer.APIKey{}
+
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row result: %w", err)
+		}
+
+		keys = append(keys, key)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over rows result: %w", err)
+	}
+
+	return keys, nil
+}
+
+// Use reads the unexpired API key with the given hash from the database
+// and records that it was used.
+// It returns ErrDbRecordNotFound if no such key exists or the key has expired.
+func (kr *APIKeyRepository) Use(ctx context.Context, hash []byte) (tixer.APIKey, error) {
+	query := `WITH k AS (UPDATE ` + apiKeysTable + ` SET last_used_at = NOW()` +
+		` WHERE key_hash = $1 AND (expires_at IS NULL OR expires_at > NOW())` +
+		` RETURNING id, public_id, user_id, name, hint, key_hash, scopes, expires_at, last_used_at, created_at)` +
+		` SELECT k.id, k.public_id, k.user_id, u.public_id, k.name, k.hint, k.key_hash, k.scopes, k.expires_at, k.last_used_at, k.created_at` +
+		` FROM k JOIN ` + usersTable + ` u ON u.id = k.user_id`
+
+	queryCtx, cancel := context.WithTimeout(ctx, kr.QueryTimeout)
+	defer cancel()
+
+	key, err := scanAPIKey(kr.DB.QueryRow(queryCtx, query, hash))
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return tixer.APIKey{}, ErrDbRecordNotFound
+		default:
+			return tixer.APIKey{}, fmt.Errorf("failed to use api key in database: %w", err)
+		}
+	}
+
+	return key, nil
+}
+
+// Delete deletes an API key of a user from the database, revoking it.
+func (kr *APIKeyRepository) Delete(ctx context.Context, id tixer.PublicID, userID int64) error {
+	query := `DELETE FROM ` + apiKeysTable + ` WHERE public_id = $1 AND user_id = $2`
+
+	queryCtx, cancel := context.WithTimeout(ctx, kr.QueryTimeout)
+	defer cancel()
+
+	res, err := kr.DB.Exec(queryCtx, query, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete api key from database: %w", err)
+	}
+
+	if res.RowsAffected() == 0 {
+		return ErrDbRecordNotFound
+	}
+
+	return nil
+}
+
+// scanAPIKey scans a row that holds the API key columns followed by the owner's public ID.
+func scanAPIKey(row pgx.Row) (tixer.APIKey, error) {
+	var key tixer.APIKey
+
+	err := row.Scan(
+		&key.ID,
+		&key.PublicID,
+		&key.UserID,
+		&key.UserPublicID,
+		&key.Name,
+		&key.Hint,
+		&key.Hash,
+		&key.Scopes,
+		&key.ExpiresAt,
+		&key.LastUsedAt,
+		&key.CreatedAt,
+	)
+
+	return key, err
+}
@@ -0,0 +1,107 @@
+package psql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	tixer "github.com/mroobert/monorepo-tixer"
+)
+
+var ErrDbAlreadyCheckedIn = errors.New("db already checked in")
+
+const checkInsTable = "checkins"
+
+// checkInColumns lists the columns read by scanCheckIn, for a checkins table aliased ci joined
+// to its order o, the ticket t of the order, the event e of the ticket and the scanning user u.
+const checkInColumns = `ci.id, ci.order_id, o.public_id, t.public_id, e.public_id, ci.user_id, u.email, ci.scanned_at`
+
+// checkInJoins joins a checkins table aliased ci to the tables read by checkInColumns.
+const checkInJoins = ` JOIN ` + ordersTable + ` o ON o.id = ci.order_id` +
+	` JOIN ` + ticketsTable + ` t ON t.id = o.ticket_id` +
+	` LEFT JOIN ` + eventsTable + ` e ON e.id = t.event_id` +
+	` JOIN ` + usersTable + ` u ON u.id = ci.user_id`
+
+// CheckInRepository persists the admissions of ticket holders at the gate in the database.
+type CheckInRepository struct {
+	DB           *pgxpool.Pool
+	QueryTimeout time.Duration
+}
+
+func NewCheckInRepository(db *pgxpool.Pool, queryTimeout time.Duration) *CheckInRepository {
+	return &CheckInRepository{
+		DB:           db,
+		QueryTimeout: queryTimeout,
+	}
+}
+
+// Insert checks in the ticket of the order referenced by checkIn.OrderID, scanned by checkIn.ScannedByID.
+// The order is only checked in if it is still paid and still has the given version, which the credential
+// was issued for; otherwise it returns ErrDbEditConflict. The order gets a new version in the same statement,
+// so a refund or resale that read it before the scan conflicts instead of committing after the admission.
+// If the ticket was already checked in, the earlier check-in is returned together with ErrDbAlreadyCheckedIn,
+// so concurrent scans admit it once.
+func (cr *CheckInRepository) Insert(ctx context.Context, checkIn tixer.CheckIn, version int32) (tixer.CheckIn, error) {
+	query := `WITH scanned AS (UPDATE ` + ordersTable + ` SET version = version + 1, updated_at = NOW()` +
+		` WHERE id = $1 AND status = $3 AND version = $4 RETURNING id),` +
+		` ci AS (INSERT INTO ` + checkInsTable + ` (order_id, user_id) SELECT id, $2 FROM scanned` +
+		` ON CONFLICT (order_id) DO NOTHING RETURNING *)` +
+		` SELECT ` + checkInColumns + ` FROM ci` + checkInJoins
+
+	args := []any{checkIn.OrderID, checkIn.ScannedByID, tixer.OrderPaid, version}
+
+	queryCtx, cancel := context.WithTimeout(ctx, cr.QueryTimeout)
+	defer cancel()
+
+	tx, err := beginScopedTx(queryCtx, cr.DB)
+	if err != nil {
+		return tixer.CheckIn{}, err
+	}
+	defer tx.Rollback(queryCtx)
+
+	createdCheckIn, err := scanCheckIn(tx.QueryRow(queryCtx, query, args...))
+	if err == nil {
+		if err := tx.Commit(queryCtx); err != nil {
+			return tixer.CheckIn{}, fmt.Errorf("failed to commit check-in transaction: %w", err)
+		}
+		return createdCheckIn, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return tixer.CheckIn{}, fmt.Errorf("failed to insert check-in in database: %w", err)
+	}
+
+	existingQuery := `SELECT ` + checkInColumns + ` FROM ` + checkInsTable + ` ci` + checkInJoins +
+		` WHERE ci.order_id = $1`
+
+	existingCheckIn, err := scanCheckIn(tx.QueryRow(queryCtx, existingQuery, checkIn.OrderID))
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return tixer.CheckIn{}, ErrDbEditConflict
+		default:
+			return tixer.CheckIn{}, fmt.Errorf("failed to select check-in from database: %w", err)
+		}
+	}
+
+	return existingCheckIn, ErrDbAlreadyCheckedIn
+}
+
+func scanCheckIn(row pgx.Row) (tixer.CheckIn, error) {
+	var checkIn tixer.CheckIn
+
+	err := row.Scan(
+		&checkIn.ID,
+		&checkIn.OrderID,
+		&checkIn.OrderPublicID,
+		&checkIn.TicketPublicID,
+		&checkIn.EventPublicID,
+		&checkIn.ScannedByID,
+		&checkIn.ScannedBy,
+		&checkIn.ScannedAt,
+	)
+
+	return checkIn, err
+}
@@ -0,0 +1,208 @@
+package psql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	tixer "github.com/mroobert/monorepo-tixer"
+)
+
+const eventsTable = "events"
+
+// EventRepository persists events in the database.
+type EventRepository struct {
+	DB           *pgxpool.Pool
+	QueryTimeout time.Duration
+}
+
+func NewEventRepository(db *pgxpool.Pool, queryTimeout time.Duration) *EventRepository {
+	return &EventRepository{
+		DB:           db,
+		QueryTimeout: queryTimeout,
+	}
+}
+
+// Insert inserts a new event in the database.
+func (er *EventRepository) Insert(ctx context.Context, event tixer.Event) (tixer.Event, error) {
+	query := `WITH e AS (INSERT INTO ` + eventsTable +
+		` (public_id, user_id, venue_id, name, starts_at, ends_at, time_zone) VALUES ($1, $2, $3, $4, $5, $6, $7)
+        RETURNING id, public_id, user_id, venue_id, name, starts_at, ends_at, time_zone, version, created_at, updated_at)` +
+		` SELECT e.id, e.public_id, e.user_id, e.venue_id, v.public_id, e.name, e.starts_at, e.ends_at, e.time_zone, e.version, e.created_at, e.updated_at` +
+		` FROM e LEFT JOIN ` + venuesTable + ` v ON v.id = e.venue_id`
+
+	args := []any{event.PublicID, event.OwnerID, event.VenueID, event.Name, event.StartsAt, event.EndsAt, event.TimeZone}
+
+	queryCtx, cancel := context.WithTimeout(ctx, er.QueryTimeout)
+	defer cancel()
+
+	createdEvent, err := scanEvent(er.DB.QueryRow(queryCtx, query, args...))
+	if err != nil {
+		return tixer.Event{}, fmt.Errorf("failed to insert event in database: %w", err)
+	}
+
+	return createdEvent, nil
+}
+
+// SelectOne reads an event from the database.
+func (er *EventRepository) SelectOne(ctx context.Context, id tixer.PublicID) (tixer.Event, error) {
+	query := `SELECT e.id, e.public_id, e.user_id, e.venue_id, v.public_id, e.name, e.starts_at, e.ends_at, e.time_zone, e.version, e.created_at, e.updated_at` +
+		` FROM ` + eventsTable + ` e LEFT JOIN ` + venuesTable + ` v ON v.id = e.venue_id` +
+		` WHERE e.public_id = $1`
+
+	queryCtx, cancel := context.WithTimeout(ctx, er.QueryTimeout)
+	defer cancel()
+
+	event, err := scanEvent(er.DB.QueryRow(queryCtx, query, id))
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return tixer.Event{}, ErrDbRecordNotFound
+		default:
+			return tixer.Event{}, fmt.Errorf("failed to select event from database: %w", err)
+		}
+	}
+
+	return event, nil
+}
+
+type EventFilter struct {
+	Name          string
+	VenueID       *tixer.PublicID // when set, only the events taking place at this venue are read
+	Limit         int
+	Offset        int
+	SortColumn    string
+	SortDirection string
+}
+
+// SelectMultiple reads events based on filters from the database.
+func (er *EventRepository) SelectMultiple(ctx context.Context, filter EventFilter) ([]tixer.Event, Pagination, error) {
+	query := fmt.Sprintf(`SELECT count(*) OVER(), e.id, e.public_id, e.user_id, e.venue_id, v.public_id, e.name, e.starts_at, e.ends_at, e.time_zone, e.version, e.created_at, e.updated_at`+
+		` FROM `+eventsTable+` e LEFT JOIN `+venuesTable+` v ON v.id = e.venue_id`+
+		` WHERE (to_tsvector('simple', e.name) @@ plainto_tsquery('simple', $1) OR $1 = '')`+
+		` AND (v.public_id = $2 OR $2 IS NULL)`+
+		` ORDER BY e.%s %s, e.id LIMIT $3 OFFSET $4`, filter.SortColumn, filter.SortDirection)
+
+	args := []any{filter.Name, filter.VenueID, filter.Limit, filter.Offset}
+
+	queryCtx, cancel := context.WithTimeout(ctx, er.QueryTimeout)
+	defer cancel()
+
+	rows, err := er.DB.Query(queryCtx, query, args...)
+	if err != nil {
+		return nil, Pagination{}, fmt.Errorf("failed to select events from database: %w", err)
+	}
+
+	defer rows.Close()
+
+	totalRecords := 0
+	events := []tixer.Event{}
+
+	for rows.Next() {
+		var event tixer.Event
+
+		err := rows.Scan(
+			&totalRecords,
+			&event.ID,
+			&event.PublicID,
+			&event.OwnerID,
+			&event.VenueID,
+			&event.VenuePublicID,
+			&event.Name,
+			&event.StartsAt,
+			&event.EndsAt,
+			&event.TimeZone,
+			&event.Version,
+			&event.CreatedAt,
+			&event.UpdatedAt,
+		)
+		if err != nil {
+			return nil, Pagination{}, fmt.Errorf("failed to scan row result: %w", err)
+		}
+
+		events = append(events, event)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, Pagination{}, fmt.Errorf("failed to iterate over rows result: %w", err)
+	}
+
+	pagination := calculatePagination(totalRecords, filter.Offset/filter.Limit+1, filter.Limit)
+
+	return events, pagination, nil
+}
+
+// Update updates an event in the database.
+func (er *EventRepository) Update(ctx context.Context, event *tixer.Event) error {
+	query := `UPDATE ` + eventsTable +
+		` SET venue_id = $1, name = $2, starts_at = $3, ends_at = $4, time_zone = $5, version = version + 1, updated_at = NOW()` +
+		` WHERE public_id = $6 AND version = $7 RETURNING version, updated_at`
+
+	args := []any{event.VenueID, event.Name, event.StartsAt, event.EndsAt, event.TimeZone, event.PublicID, event.Version}
+
+	queryCtx, cancel := context.WithTimeout(ctx, er.QueryTimeout)
+	defer cancel()
+
+	if err := er.DB.QueryRow(queryCtx, query, args...).Scan(&event.Version, &event.UpdatedAt); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return ErrDbEditConflict
+		default:
+			return fmt.Errorf("failed to update event in database: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Delete deletes an event from the database.
+// It returns ErrDbRecordInUse if tickets were issued for the event.
+func (er *EventRepository) Delete(ctx context.Context, id tixer.PublicID) error {
+	query := `DELETE FROM ` + eventsTable + ` WHERE public_id = $1`
+
+	queryCtx, cancel := context.WithTimeout(ctx, er.QueryTimeout)
+	defer cancel()
+
+	res, err := er.DB.Exec(queryCtx, query, id)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		switch {
+		case errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation:
+			return ErrDbRecordInUse
+		default:
+			return fmt.Errorf("failed to delete event from database: %w", err)
+		}
+	}
+
+	if res.RowsAffected() == 0 {
+		return ErrDbRecordNotFound
+	}
+
+	return nil
+}
+
+// scanEvent scans a row that holds the event columns with the venue public ID after the venue ID.
+func scanEvent(row pgx.Row) (tixer.Event, error) {
+	var event tixer.Event
+
+	err := row.Scan(
+		&event.ID,
+		&event.PublicID,
+		&event.OwnerID,
+		&event.VenueID,
+		&event.VenuePublicID,
+		&event.Name,
+		&event.StartsAt,
+		&event.EndsAt,
+		&event.TimeZone,
+		&event.Version,
+		&event.CreatedAt,
+		&event.UpdatedAt,
+	)
+
+	return event, err
+}
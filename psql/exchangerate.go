@@ -0,0 +1,98 @@
+package psql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	tixer "github.com/mroobert/monorepo-tixer"
+)
+
+const exchangeRatesTable = "exchange_rates"
+
+// ExchangeRateRepository persists exchange rates in the database.
+type ExchangeRateRepository struct {
+	DB           *pgxpool.Pool
+	QueryTimeout time.Duration
+}
+
+func NewExchangeRateRepository(db *pgxpool.Pool, queryTimeout time.Duration) *ExchangeRateRepository {
+	return &ExchangeRateRepository{
+		DB:           db,
+		QueryTimeout: queryTimeout,
+	}
+}
+
+// Insert inserts the exchange rates in the database in a single transaction.
+// A rate that already exists for the same pair and time is replaced.
+// The rates are stored with 10 decimals.
+func (er *ExchangeRateRepository) Insert(ctx context.Context, rates []tixer.ExchangeRate) error {
+	query := `INSERT INTO ` + exchangeRatesTable + ` (base, quote, rate, valid_at) VALUES ($1, $2, $3::text::numeric, $4)
+        ON CONFLICT (base, quote, valid_at) DO UPDATE SET rate = EXCLUDED.rate, created_at = NOW()`
+
+	queryCtx, cancel := context.WithTimeout(ctx, er.QueryTimeout)
+	defer cancel()
+
+	tx, err := er.DB.Begin(queryCtx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(queryCtx)
+
+	for _, rate := range rates {
+		args := []any{rate.Base, rate.Quote, rate.Rate.FloatString(10), rate.ValidAt}
+		if _, err := tx.Exec(queryCtx, query, args...); err != nil {
+			return fmt.Errorf("failed to insert exchange rate in database: %w", err)
+		}
+	}
+
+	if err := tx.Commit(queryCtx); err != nil {
+		return fmt.Errorf("failed to commit exchange rates transaction: %w", err)
+	}
+
+	return nil
+}
+
+// SelectLatest reads the latest exchange rate from the base to the quote currency from the database.
+// When only the opposite pair was loaded, its latest rate is inverted.
+// It returns ErrDbRecordNotFound if no rate was loaded for the pair in either direction.
+func (er *ExchangeRateRepository) SelectLatest(ctx context.Context, base, quote tixer.Currency) (tixer.ExchangeRate, error) {
+	query := `SELECT base, quote, rate::text, valid_at FROM ` + exchangeRatesTable +
+		` WHERE (base = $1 AND quote = $2) OR (base = $2 AND quote = $1)` +
+		` ORDER BY base = $1 DESC, valid_at DESC LIMIT 1`
+
+	queryCtx, cancel := context.WithTimeout(ctx, er.QueryTimeout)
+	defer cancel()
+
+	var rate tixer.ExchangeRate
+	var value string
+	if err := er.DB.QueryRow(queryCtx, query, base, quote).Scan(
+		&rate.Base,
+		&rate.Quote,
+		&value,
+		&rate.ValidAt,
+	); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return tixer.ExchangeRate{}, ErrDbRecordNotFound
+		default:
+			return tixer.ExchangeRate{}, fmt.Errorf("failed to select exchange rate from database: %w", err)
+		}
+	}
+
+	var ok bool
+	rate.Rate, ok = new(big.Rat).SetString(value)
+	if !ok {
+		return tixer.ExchangeRate{}, fmt.Errorf("failed to parse exchange rate %q", value)
+	}
+
+	if rate.Base != base {
+		rate = rate.Invert()
+	}
+
+	return rate, nil
+}
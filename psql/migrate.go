@@ -0,0 +1,340 @@
+package psql
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrDbMigrationUnknown = errors.New("db migration unknown")
+
+const schemaMigrationsTable = "schema_migrations"
+
+// migrationLockKey is the key of the advisory lock that keeps two migrators,
+// e.g. two servers migrating on startup, from changing the schema at the same time.
+const migrationLockKey int64 = 7_270_551_024
+
+// Migration represents a change of the database schema, read from a pair of
+// <version>_<name>.up.sql and <version>_<name>.down.sql files.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string // the SQL that applies the change
+	Down    string // the SQL that rolls the change back
+}
+
+// MigrationStatus represents a migration and whether it was applied to the database.
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time // nil if the migration is pending
+}
+
+// Migrator applies and rolls back the migrations of the database schema. The applied versions
+// are tracked in the schema_migrations table; every migration runs in its own transaction.
+type Migrator struct {
+	DB         *pgxpool.Pool
+	Migrations []Migration // ordered by version
+}
+
+// NewMigrator creates a migrator for the migration files found at the root of files.
+func NewMigrator(db *pgxpool.Pool, files fs.FS) (*Migrator, error) {
+	migrations, err := ReadMigrations(files)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		DB:         db,
+		Migrations: migrations,
+	}, nil
+}
+
+// ReadMigrations reads the migration files found at the root of files, ordered by version.
+// Every version must have both an up and a down file.
+func ReadMigrations(files fs.FS) ([]Migration, error) {
+	names, err := fs.Glob(files, "*.sql")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list migration files: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, fileName := range names {
+		base, up := strings.CutSuffix(fileName, ".up.sql")
+		if !up {
+			var down bool
+			if base, down = strings.CutSuffix(fileName, ".down.sql"); !down {
+				return nil, fmt.Errorf("migration file %s must end with .up.sql or .down.sql", fileName)
+			}
+		}
+
+		digits, name, _ := strings.Cut(base, "_")
+		version, err := strconv.ParseInt(digits, 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration file %s must start with a positive version number", fileName)
+		}
+
+		content, err := fs.ReadFile(files, fileName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration file %s: %w", fileName, err)
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: name}
+			byVersion[version] = migration
+		}
+		if migration.Name != name {
+			return nil, fmt.Errorf("migration version %d is used by both %s and %s", version, migration.Name, name)
+		}
+
+		if up {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s must have both an up and a down file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	slices.SortFunc(migrations, func(a, b Migration) int {
+		return cmp.Compare(a.Version, b.Version)
+	})
+
+	return migrations, nil
+}
+
+// Up applies the pending migrations, in version order, and returns them.
+// It stops at the first migration that fails, leaving the ones before it applied.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var done []Migration
+
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		applied, err := appliedMigrations(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.Migrations {
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+
+			query := `INSERT INTO ` + schemaMigrationsTable + ` (version, name) VALUES ($1, $2)`
+			err := runMigration(ctx, conn, migration.Up, query, migration.Version, migration.Name)
+			if err != nil {
+				return fmt.Errorf("failed to apply migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			done = append(done, migration)
+		}
+
+		return nil
+	})
+
+	return done, err
+}
+
+// Down rolls back the last n applied migrations, in reverse version order, and returns them.
+// It returns ErrDbMigrationUnknown if one of them has no migration files.
+func (m *Migrator) Down(ctx context.Context, n int) ([]Migration, error) {
+	var done []Migration
+
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		applied, err := appliedMigrations(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		versions := make([]int64, 0, len(applied))
+		for version := range applied {
+			versions = append(versions, version)
+		}
+		slices.Sort(versions)
+		slices.Reverse(versions)
+
+		for _, version := range versions[:min(n, len(versions))] {
+			i := slices.IndexFunc(m.Migrations, func(migration Migration) bool { return migration.Version == version })
+			if i < 0 {
+				return fmt.Errorf("failed to roll back migration %d: %w", version, ErrDbMigrationUnknown)
+			}
+			migration := m.Migrations[i]
+
+			query := `DELETE FROM ` + schemaMigrationsTable + ` WHERE version = $1`
+			err := runMigration(ctx, conn, migration.Down, query, migration.Version)
+			if err != nil {
+				return fmt.Errorf("failed to roll back migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			done = append(done, migration)
+		}
+
+		return nil
+	})
+
+	return done, err
+}
+
+// Status returns every migration, in version order, with the moment it was applied.
+// It returns ErrDbMigrationUnknown if a version was applied that has no migration files.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	var statuses []MigrationStatus
+
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		applied, err := appliedMigrations(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.Migrations {
+			status := MigrationStatus{Migration: migration}
+			if appliedAt, ok := applied[migration.Version]; ok {
+				status.AppliedAt = &appliedAt
+				delete(applied, migration.Version)
+			}
+			statuses = append(statuses, status)
+		}
+
+		if len(applied) > 0 {
+			unknown := make([]int64, 0, len(applied))
+			for version := range applied {
+				unknown = append(unknown, version)
+			}
+			slices.Sort(unknown)
+
+			return fmt.Errorf("migrations %v are applied: %w", unknown, ErrDbMigrationUnknown)
+		}
+
+		return nil
+	})
+
+	return statuses, err
+}
+
+// Force records the schema as being at the given version without running any migration:
+// the migrations up to the version are marked as applied and the ones after it as pending.
+// It is meant for databases that were migrated by hand, or repaired after a failed migration.
+// Version 0 marks every migration as pending. It returns ErrDbMigrationUnknown if the version has no migration files.
+func (m *Migrator) Force(ctx context.Context, version int64) error {
+	var versions []int64
+	var names []string
+	for _, migration := range m.Migrations {
+		if migration.Version <= version {
+			versions = append(versions, migration.Version)
+			names = append(names, migration.Name)
+		}
+	}
+	if version != 0 && (len(versions) == 0 || versions[len(versions)-1] != version) {
+		return fmt.Errorf("failed to force migration %d: %w", version, ErrDbMigrationUnknown)
+	}
+
+	return m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		tx, err := conn.Begin(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		defer tx.Rollback(ctx)
+
+		deleteQuery := `DELETE FROM ` + schemaMigrationsTable + ` WHERE version > $1`
+		if _, err := tx.Exec(ctx, deleteQuery, version); err != nil {
+			return fmt.Errorf("failed to delete migrations from database: %w", err)
+		}
+
+		insertQuery := `INSERT INTO ` + schemaMigrationsTable + ` (version, name)` +
+			` SELECT * FROM unnest($1::bigint[], $2::text[]) ON CONFLICT (version) DO NOTHING`
+		if _, err := tx.Exec(ctx, insertQuery, versions, names); err != nil {
+			return fmt.Errorf("failed to insert migrations in database: %w", err)
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return fmt.Errorf("failed to commit migrations transaction: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// withLock runs fn on a connection that holds the migration advisory lock,
+// once the schema_migrations table exists.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *pgxpool.Conn) error) error {
+	conn, err := m.DB.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	// The advisory lock belongs to the session, so it must be taken and released on the same connection.
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, migrationLockKey); err != nil {
+		return fmt.Errorf("failed to take migration lock: %w", err)
+	}
+	defer conn.Exec(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock($1)`, migrationLockKey)
+
+	query := `CREATE TABLE IF NOT EXISTS ` + schemaMigrationsTable + ` (` +
+		`version bigint PRIMARY KEY, name text NOT NULL, applied_at timestamp(0) with time zone NOT NULL DEFAULT NOW())`
+	if _, err := conn.Exec(ctx, query); err != nil {
+		return fmt.Errorf("failed to create migrations table: %w", err)
+	}
+
+	return fn(conn)
+}
+
+// appliedMigrations reads the applied migration versions from the database, with the moment they were applied.
+func appliedMigrations(ctx context.Context, conn *pgxpool.Conn) (map[int64]time.Time, error) {
+	query := `SELECT version, applied_at FROM ` + schemaMigrationsTable
+
+	rows, err := conn.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select migrations from database: %w", err)
+	}
+
+	defer rows.Close()
+
+	applied := make(map[int64]time.Time)
+	for rows.Next() {
+		var version int64
+		var appliedAt time.Time
+
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan row result: %w", err)
+		}
+
+		applied[version] = appliedAt
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over rows result: %w", err)
+	}
+
+	return applied, nil
+}
+
+// runMigration runs the SQL of a migration and the query that records it in one transaction,
+// so a migration that fails leaves neither its changes nor its record behind.
+func runMigration(ctx context.Context, conn *pgxpool.Conn, sql string, recordQuery string, args ...any) error {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Without arguments the SQL is sent with the simple protocol, which accepts several statements.
+	if _, err := tx.Exec(ctx, sql); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, recordQuery, args...); err != nil {
+		return fmt.Errorf("failed to record migration: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,474 @@
+package psql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	tixer "github.com/mroobert/monorepo-tixer"
+)
+
+var (
+	ErrDbTicketUnavailable = errors.New("db ticket unavailable")
+)
+
+const ordersTable = "orders"
+
+// OrderRepository persists orders in the database.
+type OrderRepository struct {
+	DB           *pgxpool.Pool
+	QueryTimeout time.Duration
+	ExpiryWindow time.Duration // how long a pending order holds its ticket before it expires
+	ClaimWindow  time.Duration // how long a unit released to the waitlist is held for the next user
+}
+
+func NewOrderRepository(db *pgxpool.Pool, queryTimeout, expiryWindow, claimWindow time.Duration) *OrderRepository {
+	return &OrderRepository{
+		DB:           db,
+		QueryTimeout: queryTimeout,
+		ExpiryWindow: expiryWindow,
+		ClaimWindow:  claimWindow,
+	}
+}
+
+// Insert takes a unit of the ticket referenced by order.TicketPublicID and inserts a new order for it.
+// A buyer with an unexpired waitlist claim on the ticket takes the unit held for them. Otherwise
+// the sold count of the ticket is incremented with a guarded update in the same transaction,
+// so concurrent orders can never take more units than the ticket capacity leaves on sale;
+// the orders that find the ticket sold out get ErrDbTicketUnavailable, and the orders for a ticket
+// that is not published get ErrDbTicketNotPublished.
+// The unit is counted as sold at the price tier referenced by order.PriceTierID, and
+// ErrDbPriceTierSoldOut is returned if the units of the tier were sold out in the meantime.
+// The promo code referenced by order.PromoCodeID is redeemed in the same transaction,
+// and ErrDbPromoCodeExhausted is returned if its redemption caps were reached.
+func (or *OrderRepository) Insert(ctx context.Context, order tixer.Order) (tixer.Order, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, or.QueryTimeout)
+	defer cancel()
+
+	tx, err := beginScopedTx(queryCtx, or.DB)
+	if err != nil {
+		return tixer.Order{}, err
+	}
+	defer tx.Rollback(queryCtx)
+
+	var ticketID int64
+	var claimed bool
+	if order.BuyerID != nil {
+		ticketID, claimed, err = claimHeldUnit(queryCtx, tx, order.TicketPublicID, *order.BuyerID)
+		if err != nil {
+			return tixer.Order{}, err
+		}
+	}
+
+	if !claimed {
+		// Concurrent updates of the ticket row wait for each other and re-check the guard
+		// against the committed counts, so the last unit on sale is taken only once.
+		takeQuery := `UPDATE ` + ticketsTable + ` SET sold = sold + 1` +
+			` WHERE public_id = $1 AND status = $2 AND sold + held < capacity RETURNING id`
+
+		if err := tx.QueryRow(queryCtx, takeQuery, order.TicketPublicID, tixer.TicketPublished).Scan(&ticketID); err != nil {
+			if !errors.Is(err, pgx.ErrNoRows) {
+				return tixer.Order{}, fmt.Errorf("failed to take ticket unit in database: %w", err)
+			}
+
+			statusQuery := `SELECT status FROM ` + ticketsTable + ` WHERE public_id = $1`
+
+			var status tixer.TicketStatus
+			if err := tx.QueryRow(queryCtx, statusQuery, order.TicketPublicID).Scan(&status); err != nil {
+				switch {
+				case errors.Is(err, pgx.ErrNoRows):
+					return tixer.Order{}, ErrDbRecordNotFound
+				default:
+					return tixer.Order{}, fmt.Errorf("failed to check ticket in database: %w", err)
+				}
+			}
+			if status != tixer.TicketPublished {
+				return tixer.Order{}, ErrDbTicketNotPublished
+			}
+			return tixer.Order{}, ErrDbTicketUnavailable
+		}
+	}
+
+	if order.PriceTierID != nil {
+		if err := sellAtPriceTier(queryCtx, tx, *order.PriceTierID, ticketID); err != nil {
+			return tixer.Order{}, err
+		}
+	}
+
+	if order.PromoCodeID != nil {
+		if err := redeemPromoCode(queryCtx, tx, *order.PromoCodeID, order.BuyerID); err != nil {
+			return tixer.Order{}, err
+		}
+	}
+
+	insertQuery := `INSERT INTO ` + ordersTable +
+		` (public_id, user_id, ticket_id, promo_code_id, price_tier_id, subtotal, discount, total, currency, status, expires_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW() + $11::interval)
+        RETURNING id, public_id, user_id, ticket_id, promo_code_id, price_tier_id, subtotal, discount, total, currency, status, version, expires_at, created_at, updated_at`
+
+	args := []any{order.PublicID, order.BuyerID, ticketID, order.PromoCodeID, order.PriceTierID, order.Subtotal.Amount, order.Discount.Amount, order.Total.Amount,
+		order.Total.Currency, tixer.OrderPending, or.ExpiryWindow}
+
+	createdOrder := tixer.Order{TicketPublicID: order.TicketPublicID, PromoCode: order.PromoCode, PriceTier: order.PriceTier}
+	if err := tx.QueryRow(queryCtx, insertQuery, args...).Scan(
+		&createdOrder.ID,
+		&createdOrder.PublicID,
+		&createdOrder.BuyerID,
+		&createdOrder.TicketID,
+		&createdOrder.PromoCodeID,
+		&createdOrder.PriceTierID,
+		&createdOrder.Subtotal.Amount,
+		&createdOrder.Discount.Amount,
+		&createdOrder.Total.Amount,
+		&createdOrder.Subtotal.Currency,
+		&createdOrder.Status,
+		&createdOrder.Version,
+		&createdOrder.ExpiresAt,
+		&createdOrder.CreatedAt,
+		&createdOrder.UpdatedAt,
+	); err != nil {
+		return tixer.Order{}, fmt.Errorf("failed to insert order in database: %w", err)
+	}
+	setOrderCurrency(&createdOrder)
+
+	if err := tx.Commit(queryCtx); err != nil {
+		return tixer.Order{}, fmt.Errorf("failed to commit order transaction: %w", err)
+	}
+
+	return createdOrder, nil
+}
+
+// SelectOne reads an order from the database.
+func (or *OrderRepository) SelectOne(ctx context.Context, id tixer.PublicID) (tixer.Order, error) {
+	query := `SELECT o.id, o.public_id, o.user_id, o.ticket_id, t.public_id, t.tenant_id, o.promo_code_id, pc.code, o.price_tier_id, pt.name, o.subtotal, o.discount, o.total, o.currency, o.status, o.version, o.expires_at, o.created_at, o.updated_at, o.transferred_at, ci.scanned_at` +
+		` FROM ` + ordersTable + ` o JOIN ` + ticketsTable + ` t ON t.id = o.ticket_id` +
+		` LEFT JOIN ` + promoCodesTable + ` pc ON pc.id = o.promo_code_id` +
+		` LEFT JOIN ` + ticketPriceTiersTable + ` pt ON pt.id = o.price_tier_id` +
+		` LEFT JOIN ` + checkInsTable + ` ci ON ci.order_id = o.id` +
+		` WHERE o.public_id = $1`
+
+	queryCtx, cancel := context.WithTimeout(ctx, or.QueryTimeout)
+	defer cancel()
+
+	tx, err := beginScopedTx(queryCtx, or.DB)
+	if err != nil {
+		return tixer.Order{}, err
+	}
+	defer tx.Rollback(queryCtx)
+
+	var order tixer.Order
+	if err := tx.QueryRow(queryCtx, query, id).Scan(
+		&order.ID,
+		&order.PublicID,
+		&order.BuyerID,
+		&order.TicketID,
+		&order.TicketPublicID,
+		&order.TenantID,
+		&order.PromoCodeID,
+		&order.PromoCode,
+		&order.PriceTierID,
+		&order.PriceTier,
+		&order.Subtotal.Amount,
+		&order.Discount.Amount,
+		&order.Total.Amount,
+		&order.Subtotal.Currency,
+		&order.Status,
+		&order.Version,
+		&order.ExpiresAt,
+		&order.CreatedAt,
+		&order.UpdatedAt,
+		&order.TransferredAt,
+		&order.CheckedInAt,
+	); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return tixer.Order{}, ErrDbRecordNotFound
+		default:
+			return tixer.Order{}, fmt.Errorf("failed to select order from database: %w", err)
+		}
+	}
+
+	setOrderCurrency(&order)
+
+	if err := tx.Commit(queryCtx); err != nil {
+		return tixer.Order{}, fmt.Errorf("failed to commit order transaction: %w", err)
+	}
+
+	return order, nil
+}
+
+type OrderFilter struct {
+	BuyerID *int64 // when set, only the orders placed by this user are read
+	Status  tixer.OrderStatus
+	Limit   int
+	Offset  int
+}
+
+// SelectMultiple reads orders based on filters from the database.
+func (or *OrderRepository) SelectMultiple(ctx context.Context, filter OrderFilter) ([]tixer.Order, Pagination, error) {
+	query := `SELECT count(*) OVER(), o.id, o.public_id, o.user_id, o.ticket_id, t.public_id, t.tenant_id, o.promo_code_id, pc.code, o.price_tier_id, pt.name, o.subtotal, o.discount, o.total, o.currency, o.status, o.version, o.expires_at, o.created_at, o.updated_at, o.transferred_at, ci.scanned_at` +
+		` FROM ` + ordersTable + ` o JOIN ` + ticketsTable + ` t ON t.id = o.ticket_id` +
+		` LEFT JOIN ` + promoCodesTable + ` pc ON pc.id = o.promo_code_id` +
+		` LEFT JOIN ` + ticketPriceTiersTable + ` pt ON pt.id = o.price_tier_id` +
+		` LEFT JOIN ` + checkInsTable + ` ci ON ci.order_id = o.id` +
+		` WHERE (o.status = $1 OR $1 = '') AND (o.user_id = $2 OR $2 IS NULL)` +
+		` ORDER BY o.id DESC LIMIT $3 OFFSET $4`
+
+	args := []any{filter.Status, filter.BuyerID, filter.Limit, filter.Offset}
+
+	queryCtx, cancel := context.WithTimeout(ctx, or.QueryTimeout)
+	defer cancel()
+
+	tx, err := beginScopedTx(queryCtx, or.DB)
+	if err != nil {
+		return nil, Pagination{}, err
+	}
+	defer tx.Rollback(queryCtx)
+
+	rows, err := tx.Query(queryCtx, query, args...)
+	if err != nil {
+		return nil, Pagination{}, fmt.Errorf("failed to select orders from database: %w", err)
+	}
+
+	defer rows.Close()
+
+	totalRecords := 0
+	orders := []tixer.Order{}
+
+	for rows.Next() {
+		var order tixer.Order
+
+		err := rows.Scan(
+			&totalRecords,
+			&order.ID,
+			&order.PublicID,
+			&order.BuyerID,
+			&order.TicketID,
+			&order.TicketPublicID,
+			&order.TenantID,
+			&order.PromoCodeID,
+			&order.PromoCode,
+			&order.PriceTierID,
+			&order.PriceTier,
+			&order.Subtotal.Amount,
+			&order.Discount.Amount,
+			&order.Total.Amount,
+			&order.Subtotal.Currency,
+			&order.Status,
+			&order.Version,
+			&order.ExpiresAt,
+			&order.CreatedAt,
+			&order.UpdatedAt,
+			&order.TransferredAt,
+			&order.CheckedInAt,
+		)
+		if err != nil {
+			return nil, Pagination{}, fmt.Errorf("failed to scan row result: %w", err)
+		}
+
+		setOrderCurrency(&order)
+		orders = append(orders, order)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, Pagination{}, fmt.Errorf("failed to iterate over rows result: %w", err)
+	}
+
+	if err := tx.Commit(queryCtx); err != nil {
+		return nil, Pagination{}, fmt.Errorf("failed to commit orders transaction: %w", err)
+	}
+
+	pagination := calculatePagination(totalRecords, filter.Offset/filter.Limit+1, filter.Limit)
+
+	return orders, pagination, nil
+}
+
+// ExpireOverdue marks up to limit pending or paying orders whose expiry time has passed as expired,
+// gives their ticket units and promo code redemptions back and returns the expired orders.
+// The units are offered to the waitlists of their tickets first.
+// Rows locked by another transaction are skipped, so several instances can run it concurrently.
+func (or *OrderRepository) ExpireOverdue(ctx context.Context, limit int) ([]tixer.Order, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, or.QueryTimeout)
+	defer cancel()
+
+	tx, err := beginScopedTx(queryCtx, or.DB)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(queryCtx)
+
+	overdueQuery := `SELECT id, ticket_id, promo_code_id FROM ` + ordersTable +
+		` WHERE status IN ($1, $2) AND expires_at <= NOW() ORDER BY expires_at LIMIT $3 FOR UPDATE SKIP LOCKED`
+
+	rows, err := tx.Query(queryCtx, overdueQuery, tixer.OrderPending, tixer.OrderPaying, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select overdue orders from database: %w", err)
+	}
+
+	var orderIDs, ticketIDs, promoCodeIDs []int64
+	for rows.Next() {
+		var orderID, ticketID int64
+		var promoCodeID *int64
+		if err := rows.Scan(&orderID, &ticketID, &promoCodeID); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan row result: %w", err)
+		}
+
+		orderIDs = append(orderIDs, orderID)
+		ticketIDs = append(ticketIDs, ticketID)
+		if promoCodeID != nil {
+			promoCodeIDs = append(promoCodeIDs, *promoCodeID)
+		}
+	}
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over rows result: %w", err)
+	}
+
+	if len(orderIDs) == 0 {
+		return []tixer.Order{}, nil
+	}
+
+	// The tickets and promo codes are updated in no particular order below, so they are locked
+	// in ID order first, like every other path that updates several of them in one transaction.
+	if err := lockTickets(queryCtx, tx, ticketIDs); err != nil {
+		return nil, err
+	}
+
+	promoCodesQuery := `SELECT id FROM ` + promoCodesTable + ` WHERE id = ANY($1) ORDER BY id FOR UPDATE`
+
+	if _, err := tx.Exec(queryCtx, promoCodesQuery, promoCodeIDs); err != nil {
+		return nil, fmt.Errorf("failed to lock promo codes in database: %w", err)
+	}
+
+	query := `WITH expired AS (` +
+		` UPDATE ` + ordersTable + ` SET status = $1, version = version + 1, updated_at = NOW()` +
+		` WHERE id = ANY($2)` +
+		` RETURNING id, public_id, user_id, ticket_id, promo_code_id, price_tier_id, subtotal, discount, total, currency, status, version, expires_at, created_at, updated_at, transferred_at),` +
+		` released AS (UPDATE ` + ticketsTable + ` t SET sold = t.sold - r.units` +
+		` FROM (SELECT ticket_id, count(*) AS units FROM expired GROUP BY ticket_id) r WHERE t.id = r.ticket_id),` +
+		` unredeemed AS (UPDATE ` + promoCodesTable + ` p SET redemptions = p.redemptions - r.redemptions` +
+		` FROM (SELECT promo_code_id, count(*) AS redemptions FROM expired WHERE promo_code_id IS NOT NULL GROUP BY promo_code_id) r` +
+		` WHERE p.id = r.promo_code_id)` +
+		` SELECT e.id, e.public_id, e.user_id, e.ticket_id, t.public_id, t.tenant_id, e.promo_code_id, pc.code, e.price_tier_id, pt.name, e.subtotal, e.discount, e.total, e.currency, e.status, e.version, e.expires_at, e.created_at, e.updated_at, e.transferred_at` +
+		` FROM expired e JOIN ` + ticketsTable + ` t ON t.id = e.ticket_id` +
+		` LEFT JOIN ` + promoCodesTable + ` pc ON pc.id = e.promo_code_id` +
+		` LEFT JOIN ` + ticketPriceTiersTable + ` pt ON pt.id = e.price_tier_id`
+
+	args := []any{tixer.OrderExpired, orderIDs}
+
+	rows, err = tx.Query(queryCtx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expire orders in database: %w", err)
+	}
+
+	orders := []tixer.Order{}
+
+	for rows.Next() {
+		var order tixer.Order
+
+		err := rows.Scan(
+			&order.ID,
+			&order.PublicID,
+			&order.BuyerID,
+			&order.TicketID,
+			&order.TicketPublicID,
+			&order.TenantID,
+			&order.PromoCodeID,
+			&order.PromoCode,
+			&order.PriceTierID,
+			&order.PriceTier,
+			&order.Subtotal.Amount,
+			&order.Discount.Amount,
+			&order.Total.Amount,
+			&order.Subtotal.Currency,
+			&order.Status,
+			&order.Version,
+			&order.ExpiresAt,
+			&order.CreatedAt,
+			&order.UpdatedAt,
+			&order.TransferredAt,
+		)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan row result: %w", err)
+		}
+
+		setOrderCurrency(&order)
+		orders = append(orders, order)
+	}
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over rows result: %w", err)
+	}
+
+	units := make(map[int64]int)
+	for _, order := range orders {
+		units[order.TicketID]++
+	}
+	for ticketID, n := range units {
+		if err := offerReleasedUnits(queryCtx, tx, ticketID, n, or.ClaimWindow); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(queryCtx); err != nil {
+		return nil, fmt.Errorf("failed to commit order expiry transaction: %w", err)
+	}
+
+	return orders, nil
+}
+
+// Cancel cancels a pending order and gives its ticket unit back,
+// offering it to the waitlist of the ticket first. The redemption of its promo code is given back too.
+// It returns ErrDbEditConflict if the order was changed in the meantime.
+func (or *OrderRepository) Cancel(ctx context.Context, order *tixer.Order) error {
+	queryCtx, cancel := context.WithTimeout(ctx, or.QueryTimeout)
+	defer cancel()
+
+	tx, err := beginScopedTx(queryCtx, or.DB)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(queryCtx)
+
+	query := `WITH cancelled AS (UPDATE ` + ordersTable +
+		` SET status = $1, version = version + 1, updated_at = NOW()` +
+		` WHERE id = $2 AND version = $3 AND status = $4 RETURNING ticket_id, promo_code_id, status, version),` +
+		` released AS (UPDATE ` + ticketsTable + ` t SET sold = t.sold - 1 FROM cancelled c WHERE t.id = c.ticket_id),` +
+		` unredeemed AS (UPDATE ` + promoCodesTable + ` p SET redemptions = p.redemptions - 1 FROM cancelled c WHERE p.id = c.promo_code_id)` +
+		` SELECT status, version FROM cancelled`
+
+	args := []any{tixer.OrderCancelled, order.ID, order.Version, tixer.OrderPending}
+
+	if err := tx.QueryRow(queryCtx, query, args...).Scan(&order.Status, &order.Version); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return ErrDbEditConflict
+		default:
+			return fmt.Errorf("failed to cancel order in database: %w", err)
+		}
+	}
+
+	if err := offerReleasedUnits(queryCtx, tx, order.TicketID, 1, or.ClaimWindow); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(queryCtx); err != nil {
+		return fmt.Errorf("failed to commit order cancellation transaction: %w", err)
+	}
+
+	return nil
+}
+
+// setOrderCurrency sets the currency of all the amounts of an order from the subtotal,
+// the only amount the currency column is scanned into.
+func setOrderCurrency(order *tixer.Order) {
+	order.Discount.Currency = order.Subtotal.Currency
+	order.Total.Currency = order.Subtotal.Currency
+}
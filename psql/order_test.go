@@ -0,0 +1,92 @@
+package psql_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	tixer "github.com/mroobert/monorepo-tixer"
+	"github.com/mroobert/monorepo-tixer/psql"
+)
+
+// TestOrderInsertNeverOversells places ten times more concurrent orders than the capacity
+// of a ticket and checks that exactly the capacity is sold.
+func TestOrderInsertNeverOversells(t *testing.T) {
+	const capacity = 50
+
+	pool := newTestPool(t)
+	ctx := context.Background()
+
+	tenantDB, err := psql.NewTenantRepository(pool, testQueryTimeout).Insert(ctx, tixer.Tenant{
+		PublicID: newPublicID(t),
+		Name:     "Concurrency test organizer",
+	})
+	if err != nil {
+		t.Fatalf("inserting tenant: %v", err)
+	}
+	ctx = tixer.NewTenantContext(ctx, tenantDB.ID)
+
+	tickets := psql.NewTicketRepository(pool, testQueryTimeout)
+	ticketDB, err := tickets.Insert(ctx, tixer.Ticket{
+		PublicID: newPublicID(t),
+		Title:    "Concurrency test ticket",
+		Status:   tixer.TicketPublished,
+		Price:    tixer.Money{Amount: 1000, Currency: "EUR"},
+		Capacity: capacity,
+	})
+	if err != nil {
+		t.Fatalf("inserting ticket: %v", err)
+	}
+
+	orders := psql.NewOrderRepository(pool, testQueryTimeout, 15*time.Minute, 30*time.Minute)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	placed, unavailable := 0, 0
+
+	for range capacity * 10 {
+		publicID := newPublicID(t)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			_, err := orders.Insert(ctx, tixer.Order{
+				PublicID:       publicID,
+				TicketPublicID: ticketDB.PublicID,
+				Subtotal:       ticketDB.Price,
+				Discount:       tixer.Money{Currency: ticketDB.Price.Currency},
+				Total:          ticketDB.Price,
+			})
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			switch err {
+			case nil:
+				placed++
+			case psql.ErrDbTicketUnavailable:
+				unavailable++
+			default:
+				t.Errorf("inserting order: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if placed != capacity {
+		t.Errorf("placed %d orders, want %d", placed, capacity)
+	}
+	if unavailable != capacity*9 {
+		t.Errorf("refused %d orders as unavailable, want %d", unavailable, capacity*9)
+	}
+
+	ticketDB, err = tickets.SelectOne(ctx, ticketDB.PublicID)
+	if err != nil {
+		t.Fatalf("selecting ticket: %v", err)
+	}
+	if ticketDB.Sold != capacity {
+		t.Errorf("sold = %d, want %d", ticketDB.Sold, capacity)
+	}
+}
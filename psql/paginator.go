@@ -1,7 +1,7 @@
 package psql
 
 import (
-	"math"
+	tixer "github.com/mroobert/monorepo-tixer"
 )
 
 // Paginator is used to paginate records.
@@ -27,26 +27,9 @@ func (p Paginator) Offset() int {
 	return (p.page - 1) * p.pageSize
 }
 
-// Pagination represents the pagination information.
-type Pagination struct {
-	CurrentPage  int `json:"currentPage,omitempty"`
-	PageSize     int `json:"pageSize,omitempty"`
-	FirstPage    int `json:"firstPage,omitempty"`
-	LastPage     int `json:"lastPage,omitempty"`
-	TotalRecords int `json:"totalRecords,omitempty"`
-}
+// Pagination represents the pagination information. It is shared with the stores defined by the tixer package.
+type Pagination = tixer.Pagination
 
 func calculatePagination(totalRecords, page, pageSize int) Pagination {
-	if totalRecords == 0 {
-
-		return Pagination{}
-	}
-
-	return Pagination{
-		CurrentPage:  page,
-		PageSize:     pageSize,
-		FirstPage:    1,
-		LastPage:     int(math.Ceil(float64(totalRecords) / float64(pageSize))),
-		TotalRecords: totalRecords,
-	}
+	return tixer.NewPagination(totalRecords, page, pageSize)
 }
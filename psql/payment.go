@@ -0,0 +1,155 @@
+package psql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	tixer "github.com/mroobert/monorepo-tixer"
+)
+
+var (
+	ErrDbOrderNotPayable = errors.New("db order not payable")
+)
+
+const paymentsTable = "payments"
+
+// PaymentRepository persists payments in the database.
+type PaymentRepository struct {
+	DB           *pgxpool.Pool
+	QueryTimeout time.Duration
+}
+
+func NewPaymentRepository(db *pgxpool.Pool, queryTimeout time.Duration) *PaymentRepository {
+	return &PaymentRepository{
+		DB:           db,
+		QueryTimeout: queryTimeout,
+	}
+}
+
+// StartPayment moves a pending order whose expiry time has not passed to paying, before the buyer
+// is charged. The guarded update lets a single payment of the order through at a time, so the
+// concurrent payments get ErrDbOrderNotPayable without charging the buyer a second time.
+// It returns the number of declined payments of the order, which numbers its charge attempts:
+// an attempt whose outcome is unknown, e.g. because the gateway timed out, is retried with the same number.
+func (pr *PaymentRepository) StartPayment(ctx context.Context, order *tixer.Order) (int, error) {
+	query := `UPDATE ` + ordersTable +
+		` SET status = $1, version = version + 1, updated_at = NOW()` +
+		` WHERE id = $2 AND status = $3 AND expires_at > NOW()` +
+		` RETURNING status, version, (SELECT count(*) FROM ` + paymentsTable + ` WHERE order_id = $2 AND status = $4)`
+
+	args := []any{tixer.OrderPaying, order.ID, tixer.OrderPending, tixer.PaymentDeclined}
+
+	queryCtx, cancel := context.WithTimeout(ctx, pr.QueryTimeout)
+	defer cancel()
+
+	var attempt int
+	if err := pr.DB.QueryRow(queryCtx, query, args...).Scan(&order.Status, &order.Version, &attempt); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return 0, ErrDbOrderNotPayable
+		default:
+			return 0, fmt.Errorf("failed to mark order as paying in database: %w", err)
+		}
+	}
+
+	return attempt, nil
+}
+
+// Insert records a payment attempt for the paying order referenced by payment.OrderID.
+// A succeeded payment marks the order as paid in the same transaction, while any other
+// outcome gives the order back to pending, so the buyer can try again.
+// If the order expired while it was charged, the payment is still recorded,
+// because the money has already moved, and ErrDbOrderNotPayable is returned together with it.
+func (pr *PaymentRepository) Insert(ctx context.Context, payment tixer.Payment) (tixer.Payment, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, pr.QueryTimeout)
+	defer cancel()
+
+	tx, err := pr.DB.Begin(queryCtx)
+	if err != nil {
+		return tixer.Payment{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(queryCtx)
+
+	settleQuery := `UPDATE ` + ordersTable +
+		` SET status = $1, version = version + 1, updated_at = NOW()` +
+		` WHERE id = $2 AND status = $3 RETURNING version`
+
+	status := tixer.OrderPending
+	if payment.Status == tixer.PaymentSucceeded {
+		status = tixer.OrderPaid
+	}
+
+	var settleErr error
+	var version int32
+	err = tx.QueryRow(queryCtx, settleQuery, status, payment.OrderID, tixer.OrderPaying).Scan(&version)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows) && payment.Status == tixer.PaymentSucceeded:
+		settleErr = ErrDbOrderNotPayable
+	case errors.Is(err, pgx.ErrNoRows):
+		// the order expired while it was charged, there is nothing to give back
+	case err != nil:
+		return tixer.Payment{}, fmt.Errorf("failed to settle order in database: %w", err)
+	}
+
+	insertQuery := `INSERT INTO ` + paymentsTable +
+		` (public_id, order_id, amount, currency, status, gateway_reference) VALUES ($1, $2, $3, $4, $5, $6)
+        RETURNING id, public_id, order_id, amount, currency, status, gateway_reference, created_at`
+
+	args := []any{payment.PublicID, payment.OrderID, payment.Amount.Amount, payment.Amount.Currency, payment.Status, payment.GatewayReference}
+
+	createdPayment := tixer.Payment{OrderPublicID: payment.OrderPublicID}
+	if err := tx.QueryRow(queryCtx, insertQuery, args...).Scan(
+		&createdPayment.ID,
+		&createdPayment.PublicID,
+		&createdPayment.OrderID,
+		&createdPayment.Amount.Amount,
+		&createdPayment.Amount.Currency,
+		&createdPayment.Status,
+		&createdPayment.GatewayReference,
+		&createdPayment.CreatedAt,
+	); err != nil {
+		return tixer.Payment{}, fmt.Errorf("failed to insert payment in database: %w", err)
+	}
+
+	if err := tx.Commit(queryCtx); err != nil {
+		return tixer.Payment{}, fmt.Errorf("failed to commit payment transaction: %w", err)
+	}
+
+	return createdPayment, settleErr
+}
+
+// SelectSucceeded reads the succeeded payment of an order from the database.
+func (pr *PaymentRepository) SelectSucceeded(ctx context.Context, orderID int64) (tixer.Payment, error) {
+	query := `SELECT p.id, p.public_id, p.order_id, o.public_id, p.amount, p.currency, p.status, p.gateway_reference, p.created_at` +
+		` FROM ` + paymentsTable + ` p JOIN ` + ordersTable + ` o ON o.id = p.order_id` +
+		` WHERE p.order_id = $1 AND p.status = $2 ORDER BY p.id DESC LIMIT 1`
+
+	queryCtx, cancel := context.WithTimeout(ctx, pr.QueryTimeout)
+	defer cancel()
+
+	var payment tixer.Payment
+	if err := pr.DB.QueryRow(queryCtx, query, orderID, tixer.PaymentSucceeded).Scan(
+		&payment.ID,
+		&payment.PublicID,
+		&payment.OrderID,
+		&payment.OrderPublicID,
+		&payment.Amount.Amount,
+		&payment.Amount.Currency,
+		&payment.Status,
+		&payment.GatewayReference,
+		&payment.CreatedAt,
+	); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return tixer.Payment{}, ErrDbRecordNotFound
+		default:
+			return tixer.Payment{}, fmt.Errorf("failed to select payment from database: %w", err)
+		}
+	}
+
+	return payment, nil
+}
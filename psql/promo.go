@@ -0,0 +1,299 @@
+package psql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	tixer "github.com/mroobert/monorepo-tixer"
+)
+
+var (
+	ErrDbDuplicatePromoCode = errors.New("db duplicate promo code")
+	ErrDbPromoCodeExhausted = errors.New("db promo code exhausted")
+)
+
+const promoCodesTable = "promo_codes"
+
+// promoCodeColumns lists the columns read by scanPromoCode,
+// for a promo_codes table aliased p joined to events e and tickets t.
+const promoCodeColumns = `p.id, p.public_id, p.code, p.discount_type, p.percent_off, p.amount_off, p.currency, p.scope,` +
+	` p.event_id, e.public_id, p.ticket_id, t.public_id, p.valid_from, p.valid_until,` +
+	` p.max_redemptions, p.max_redemptions_per_user, p.redemptions, p.created_at`
+
+// PromoCodeRepository persists promo codes in the database.
+type PromoCodeRepository struct {
+	DB           *pgxpool.Pool
+	QueryTimeout time.Duration
+}
+
+func NewPromoCodeRepository(db *pgxpool.Pool, queryTimeout time.Duration) *PromoCodeRepository {
+	return &PromoCodeRepository{
+		DB:           db,
+		QueryTimeout: queryTimeout,
+	}
+}
+
+// Insert inserts a new promo code in the database.
+// It returns ErrDbDuplicatePromoCode if the code is already taken.
+func (pr *PromoCodeRepository) Insert(ctx context.Context, promo tixer.PromoCode) (tixer.PromoCode, error) {
+	query := `WITH p AS (INSERT INTO ` + promoCodesTable +
+		` (public_id, code, discount_type, percent_off, amount_off, currency, scope, event_id, ticket_id, valid_from, valid_until, max_redemptions, max_redemptions_per_user)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13) RETURNING *)` +
+		` SELECT ` + promoCodeColumns +
+		` FROM p LEFT JOIN ` + eventsTable + ` e ON e.id = p.event_id` +
+		` LEFT JOIN ` + ticketsTable + ` t ON t.id = p.ticket_id`
+
+	var currency *tixer.Currency
+	if promo.DiscountType == tixer.DiscountFixed {
+		currency = &promo.AmountOff.Currency
+	}
+
+	args := []any{promo.PublicID, promo.Code, promo.DiscountType, promo.PercentOff, promo.AmountOff.Amount, currency, promo.Scope,
+		promo.EventID, promo.TicketID, promo.ValidFrom, promo.ValidUntil, promo.MaxRedemptions, promo.MaxRedemptionsPerUser}
+
+	queryCtx, cancel := context.WithTimeout(ctx, pr.QueryTimeout)
+	defer cancel()
+
+	tx, err := beginScopedTx(queryCtx, pr.DB)
+	if err != nil {
+		return tixer.PromoCode{}, err
+	}
+	defer tx.Rollback(queryCtx)
+
+	createdPromo, err := scanPromoCode(tx.QueryRow(queryCtx, query, args...))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		switch {
+		case errors.As(err, &pgErr) && pgErr.ConstraintName == "promo_codes_code_key":
+			return tixer.PromoCode{}, ErrDbDuplicatePromoCode
+		default:
+			return tixer.PromoCode{}, fmt.Errorf("failed to insert promo code in database: %w", err)
+		}
+	}
+
+	if err := tx.Commit(queryCtx); err != nil {
+		return tixer.PromoCode{}, fmt.Errorf("failed to commit promo code transaction: %w", err)
+	}
+
+	return createdPromo, nil
+}
+
+// SelectOne reads a promo code from the database.
+func (pr *PromoCodeRepository) SelectOne(ctx context.Context, id tixer.PublicID) (tixer.PromoCode, error) {
+	return pr.selectOne(ctx, `p.public_id = $1`, id)
+}
+
+// SelectByCode reads the promo code with the given code from the database.
+func (pr *PromoCodeRepository) SelectByCode(ctx context.Context, code string) (tixer.PromoCode, error) {
+	return pr.selectOne(ctx, `p.code = $1`, code)
+}
+
+func (pr *PromoCodeRepository) selectOne(ctx context.Context, where string, arg any) (tixer.PromoCode, error) {
+	query := `SELECT ` + promoCodeColumns +
+		` FROM ` + promoCodesTable + ` p LEFT JOIN ` + eventsTable + ` e ON e.id = p.event_id` +
+		` LEFT JOIN ` + ticketsTable + ` t ON t.id = p.ticket_id` +
+		` WHERE ` + where
+
+	queryCtx, cancel := context.WithTimeout(ctx, pr.QueryTimeout)
+	defer cancel()
+
+	tx, err := beginScopedTx(queryCtx, pr.DB)
+	if err != nil {
+		return tixer.PromoCode{}, err
+	}
+	defer tx.Rollback(queryCtx)
+
+	promo, err := scanPromoCode(tx.QueryRow(queryCtx, query, arg))
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return tixer.PromoCode{}, ErrDbRecordNotFound
+		default:
+			return tixer.PromoCode{}, fmt.Errorf("failed to select promo code from database: %w", err)
+		}
+	}
+
+	if err := tx.Commit(queryCtx); err != nil {
+		return tixer.PromoCode{}, fmt.Errorf("failed to commit promo code transaction: %w", err)
+	}
+
+	return promo, nil
+}
+
+type PromoCodeFilter struct {
+	Limit  int
+	Offset int
+}
+
+// SelectMultiple reads promo codes from the database, the newest first.
+func (pr *PromoCodeRepository) SelectMultiple(ctx context.Context, filter PromoCodeFilter) ([]tixer.PromoCode, Pagination, error) {
+	query := `SELECT count(*) OVER(), ` + promoCodeColumns +
+		` FROM ` + promoCodesTable + ` p LEFT JOIN ` + eventsTable + ` e ON e.id = p.event_id` +
+		` LEFT JOIN ` + ticketsTable + ` t ON t.id = p.ticket_id` +
+		` ORDER BY p.id DESC LIMIT $1 OFFSET $2`
+
+	queryCtx, cancel := context.WithTimeout(ctx, pr.QueryTimeout)
+	defer cancel()
+
+	tx, err := beginScopedTx(queryCtx, pr.DB)
+	if err != nil {
+		return nil, Pagination{}, err
+	}
+	defer tx.Rollback(queryCtx)
+
+	rows, err := tx.Query(queryCtx, query, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, Pagination{}, fmt.Errorf("failed to select promo codes from database: %w", err)
+	}
+
+	defer rows.Close()
+
+	totalRecords := 0
+	promos := []tixer.PromoCode{}
+
+	for rows.Next() {
+		var promo tixer.PromoCode
+		var currency *tixer.Currency
+
+		err := rows.Scan(
+			&totalRecords,
+			&promo.ID,
+			&promo.PublicID,
+			&promo.Code,
+			&promo.DiscountType,
+			&promo.PercentOff,
+			&promo.AmountOff.Amount,
+			&currency,
+			&promo.Scope,
+			&promo.EventID,
+			&promo.EventPublicID,
+			&promo.TicketID,
+			&promo.TicketPublicID,
+			&promo.ValidFrom,
+			&promo.ValidUntil,
+			&promo.MaxRedemptions,
+			&promo.MaxRedemptionsPerUser,
+			&promo.Redemptions,
+			&promo.CreatedAt,
+		)
+		if err != nil {
+			return nil, Pagination{}, fmt.Errorf("failed to scan row result: %w", err)
+		}
+		if currency != nil {
+			promo.AmountOff.Currency = *currency
+		}
+
+		promos = append(promos, promo)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, Pagination{}, fmt.Errorf("failed to iterate over rows result: %w", err)
+	}
+
+	if err := tx.Commit(queryCtx); err != nil {
+		return nil, Pagination{}, fmt.Errorf("failed to commit promo codes transaction: %w", err)
+	}
+
+	pagination := calculatePagination(totalRecords, filter.Offset/filter.Limit+1, filter.Limit)
+
+	return promos, pagination, nil
+}
+
+// Delete deletes a promo code from the database.
+// It returns ErrDbRecordInUse if orders redeemed the code.
+func (pr *PromoCodeRepository) Delete(ctx context.Context, id tixer.PublicID) error {
+	query := `DELETE FROM ` + promoCodesTable + ` WHERE public_id = $1`
+
+	queryCtx, cancel := context.WithTimeout(ctx, pr.QueryTimeout)
+	defer cancel()
+
+	res, err := pr.DB.Exec(queryCtx, query, id)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		switch {
+		case errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation:
+			return ErrDbRecordInUse
+		default:
+			return fmt.Errorf("failed to delete promo code from database: %w", err)
+		}
+	}
+
+	if res.RowsAffected() == 0 {
+		return ErrDbRecordNotFound
+	}
+
+	return nil
+}
+
+// redeemPromoCode counts a redemption of the promo code by the buyer. The guarded increment
+// locks the promo code row until the transaction ends, so concurrent checkouts with the same code
+// are serialized and neither the global nor the per user cap can be exceeded.
+// It returns ErrDbPromoCodeExhausted if a cap was reached.
+// It must run in the transaction that inserts the order redeeming the code.
+func redeemPromoCode(ctx context.Context, tx pgx.Tx, promoCodeID int64, buyerID *int64) error {
+	redeemQuery := `UPDATE ` + promoCodesTable + ` SET redemptions = redemptions + 1` +
+		` WHERE id = $1 AND (max_redemptions IS NULL OR redemptions < max_redemptions) RETURNING max_redemptions_per_user`
+
+	var maxPerUser *int32
+	if err := tx.QueryRow(ctx, redeemQuery, promoCodeID).Scan(&maxPerUser); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return ErrDbPromoCodeExhausted
+		default:
+			return fmt.Errorf("failed to redeem promo code in database: %w", err)
+		}
+	}
+
+	if maxPerUser == nil || buyerID == nil {
+		return nil
+	}
+
+	countQuery := `SELECT count(*) FROM ` + ordersTable +
+		` WHERE promo_code_id = $1 AND user_id = $2 AND status NOT IN ($3, $4)`
+
+	var redeemed int32
+	if err := tx.QueryRow(ctx, countQuery, promoCodeID, *buyerID, tixer.OrderCancelled, tixer.OrderExpired).Scan(&redeemed); err != nil {
+		return fmt.Errorf("failed to count user redemptions in database: %w", err)
+	}
+	if redeemed >= *maxPerUser {
+		return ErrDbPromoCodeExhausted
+	}
+
+	return nil
+}
+
+func scanPromoCode(row pgx.Row) (tixer.PromoCode, error) {
+	var promo tixer.PromoCode
+	var currency *tixer.Currency
+
+	err := row.Scan(
+		&promo.ID,
+		&promo.PublicID,
+		&promo.Code,
+		&promo.DiscountType,
+		&promo.PercentOff,
+		&promo.AmountOff.Amount,
+		&currency,
+		&promo.Scope,
+		&promo.EventID,
+		&promo.EventPublicID,
+		&promo.TicketID,
+		&promo.TicketPublicID,
+		&promo.ValidFrom,
+		&promo.ValidUntil,
+		&promo.MaxRedemptions,
+		&promo.MaxRedemptionsPerUser,
+		&promo.Redemptions,
+		&promo.CreatedAt,
+	)
+	if currency != nil {
+		promo.AmountOff.Currency = *currency
+	}
+
+	return promo, err
+}
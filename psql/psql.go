@@ -8,6 +8,12 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// PostgreSQL error codes handled by the repositories.
+const (
+	pgForeignKeyViolation = "23503"
+	pgUniqueViolation     = "23505"
+)
+
 // DbConfig represents the configuration details for the database connection.
 type DbConfig struct {
 	DSN             string        // data source name
@@ -15,6 +21,7 @@ type DbConfig struct {
 	MinConns        int32         // minimum size of the pool
 	MaxConnIdleTime time.Duration // sets the maximum length of time that a connection can be idle for before it is marked as expired
 	QueryTimeout    time.Duration // sets the maximum time a query can run before it is canceled
+	AutoMigrate     bool          // apply the pending migrations when the application starts
 }
 
 // NewPool creates a new connection pool to the database.
@@ -0,0 +1,91 @@
+package psql_test
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	nanoid "github.com/matoous/go-nanoid/v2"
+	tixer "github.com/mroobert/monorepo-tixer"
+	"github.com/mroobert/monorepo-tixer/migrations"
+	"github.com/mroobert/monorepo-tixer/psql"
+)
+
+// testQueryTimeout is generous, the queries of the concurrency tests wait for a connection of the pool.
+const testQueryTimeout = 30 * time.Second
+
+// newTestPool connects to the database named by TEST_DB_DSN, given as a URL, and applies the migrations
+// in a schema of the test's own, which is dropped when the test ends. The tests that need it are skipped
+// when TEST_DB_DSN is not set.
+func newTestPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DB_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DB_DSN is not set")
+	}
+	ctx := context.Background()
+
+	schema := "test_" + string(newPublicID(t))
+
+	conn, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		t.Fatalf("connecting to the test database: %v", err)
+	}
+	if _, err := conn.Exec(ctx, "CREATE SCHEMA "+schema); err != nil {
+		t.Fatalf("creating the test schema: %v", err)
+	}
+	t.Cleanup(func() {
+		if _, err := conn.Exec(ctx, "DROP SCHEMA "+schema+" CASCADE"); err != nil {
+			t.Errorf("dropping the test schema: %v", err)
+		}
+		conn.Close(ctx)
+	})
+
+	schemaDSN, err := url.Parse(dsn)
+	if err != nil {
+		t.Fatalf("parsing TEST_DB_DSN: %v", err)
+	}
+	query := schemaDSN.Query()
+	query.Set("search_path", schema)
+	schemaDSN.RawQuery = query.Encode()
+
+	pool, err := psql.NewPool(psql.DbConfig{
+		DSN:             schemaDSN.String(),
+		MaxOpenConns:    20,
+		MinConns:        1,
+		MaxConnIdleTime: time.Minute,
+		QueryTimeout:    testQueryTimeout,
+	})
+	if err != nil {
+		t.Fatalf("connecting to the test schema: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	migrator, err := psql.NewMigrator(pool, migrations.Files)
+	if err != nil {
+		t.Fatalf("reading the migrations: %v", err)
+	}
+
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatalf("migrating the test schema: %v", err)
+	}
+
+	return pool
+}
+
+// newPublicID generates a public ID for the rows created by a test.
+func newPublicID(t *testing.T) tixer.PublicID {
+	t.Helper()
+
+	id, err := nanoid.Generate(tixer.PublicIDAlphabet, tixer.PublicIDLength)
+	if err != nil {
+		t.Fatalf("generating public ID: %v", err)
+	}
+
+	return tixer.PublicID(id)
+}
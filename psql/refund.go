@@ -0,0 +1,191 @@
+package psql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	tixer "github.com/mroobert/monorepo-tixer"
+)
+
+const refundsTable = "refunds"
+
+// RefundRepository persists refunds in the database.
+type RefundRepository struct {
+	DB           *pgxpool.Pool
+	QueryTimeout time.Duration
+	ClaimWindow  time.Duration // how long a unit released to the waitlist is held for the next user
+}
+
+func NewRefundRepository(db *pgxpool.Pool, queryTimeout, claimWindow time.Duration) *RefundRepository {
+	return &RefundRepository{
+		DB:           db,
+		QueryTimeout: queryTimeout,
+		ClaimWindow:  claimWindow,
+	}
+}
+
+// Insert records a pending refund and marks the refunded order as refunding in the same transaction,
+// before the money is given back. The order must still be paid, at the version the caller read and not
+// checked in, otherwise ErrDbEditConflict is returned; a refunding order cannot be changed by anything but its refund.
+func (rr *RefundRepository) Insert(ctx context.Context, refund tixer.Refund, order *tixer.Order) (tixer.Refund, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, rr.QueryTimeout)
+	defer cancel()
+
+	tx, err := rr.DB.Begin(queryCtx)
+	if err != nil {
+		return tixer.Refund{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(queryCtx)
+
+	orderQuery := `UPDATE ` + ordersTable +
+		` SET status = $1, version = version + 1, updated_at = NOW()` +
+		` WHERE id = $2 AND version = $3 AND status = $4` +
+		` AND NOT EXISTS (SELECT 1 FROM ` + checkInsTable + ` WHERE order_id = $2) RETURNING status, version`
+
+	args := []any{tixer.OrderRefunding, order.ID, order.Version, tixer.OrderPaid}
+
+	if err := tx.QueryRow(queryCtx, orderQuery, args...).Scan(&order.Status, &order.Version); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return tixer.Refund{}, ErrDbEditConflict
+		default:
+			return tixer.Refund{}, fmt.Errorf("failed to mark order as refunding in database: %w", err)
+		}
+	}
+
+	insertQuery := `INSERT INTO ` + refundsTable +
+		` (public_id, order_id, payment_id, amount, currency, reason, status) VALUES ($1, $2, $3, $4, $5, $6, $7)
+        RETURNING id, public_id, order_id, payment_id, amount, currency, reason, status, gateway_reference, created_at`
+
+	args = []any{refund.PublicID, order.ID, refund.PaymentID, refund.Amount.Amount, refund.Amount.Currency, refund.Reason, tixer.RefundPending}
+
+	createdRefund := tixer.Refund{OrderPublicID: order.PublicID}
+	if err := tx.QueryRow(queryCtx, insertQuery, args...).Scan(
+		&createdRefund.ID,
+		&createdRefund.PublicID,
+		&createdRefund.OrderID,
+		&createdRefund.PaymentID,
+		&createdRefund.Amount.Amount,
+		&createdRefund.Amount.Currency,
+		&createdRefund.Reason,
+		&createdRefund.Status,
+		&createdRefund.GatewayReference,
+		&createdRefund.CreatedAt,
+	); err != nil {
+		return tixer.Refund{}, fmt.Errorf("failed to insert refund in database: %w", err)
+	}
+
+	if err := tx.Commit(queryCtx); err != nil {
+		return tixer.Refund{}, fmt.Errorf("failed to commit refund transaction: %w", err)
+	}
+
+	return createdRefund, nil
+}
+
+// Complete records that the money of a pending refund was given back: the refund succeeds and its
+// order is marked as refunded in the same transaction, which puts its ticket unit back on sale
+// or offers it to the waitlist.
+func (rr *RefundRepository) Complete(ctx context.Context, refund *tixer.Refund, order *tixer.Order) error {
+	queryCtx, cancel := context.WithTimeout(ctx, rr.QueryTimeout)
+	defer cancel()
+
+	tx, err := beginScopedTx(queryCtx, rr.DB)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(queryCtx)
+
+	refundQuery := `UPDATE ` + refundsTable + ` SET status = $1, gateway_reference = $2` +
+		` WHERE id = $3 AND status = $4 RETURNING status, gateway_reference`
+
+	args := []any{tixer.RefundSucceeded, refund.GatewayReference, refund.ID, tixer.RefundPending}
+
+	if err := tx.QueryRow(queryCtx, refundQuery, args...).Scan(&refund.Status, &refund.GatewayReference); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return ErrDbEditConflict
+		default:
+			return fmt.Errorf("failed to mark refund as succeeded in database: %w", err)
+		}
+	}
+
+	orderQuery := `UPDATE ` + ordersTable +
+		` SET status = $1, version = version + 1, updated_at = NOW()` +
+		` WHERE id = $2 AND status = $3 RETURNING status, version`
+
+	args = []any{tixer.OrderRefunded, order.ID, tixer.OrderRefunding}
+
+	if err := tx.QueryRow(queryCtx, orderQuery, args...).Scan(&order.Status, &order.Version); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return ErrDbEditConflict
+		default:
+			return fmt.Errorf("failed to mark order as refunded in database: %w", err)
+		}
+	}
+
+	releaseQuery := `UPDATE ` + ticketsTable + ` SET sold = sold - 1 WHERE id = $1`
+
+	if _, err := tx.Exec(queryCtx, releaseQuery, order.TicketID); err != nil {
+		return fmt.Errorf("failed to give ticket unit back in database: %w", err)
+	}
+
+	if err := offerReleasedUnits(queryCtx, tx, order.TicketID, 1, rr.ClaimWindow); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(queryCtx); err != nil {
+		return fmt.Errorf("failed to commit refund transaction: %w", err)
+	}
+
+	return nil
+}
+
+// Abort removes a pending refund whose money could not be given back and
+// gives its order back to paid, so the refund can be requested again.
+func (rr *RefundRepository) Abort(ctx context.Context, refund tixer.Refund, order *tixer.Order) error {
+	queryCtx, cancel := context.WithTimeout(ctx, rr.QueryTimeout)
+	defer cancel()
+
+	tx, err := rr.DB.Begin(queryCtx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(queryCtx)
+
+	refundQuery := `DELETE FROM ` + refundsTable + ` WHERE id = $1 AND status = $2`
+
+	res, err := tx.Exec(queryCtx, refundQuery, refund.ID, tixer.RefundPending)
+	if err != nil {
+		return fmt.Errorf("failed to delete refund from database: %w", err)
+	}
+
+	if res.RowsAffected() == 0 {
+		return ErrDbEditConflict
+	}
+
+	orderQuery := `UPDATE ` + ordersTable +
+		` SET status = $1, version = version + 1, updated_at = NOW()` +
+		` WHERE id = $2 AND status = $3 RETURNING status, version`
+
+	args := []any{tixer.OrderPaid, order.ID, tixer.OrderRefunding}
+
+	if err := tx.QueryRow(queryCtx, orderQuery, args...).Scan(&order.Status, &order.Version); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return ErrDbEditConflict
+		default:
+			return fmt.Errorf("failed to mark order as paid in database: %w", err)
+		}
+	}
+
+	if err := tx.Commit(queryCtx); err != nil {
+		return fmt.Errorf("failed to commit refund transaction: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package psql_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	tixer "github.com/mroobert/monorepo-tixer"
+	"github.com/mroobert/monorepo-tixer/psql"
+)
+
+// TestRefundInsertRacesCheckIn refunds paid orders while their tickets are scanned at the gate
+// and checks that each order is either refunded or checked in, never both.
+func TestRefundInsertRacesCheckIn(t *testing.T) {
+	const rounds = 25
+
+	pool := newTestPool(t)
+	ctx := context.Background()
+
+	tenantDB, err := psql.NewTenantRepository(pool, testQueryTimeout).Insert(ctx, tixer.Tenant{
+		PublicID: newPublicID(t),
+		Name:     "Refund race test organizer",
+	})
+	if err != nil {
+		t.Fatalf("inserting tenant: %v", err)
+	}
+	ctx = tixer.NewTenantContext(ctx, tenantDB.ID)
+
+	staffDB, err := psql.NewUserRepository(pool, testQueryTimeout).Insert(ctx, tixer.User{
+		PublicID:     newPublicID(t),
+		Name:         "Gate staff",
+		Email:        "gate-" + string(newPublicID(t)) + "@example.com",
+		PasswordHash: []byte("not a hash"),
+	})
+	if err != nil {
+		t.Fatalf("inserting user: %v", err)
+	}
+
+	ticketDB, err := psql.NewTicketRepository(pool, testQueryTimeout).Insert(ctx, tixer.Ticket{
+		PublicID: newPublicID(t),
+		Title:    "Refund race test ticket",
+		Status:   tixer.TicketPublished,
+		Price:    tixer.Money{Amount: 1000, Currency: "EUR"},
+		Capacity: rounds,
+	})
+	if err != nil {
+		t.Fatalf("inserting ticket: %v", err)
+	}
+
+	orders := psql.NewOrderRepository(pool, testQueryTimeout, 15*time.Minute, 30*time.Minute)
+	payments := psql.NewPaymentRepository(pool, testQueryTimeout)
+	refunds := psql.NewRefundRepository(pool, testQueryTimeout, 30*time.Minute)
+	checkIns := psql.NewCheckInRepository(pool, testQueryTimeout)
+
+	for range rounds {
+		orderDB, err := orders.Insert(ctx, tixer.Order{
+			PublicID:       newPublicID(t),
+			TicketPublicID: ticketDB.PublicID,
+			Subtotal:       ticketDB.Price,
+			Discount:       tixer.Money{Currency: ticketDB.Price.Currency},
+			Total:          ticketDB.Price,
+		})
+		if err != nil {
+			t.Fatalf("inserting order: %v", err)
+		}
+
+		if _, err := payments.StartPayment(ctx, &orderDB); err != nil {
+			t.Fatalf("starting payment: %v", err)
+		}
+
+		paymentDB, err := payments.Insert(ctx, tixer.Payment{
+			PublicID:         newPublicID(t),
+			OrderID:          orderDB.ID,
+			Amount:           orderDB.Total,
+			Status:           tixer.PaymentSucceeded,
+			GatewayReference: "ch_" + string(orderDB.PublicID),
+		})
+		if err != nil {
+			t.Fatalf("inserting payment: %v", err)
+		}
+
+		orderDB, err = orders.SelectOne(ctx, orderDB.PublicID)
+		if err != nil {
+			t.Fatalf("selecting order: %v", err)
+		}
+		version := orderDB.Version
+		refundID := newPublicID(t)
+
+		var wg sync.WaitGroup
+		var refundErr, checkInErr error
+
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+
+			_, refundErr = refunds.Insert(ctx, tixer.Refund{
+				PublicID:  refundID,
+				PaymentID: paymentDB.ID,
+				Amount:    orderDB.Total,
+				Reason:    "race",
+			}, &orderDB)
+		}()
+		go func() {
+			defer wg.Done()
+
+			_, checkInErr = checkIns.Insert(ctx, tixer.CheckIn{
+				OrderID:     orderDB.ID,
+				ScannedByID: staffDB.ID,
+			}, version)
+		}()
+		wg.Wait()
+
+		for _, err := range []error{refundErr, checkInErr} {
+			if err != nil && err != psql.ErrDbEditConflict {
+				t.Fatalf("racing refund and check-in: %v", err)
+			}
+		}
+
+		switch {
+		case refundErr == nil && checkInErr == nil:
+			t.Fatalf("order %s was both refunded and checked in", orderDB.PublicID)
+		case refundErr != nil && checkInErr != nil:
+			t.Fatalf("order %s was neither refunded nor checked in", orderDB.PublicID)
+		}
+	}
+}
@@ -0,0 +1,367 @@
+package psql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	tixer "github.com/mroobert/monorepo-tixer"
+)
+
+var (
+	ErrDbOrderListed        = errors.New("db order listed")
+	ErrDbListingUnavailable = errors.New("db listing unavailable")
+)
+
+const (
+	resaleListingsTable = "resale_listings"
+	payoutsTable        = "payouts"
+)
+
+// resaleListingColumns lists the columns read by scanResaleListing, for a resale_listings
+// table aliased l joined to its order o and the ticket t of the order.
+const resaleListingColumns = `l.id, l.public_id, l.order_id, o.public_id, t.public_id, l.seller_id, l.buyer_id,` +
+	` l.price, l.original_price, l.currency, l.status, l.gateway_reference, l.created_at, l.sold_at`
+
+// resaleListingJoins joins a resale_listings table aliased l to the tables read by resaleListingColumns.
+const resaleListingJoins = ` JOIN ` + ordersTable + ` o ON o.id = l.order_id` +
+	` JOIN ` + ticketsTable + ` t ON t.id = o.ticket_id`
+
+// ResaleRepository persists the resale listings of purchased tickets and the payouts to their sellers in the database.
+type ResaleRepository struct {
+	DB           *pgxpool.Pool
+	QueryTimeout time.Duration
+}
+
+func NewResaleRepository(db *pgxpool.Pool, queryTimeout time.Duration) *ResaleRepository {
+	return &ResaleRepository{
+		DB:           db,
+		QueryTimeout: queryTimeout,
+	}
+}
+
+// Insert inserts a new active listing in the database.
+// It returns ErrDbOrderListed if the order is already on the market.
+func (rr *ResaleRepository) Insert(ctx context.Context, listing tixer.ResaleListing) (tixer.ResaleListing, error) {
+	query := `WITH l AS (INSERT INTO ` + resaleListingsTable +
+		` (public_id, order_id, seller_id, price, original_price, currency, status) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING *)` +
+		` SELECT ` + resaleListingColumns + ` FROM l` + resaleListingJoins
+
+	args := []any{
+		listing.PublicID,
+		listing.OrderID,
+		listing.SellerID,
+		listing.Price.Amount,
+		listing.OriginalPrice.Amount,
+		listing.Price.Currency,
+		tixer.ResaleActive,
+	}
+
+	queryCtx, cancel := context.WithTimeout(ctx, rr.QueryTimeout)
+	defer cancel()
+
+	tx, err := beginScopedTx(queryCtx, rr.DB)
+	if err != nil {
+		return tixer.ResaleListing{}, err
+	}
+	defer tx.Rollback(queryCtx)
+
+	createdListing, err := scanResaleListing(tx.QueryRow(queryCtx, query, args...))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		switch {
+		case errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation && pgErr.ConstraintName == "resale_listings_active_order_id_idx":
+			return tixer.ResaleListing{}, ErrDbOrderListed
+		default:
+			return tixer.ResaleListing{}, fmt.Errorf("failed to insert resale listing in database: %w", err)
+		}
+	}
+
+	if err := tx.Commit(queryCtx); err != nil {
+		return tixer.ResaleListing{}, fmt.Errorf("failed to commit resale listing transaction: %w", err)
+	}
+
+	return createdListing, nil
+}
+
+// SelectOne reads a resale listing from the database.
+func (rr *ResaleRepository) SelectOne(ctx context.Context, id tixer.PublicID) (tixer.ResaleListing, error) {
+	query := `SELECT ` + resaleListingColumns + ` FROM ` + resaleListingsTable + ` l` + resaleListingJoins +
+		` WHERE l.public_id = $1`
+
+	queryCtx, cancel := context.WithTimeout(ctx, rr.QueryTimeout)
+	defer cancel()
+
+	tx, err := beginScopedTx(queryCtx, rr.DB)
+	if err != nil {
+		return tixer.ResaleListing{}, err
+	}
+	defer tx.Rollback(queryCtx)
+
+	listing, err := scanResaleListing(tx.QueryRow(queryCtx, query, id))
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return tixer.ResaleListing{}, ErrDbRecordNotFound
+		default:
+			return tixer.ResaleListing{}, fmt.Errorf("failed to select resale listing from database: %w", err)
+		}
+	}
+
+	if err := tx.Commit(queryCtx); err != nil {
+		return tixer.ResaleListing{}, fmt.Errorf("failed to commit resale listing transaction: %w", err)
+	}
+
+	return listing, nil
+}
+
+type ResaleFilter struct {
+	TicketID string // when set, only the listings of this ticket are read
+	Limit    int
+	Offset   int
+}
+
+// SelectMultiple reads the listings that are on the market from the database, the cheapest first.
+// Listings whose order was refunded, checked in or left the seller in the meantime are skipped, they can no longer be bought.
+func (rr *ResaleRepository) SelectMultiple(ctx context.Context, filter ResaleFilter) ([]tixer.ResaleListing, Pagination, error) {
+	query := `SELECT count(*) OVER(), ` + resaleListingColumns + ` FROM ` + resaleListingsTable + ` l` + resaleListingJoins +
+		` WHERE l.status = $1 AND o.status = $2 AND o.user_id = l.seller_id AND (t.public_id = $3 OR $3 = '')` +
+		` AND NOT EXISTS (SELECT 1 FROM ` + checkInsTable + ` ci WHERE ci.order_id = o.id)` +
+		` ORDER BY l.price, l.id LIMIT $4 OFFSET $5`
+
+	args := []any{tixer.ResaleActive, tixer.OrderPaid, filter.TicketID, filter.Limit, filter.Offset}
+
+	queryCtx, cancel := context.WithTimeout(ctx, rr.QueryTimeout)
+	defer cancel()
+
+	tx, err := beginScopedTx(queryCtx, rr.DB)
+	if err != nil {
+		return nil, Pagination{}, err
+	}
+	defer tx.Rollback(queryCtx)
+
+	rows, err := tx.Query(queryCtx, query, args...)
+	if err != nil {
+		return nil, Pagination{}, fmt.Errorf("failed to select resale listings from database: %w", err)
+	}
+
+	defer rows.Close()
+
+	totalRecords := 0
+	listings := []tixer.ResaleListing{}
+
+	for rows.Next() {
+		var listing tixer.ResaleListing
+
+		err := rows.Scan(
+			&totalRecords,
+			&listing.ID,
+			&listing.PublicID,
+			&listing.OrderID,
+			&listing.OrderPublicID,
+			&listing.TicketPublicID,
+			&listing.SellerID,
+			&listing.BuyerID,
+			&listing.Price.Amount,
+			&listing.OriginalPrice.Amount,
+			&listing.Price.Currency,
+			&listing.Status,
+			&listing.GatewayReference,
+			&listing.CreatedAt,
+			&listing.SoldAt,
+		)
+		if err != nil {
+			return nil, Pagination{}, fmt.Errorf("failed to scan row result: %w", err)
+		}
+		listing.OriginalPrice.Currency = listing.Price.Currency
+
+		listings = append(listings, listing)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, Pagination{}, fmt.Errorf("failed to iterate over rows result: %w", err)
+	}
+
+	if err := tx.Commit(queryCtx); err != nil {
+		return nil, Pagination{}, fmt.Errorf("failed to commit resale listings transaction: %w", err)
+	}
+
+	pagination := calculatePagination(totalRecords, filter.Offset/filter.Limit+1, filter.Limit)
+
+	return listings, pagination, nil
+}
+
+// Withdraw takes an active listing off the market.
+// It returns ErrDbEditConflict if the listing is no longer active.
+func (rr *ResaleRepository) Withdraw(ctx context.Context, listing *tixer.ResaleListing) error {
+	query := `UPDATE ` + resaleListingsTable + ` SET status = $1 WHERE id = $2 AND status = $3 RETURNING status`
+
+	queryCtx, cancel := context.WithTimeout(ctx, rr.QueryTimeout)
+	defer cancel()
+
+	if err := rr.DB.QueryRow(queryCtx, query, tixer.ResaleWithdrawn, listing.ID, tixer.ResaleActive).Scan(&listing.Status); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return ErrDbEditConflict
+		default:
+			return fmt.Errorf("failed to withdraw resale listing in database: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Purchase completes the sale of a listing to listing.BuyerID, who was already charged under
+// listing.GatewayReference. In one transaction the listing is marked sold, the order moves to the buyer
+// and is marked as transferred, and the payout of the price to the seller is recorded.
+// It returns ErrDbListingUnavailable if the listing is no longer active or its order is no longer
+// paid, held by the seller and unused; nothing is changed then and the charge must be given back.
+func (rr *ResaleRepository) Purchase(ctx context.Context, listing *tixer.ResaleListing, order *tixer.Order, payout tixer.Payout) (tixer.Payout, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, rr.QueryTimeout)
+	defer cancel()
+
+	tx, err := rr.DB.Begin(queryCtx)
+	if err != nil {
+		return tixer.Payout{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(queryCtx)
+
+	listingQuery := `UPDATE ` + resaleListingsTable + ` SET status = $1, buyer_id = $2, gateway_reference = $3, sold_at = NOW()` +
+		` WHERE id = $4 AND status = $5 RETURNING status, sold_at`
+
+	listingArgs := []any{tixer.ResaleSold, listing.BuyerID, listing.GatewayReference, listing.ID, tixer.ResaleActive}
+
+	if err := tx.QueryRow(queryCtx, listingQuery, listingArgs...).Scan(&listing.Status, &listing.SoldAt); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return tixer.Payout{}, ErrDbListingUnavailable
+		default:
+			return tixer.Payout{}, fmt.Errorf("failed to mark resale listing as sold in database: %w", err)
+		}
+	}
+
+	orderQuery := `UPDATE ` + ordersTable + ` SET user_id = $1, transferred_at = NOW(), version = version + 1, updated_at = NOW()` +
+		` WHERE id = $2 AND user_id = $3 AND status = $4` +
+		` AND NOT EXISTS (SELECT 1 FROM ` + checkInsTable + ` WHERE order_id = $2) RETURNING user_id, transferred_at, version`
+
+	orderArgs := []any{listing.BuyerID, listing.OrderID, listing.SellerID, tixer.OrderPaid}
+
+	if err := tx.QueryRow(queryCtx, orderQuery, orderArgs...).Scan(&order.BuyerID, &order.TransferredAt, &order.Version); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return tixer.Payout{}, ErrDbListingUnavailable
+		default:
+			return tixer.Payout{}, fmt.Errorf("failed to transfer order in database: %w", err)
+		}
+	}
+
+	payoutQuery := `INSERT INTO ` + payoutsTable +
+		` (public_id, listing_id, user_id, amount, currency, status) VALUES ($1, $2, $3, $4, $5, $6)
+        RETURNING id, public_id, listing_id, user_id, amount, currency, status, created_at`
+
+	payoutArgs := []any{payout.PublicID, listing.ID, listing.SellerID, listing.Price.Amount, listing.Price.Currency, tixer.PayoutPending}
+
+	createdPayout := tixer.Payout{ListingPublicID: listing.PublicID}
+	if err := tx.QueryRow(queryCtx, payoutQuery, payoutArgs...).Scan(
+		&createdPayout.ID,
+		&createdPayout.PublicID,
+		&createdPayout.ListingID,
+		&createdPayout.UserID,
+		&createdPayout.Amount.Amount,
+		&createdPayout.Amount.Currency,
+		&createdPayout.Status,
+		&createdPayout.CreatedAt,
+	); err != nil {
+		return tixer.Payout{}, fmt.Errorf("failed to insert payout in database: %w", err)
+	}
+
+	if err := tx.Commit(queryCtx); err != nil {
+		return tixer.Payout{}, fmt.Errorf("failed to commit resale transaction: %w", err)
+	}
+
+	return createdPayout, nil
+}
+
+type PayoutFilter struct {
+	UserID int64 // only the payouts owed to this user are read
+	Limit  int
+	Offset int
+}
+
+// SelectPayouts reads the payouts owed to a seller from the database, the newest first.
+func (rr *ResaleRepository) SelectPayouts(ctx context.Context, filter PayoutFilter) ([]tixer.Payout, Pagination, error) {
+	query := `SELECT count(*) OVER(), p.id, p.public_id, p.listing_id, l.public_id, p.user_id, p.amount, p.currency, p.status, p.created_at` +
+		` FROM ` + payoutsTable + ` p JOIN ` + resaleListingsTable + ` l ON l.id = p.listing_id` +
+		` WHERE p.user_id = $1 ORDER BY p.id DESC LIMIT $2 OFFSET $3`
+
+	queryCtx, cancel := context.WithTimeout(ctx, rr.QueryTimeout)
+	defer cancel()
+
+	rows, err := rr.DB.Query(queryCtx, query, filter.UserID, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, Pagination{}, fmt.Errorf("failed to select payouts from database: %w", err)
+	}
+
+	defer rows.Close()
+
+	totalRecords := 0
+	payouts := []tixer.Payout{}
+
+	for rows.Next() {
+		var payout tixer.Payout
+
+		err := rows.Scan(
+			&totalRecords,
+			&payout.ID,
+			&payout.PublicID,
+			&payout.ListingID,
+			&payout.ListingPublicID,
+			&payout.UserID,
+			&payout.Amount.Amount,
+			&payout.Amount.Currency,
+			&payout.Status,
+			&payout.CreatedAt,
+		)
+		if err != nil {
+			return nil, Pagination{}, fmt.Errorf("failed to scan row result: %w", err)
+		}
+
+		payouts = append(payouts, payout)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, Pagination{}, fmt.Errorf("failed to iterate over rows result: %w", err)
+	}
+
+	pagination := calculatePagination(totalRecords, filter.Offset/filter.Limit+1, filter.Limit)
+
+	return payouts, pagination, nil
+}
+
+func scanResaleListing(row pgx.Row) (tixer.ResaleListing, error) {
+	var listing tixer.ResaleListing
+
+	err := row.Scan(
+		&listing.ID,
+		&listing.PublicID,
+		&listing.OrderID,
+		&listing.OrderPublicID,
+		&listing.TicketPublicID,
+		&listing.SellerID,
+		&listing.BuyerID,
+		&listing.Price.Amount,
+		&listing.OriginalPrice.Amount,
+		&listing.Price.Currency,
+		&listing.Status,
+		&listing.GatewayReference,
+		&listing.CreatedAt,
+		&listing.SoldAt,
+	)
+	listing.OriginalPrice.Currency = listing.Price.Currency
+
+	return listing, err
+}
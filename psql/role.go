@@ -0,0 +1,290 @@
+package psql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	tixer "github.com/mroobert/monorepo-tixer"
+)
+
+var (
+	ErrDbDuplicateRole = errors.New("db duplicate role")
+)
+
+const (
+	rolesTable           = "roles"
+	permissionsTable     = "permissions"
+	rolePermissionsTable = "role_permissions"
+	userRolesTable       = "user_roles"
+)
+
+// RoleRepository persists roles, their permissions and the roles granted to users in the database.
+type RoleRepository struct {
+	DB           *pgxpool.Pool
+	QueryTimeout time.Duration
+}
+
+func NewRoleRepository(db *pgxpool.Pool, queryTimeout time.Duration) *RoleRepository {
+	return &RoleRepository{
+		DB:           db,
+		QueryTimeout: queryTimeout,
+	}
+}
+
+// SelectRolePermissions reads the permissions of a role from the database.
+func (rr *RoleRepository) SelectRolePermissions(ctx context.Context, role string) (tixer.Permissions, error) {
+	query := `SELECT p.code FROM ` + permissionsTable + ` p` +
+		` JOIN ` + rolePermissionsTable + ` rp ON rp.permission_id = p.id` +
+		` JOIN ` + rolesTable + ` r ON r.id = rp.role_id` +
+		` WHERE r.name = $1`
+
+	return rr.selectPermissions(ctx, query, role)
+}
+
+// SelectUserPermissions reads the permissions of all roles granted to a user from the database.
+func (rr *RoleRepository) SelectUserPermissions(ctx context.Context, userID tixer.PublicID) (tixer.Permissions, error) {
+	query := `SELECT DISTINCT p.code FROM ` + permissionsTable + ` p` +
+		` JOIN ` + rolePermissionsTable + ` rp ON rp.permission_id = p.id` +
+		` JOIN ` + userRolesTable + ` ur ON ur.role_id = rp.role_id` +
+		` JOIN ` + usersTable + ` u ON u.id = ur.user_id` +
+		` WHERE u.public_id = $1`
+
+	return rr.selectPermissions(ctx, query, userID)
+}
+
+// selectPermissions runs a query that returns permission codes.
+func (rr *RoleRepository) selectPermissions(ctx context.Context, query string, args ...any) (tixer.Permissions, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, rr.QueryTimeout)
+	defer cancel()
+
+	rows, err := rr.DB.Query(queryCtx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select permissions from database: %w", err)
+	}
+
+	permissions, err := pgx.CollectRows(rows, pgx.RowTo[tixer.Permission])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect permissions: %w", err)
+	}
+
+	return permissions, nil
+}
+
+// SelectAll reads all roles with their permissions from the database.
+func (rr *RoleRepository) SelectAll(ctx context.Context) ([]tixer.Role, error) {
+	query := `SELECT r.id, r.name, r.description, COALESCE(array_agg(p.code ORDER BY p.code) FILTER (WHERE p.code IS NOT NULL), '{}')` +
+		` FROM ` + rolesTable + ` r` +
+		` LEFT JOIN ` + rolePermissionsTable + ` rp ON rp.role_id = r.id` +
+		` LEFT JOIN ` + permissionsTable + ` p ON p.id = rp.permission_id` +
+		` GROUP BY r.id ORDER BY r.id`
+
+	queryCtx, cancel := context.WithTimeout(ctx, rr.QueryTimeout)
+	defer cancel()
+
+	rows, err := rr.DB.Query(queryCtx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select roles from database: %w", err)
+	}
+
+	defer rows.Close()
+
+	roles := []tixer.Role{}
+
+	for rows.Next() {
+		var role tixer.Role
+
+		err := rows.Scan(
+			&role.ID,
+			&role.Name,
+			&role.Description,
+			&role.Permissions,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row result: %w", err)
+		}
+
+		roles = append(roles, role)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over rows result: %w", err)
+	}
+
+	return roles, nil
+}
+
+// Insert inserts a new role with its permissions in the database.
+// It returns ErrDbDuplicateRole if a role with the same name already exists.
+func (rr *RoleRepository) Insert(ctx context.Context, role tixer.Role) (tixer.Role, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, rr.QueryTimeout)
+	defer cancel()
+
+	tx, err := rr.DB.Begin(queryCtx)
+	if err != nil {
+		return tixer.Role{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(queryCtx)
+
+	query := `INSERT INTO ` + rolesTable + ` (name, description) VALUES ($1, $2) RETURNING id`
+
+	if err := tx.QueryRow(queryCtx, query, role.Name, role.Description).Scan(&role.ID); err != nil {
+		var pgErr *pgconn.PgError
+		switch {
+		case errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation:
+			return tixer.Role{}, ErrDbDuplicateRole
+		default:
+			return tixer.Role{}, fmt.Errorf("failed to insert role in database: %w", err)
+		}
+	}
+
+	if err := rr.insertPermissions(queryCtx, tx, role.ID, role.Permissions); err != nil {
+		return tixer.Role{}, err
+	}
+
+	if err := tx.Commit(queryCtx); err != nil {
+		return tixer.Role{}, fmt.Errorf("failed to commit role transaction: %w", err)
+	}
+
+	return role, nil
+}
+
+// UpdatePermissions replaces the permissions of a role in the database.
+func (rr *RoleRepository) UpdatePermissions(ctx context.Context, role *tixer.Role) error {
+	queryCtx, cancel := context.WithTimeout(ctx, rr.QueryTimeout)
+	defer cancel()
+
+	tx, err := rr.DB.Begin(queryCtx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(queryCtx)
+
+	query := `SELECT id, description FROM ` + rolesTable + ` WHERE name = $1 FOR UPDATE`
+
+	if err := tx.QueryRow(queryCtx, query, role.Name).Scan(&role.ID, &role.Description); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return ErrDbRecordNotFound
+		default:
+			return fmt.Errorf("failed to select role from database: %w", err)
+		}
+	}
+
+	query = `DELETE FROM ` + rolePermissionsTable + ` WHERE role_id = $1`
+
+	if _, err := tx.Exec(queryCtx, query, role.ID); err != nil {
+		return fmt.Errorf("failed to delete role permissions from database: %w", err)
+	}
+
+	if err := rr.insertPermissions(queryCtx, tx, role.ID, role.Permissions); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(queryCtx); err != nil {
+		return fmt.Errorf("failed to commit role transaction: %w", err)
+	}
+
+	return nil
+}
+
+// insertPermissions assigns the permissions to a role inside the given transaction.
+func (rr *RoleRepository) insertPermissions(ctx context.Context, tx pgx.Tx, roleID int64, permissions tixer.Permissions) error {
+	query := `INSERT INTO ` + rolePermissionsTable + ` (role_id, permission_id)` +
+		` SELECT $1, id FROM ` + permissionsTable + ` WHERE code = ANY($2)`
+
+	if _, err := tx.Exec(ctx, query, roleID, permissions); err != nil {
+		return fmt.Errorf("failed to insert role permissions in database: %w", err)
+	}
+
+	return nil
+}
+
+// Delete deletes a role from the database, revoking it from every user.
+func (rr *RoleRepository) Delete(ctx context.Context, name string) error {
+	query := `DELETE FROM ` + rolesTable + ` WHERE name = $1`
+
+	queryCtx, cancel := context.WithTimeout(ctx, rr.QueryTimeout)
+	defer cancel()
+
+	res, err := rr.DB.Exec(queryCtx, query, name)
+	if err != nil {
+		return fmt.Errorf("failed to delete role from database: %w", err)
+	}
+
+	if res.RowsAffected() == 0 {
+		return ErrDbRecordNotFound
+	}
+
+	return nil
+}
+
+// Grant grants a role to a user.
+// It returns ErrDbRecordNotFound if the user or the role does not exist.
+func (rr *RoleRepository) Grant(ctx context.Context, userID tixer.PublicID, role string) error {
+	query := `INSERT INTO ` + userRolesTable + ` (user_id, role_id)` +
+		` SELECT u.id, r.id FROM ` + usersTable + ` u, ` + rolesTable + ` r` +
+		` WHERE u.public_id = $1 AND r.name = $2` +
+		` ON CONFLICT DO NOTHING RETURNING user_id`
+
+	queryCtx, cancel := context.WithTimeout(ctx, rr.QueryTimeout)
+	defer cancel()
+
+	var id int64
+	if err := rr.DB.QueryRow(queryCtx, query, userID, role).Scan(&id); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			// Either the grant already exists or the user or the role is missing.
+			return rr.checkGrantTargets(ctx, userID, role)
+		default:
+			return fmt.Errorf("failed to grant role in database: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// checkGrantTargets returns ErrDbRecordNotFound if the user or the role does not exist.
+func (rr *RoleRepository) checkGrantTargets(ctx context.Context, userID tixer.PublicID, role string) error {
+	query := `SELECT EXISTS (SELECT 1 FROM ` + usersTable + ` WHERE public_id = $1)` +
+		` AND EXISTS (SELECT 1 FROM ` + rolesTable + ` WHERE name = $2)`
+
+	queryCtx, cancel := context.WithTimeout(ctx, rr.QueryTimeout)
+	defer cancel()
+
+	var exist bool
+	if err := rr.DB.QueryRow(queryCtx, query, userID, role).Scan(&exist); err != nil {
+		return fmt.Errorf("failed to check grant targets in database: %w", err)
+	}
+
+	if !exist {
+		return ErrDbRecordNotFound
+	}
+
+	return nil
+}
+
+// Revoke revokes a role from a user.
+// It returns ErrDbRecordNotFound if the user did not have the role.
+func (rr *RoleRepository) Revoke(ctx context.Context, userID tixer.PublicID, role string) error {
+	query := `DELETE FROM ` + userRolesTable + ` ur USING ` + usersTable + ` u, ` + rolesTable + ` r` +
+		` WHERE ur.user_id = u.id AND ur.role_id = r.id AND u.public_id = $1 AND r.name = $2`
+
+	queryCtx, cancel := context.WithTimeout(ctx, rr.QueryTimeout)
+	defer cancel()
+
+	res, err := rr.DB.Exec(queryCtx, query, userID, role)
+	if err != nil {
+		return fmt.Errorf("failed to revoke role in database: %w", err)
+	}
+
+	if res.RowsAffected() == 0 {
+		return ErrDbRecordNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,154 @@
+package psql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	tixer "github.com/mroobert/monorepo-tixer"
+)
+
+const ticketRulesTable = "ticket_rules"
+
+// TicketRulesRepository persists the ticket rules that organizers have instead of the deployment rules.
+type TicketRulesRepository struct {
+	DB           *pgxpool.Pool
+	QueryTimeout time.Duration
+}
+
+func NewTicketRulesRepository(db *pgxpool.Pool, queryTimeout time.Duration) *TicketRulesRepository {
+	return &TicketRulesRepository{
+		DB:           db,
+		QueryTimeout: queryTimeout,
+	}
+}
+
+// SelectOne reads the ticket rules override of the organizer from the database.
+// It returns ErrDbRecordNotFound if the organizer follows the deployment rules.
+func (rr *TicketRulesRepository) SelectOne(ctx context.Context, tenantID int64) (tixer.TicketRulesOverride, error) {
+	query := `SELECT tenant_id, max_title_length, min_price, max_prices, allowed_currencies, forbidden_words, updated_at` +
+		` FROM ` + ticketRulesTable + ` WHERE tenant_id = $1`
+
+	queryCtx, cancel := context.WithTimeout(ctx, rr.QueryTimeout)
+	defer cancel()
+
+	override, err := scanTicketRulesOverride(rr.DB.QueryRow(queryCtx, query, tenantID))
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return tixer.TicketRulesOverride{}, ErrDbRecordNotFound
+		default:
+			return tixer.TicketRulesOverride{}, fmt.Errorf("failed to select ticket rules from database: %w", err)
+		}
+	}
+
+	return override, nil
+}
+
+// Upsert stores the ticket rules override of the organizer (tenant) referenced by override.TenantID,
+// replacing the override it had. It returns ErrDbRecordNotFound if the organizer does not exist.
+func (rr *TicketRulesRepository) Upsert(ctx context.Context, override tixer.TicketRulesOverride) (tixer.TicketRulesOverride, error) {
+	query := `INSERT INTO ` + ticketRulesTable +
+		` (tenant_id, max_title_length, min_price, max_prices, allowed_currencies, forbidden_words) VALUES ($1, $2, $3, $4, $5, $6)` +
+		` ON CONFLICT (tenant_id) DO UPDATE SET max_title_length = EXCLUDED.max_title_length, min_price = EXCLUDED.min_price,` +
+		` max_prices = EXCLUDED.max_prices, allowed_currencies = EXCLUDED.allowed_currencies,` +
+		` forbidden_words = EXCLUDED.forbidden_words, updated_at = NOW()` +
+		` RETURNING tenant_id, max_title_length, min_price, max_prices, allowed_currencies, forbidden_words, updated_at`
+
+	var currencies *[]string
+	if override.AllowedCurrencies != nil {
+		codes := make([]string, len(*override.AllowedCurrencies))
+		for i, currency := range *override.AllowedCurrencies {
+			codes[i] = string(currency)
+		}
+		currencies = &codes
+	}
+
+	// A nil map would be stored as a JSON null instead of NULL.
+	var maxPrices any
+	if len(override.MaxPrices) > 0 {
+		prices := make(map[string]int64, len(override.MaxPrices))
+		for currency, amount := range override.MaxPrices {
+			prices[string(currency)] = amount
+		}
+		maxPrices = prices
+	}
+
+	args := []any{override.TenantID, override.MaxTitleLength, override.MinPrice, maxPrices, currencies, override.ForbiddenWords}
+
+	queryCtx, cancel := context.WithTimeout(ctx, rr.QueryTimeout)
+	defer cancel()
+
+	stored, err := scanTicketRulesOverride(rr.DB.QueryRow(queryCtx, query, args...))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		switch {
+		case errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation:
+			return tixer.TicketRulesOverride{}, ErrDbRecordNotFound
+		default:
+			return tixer.TicketRulesOverride{}, fmt.Errorf("failed to upsert ticket rules in database: %w", err)
+		}
+	}
+
+	return stored, nil
+}
+
+// Delete removes the ticket rules override of the organizer, who follows the deployment rules again.
+// It returns ErrDbRecordNotFound if the organizer has no override.
+func (rr *TicketRulesRepository) Delete(ctx context.Context, tenantID int64) error {
+	query := `DELETE FROM ` + ticketRulesTable + ` WHERE tenant_id = $1`
+
+	queryCtx, cancel := context.WithTimeout(ctx, rr.QueryTimeout)
+	defer cancel()
+
+	result, err := rr.DB.Exec(queryCtx, query, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to delete ticket rules from database: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrDbRecordNotFound
+	}
+
+	return nil
+}
+
+func scanTicketRulesOverride(row pgx.Row) (tixer.TicketRulesOverride, error) {
+	var override tixer.TicketRulesOverride
+	var maxPrices map[string]int64
+	var currencies *[]string
+
+	err := row.Scan(
+		&override.TenantID,
+		&override.MaxTitleLength,
+		&override.MinPrice,
+		&maxPrices,
+		&currencies,
+		&override.ForbiddenWords,
+		&override.UpdatedAt,
+	)
+	if err != nil {
+		return tixer.TicketRulesOverride{}, err
+	}
+
+	if len(maxPrices) > 0 {
+		override.MaxPrices = make(map[tixer.Currency]int64, len(maxPrices))
+		for currency, amount := range maxPrices {
+			override.MaxPrices[tixer.Currency(currency)] = amount
+		}
+	}
+
+	if currencies != nil {
+		allowed := make([]tixer.Currency, len(*currencies))
+		for i, code := range *currencies {
+			allowed[i] = tixer.Currency(code)
+		}
+		override.AllowedCurrencies = &allowed
+	}
+
+	return override, nil
+}
@@ -0,0 +1,288 @@
+package psql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	tixer "github.com/mroobert/monorepo-tixer"
+)
+
+const (
+	sectionsTable = "sections"
+	seatRowsTable = "seat_rows"
+	seatsTable    = "seats"
+)
+
+// SeatRepository persists the seat maps of the venues in the database.
+type SeatRepository struct {
+	DB           *pgxpool.Pool
+	QueryTimeout time.Duration
+}
+
+func NewSeatRepository(db *pgxpool.Pool, queryTimeout time.Duration) *SeatRepository {
+	return &SeatRepository{
+		DB:           db,
+		QueryTimeout: queryTimeout,
+	}
+}
+
+// ReplaceSeatMap replaces the seat map of a venue in the database and sets the IDs
+// of the inserted sections, rows and seats. The public IDs of the seats must be set.
+// It returns ErrDbRecordInUse if tickets were issued for seats of the current map.
+func (sr *SeatRepository) ReplaceSeatMap(ctx context.Context, venueID int64, seatMap *tixer.SeatMap) error {
+	queryCtx, cancel := context.WithTimeout(ctx, sr.QueryTimeout)
+	defer cancel()
+
+	tx, err := sr.DB.Begin(queryCtx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(queryCtx)
+
+	lockQuery := `SELECT id FROM ` + venuesTable + ` WHERE id = $1 FOR UPDATE`
+
+	if err := tx.QueryRow(queryCtx, lockQuery, venueID).Scan(&venueID); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return ErrDbRecordNotFound
+		default:
+			return fmt.Errorf("failed to lock venue in database: %w", err)
+		}
+	}
+
+	deleteQuery := `DELETE FROM ` + sectionsTable + ` WHERE venue_id = $1`
+
+	if _, err := tx.Exec(queryCtx, deleteQuery, venueID); err != nil {
+		var pgErr *pgconn.PgError
+		switch {
+		case errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation:
+			return ErrDbRecordInUse
+		default:
+			return fmt.Errorf("failed to delete seat map from database: %w", err)
+		}
+	}
+
+	sectionQuery := `INSERT INTO ` + sectionsTable + ` (venue_id, name, position) VALUES ($1, $2, $3) RETURNING id`
+	rowQuery := `INSERT INTO ` + seatRowsTable + ` (section_id, label, position) VALUES ($1, $2, $3) RETURNING id`
+	seatQuery := `INSERT INTO ` + seatsTable + ` (public_id, row_id, number, category) VALUES ($1, $2, $3, $4) RETURNING id`
+
+	for i := range seatMap.Sections {
+		section := &seatMap.Sections[i]
+		if err := tx.QueryRow(queryCtx, sectionQuery, venueID, section.Name, i).Scan(&section.ID); err != nil {
+			return fmt.Errorf("failed to insert section in database: %w", err)
+		}
+
+		for j := range section.Rows {
+			row := &section.Rows[j]
+			if err := tx.QueryRow(queryCtx, rowQuery, section.ID, row.Label, j).Scan(&row.ID); err != nil {
+				return fmt.Errorf("failed to insert seat row in database: %w", err)
+			}
+
+			for k := range row.Seats {
+				seat := &row.Seats[k]
+				seat.VenueID = venueID
+				if err := tx.QueryRow(queryCtx, seatQuery, seat.PublicID, row.ID, seat.Number, seat.Category).Scan(&seat.ID); err != nil {
+					return fmt.Errorf("failed to insert seat in database: %w", err)
+				}
+			}
+		}
+	}
+
+	if err := tx.Commit(queryCtx); err != nil {
+		return fmt.Errorf("failed to commit seat map transaction: %w", err)
+	}
+
+	return nil
+}
+
+// SelectSeatMap reads the seat map of a venue from the database.
+func (sr *SeatRepository) SelectSeatMap(ctx context.Context, venueID int64) (tixer.SeatMap, error) {
+	query := `SELECT sc.id, sc.name, r.id, r.label, s.id, s.public_id, s.number, s.category` +
+		` FROM ` + sectionsTable + ` sc` +
+		` JOIN ` + seatRowsTable + ` r ON r.section_id = sc.id` +
+		` JOIN ` + seatsTable + ` s ON s.row_id = r.id` +
+		` WHERE sc.venue_id = $1` +
+		` ORDER BY sc.position, r.position, s.number`
+
+	queryCtx, cancel := context.WithTimeout(ctx, sr.QueryTimeout)
+	defer cancel()
+
+	rows, err := sr.DB.Query(queryCtx, query, venueID)
+	if err != nil {
+		return tixer.SeatMap{}, fmt.Errorf("failed to select seat map from database: %w", err)
+	}
+
+	defer rows.Close()
+
+	var seatMap tixer.SeatMap
+
+	for rows.Next() {
+		var section tixer.Section
+		var row tixer.SeatRow
+		seat := tixer.Seat{VenueID: venueID}
+
+		err := rows.Scan(
+			&section.ID,
+			&section.Name,
+			&row.ID,
+			&row.Label,
+			&seat.ID,
+			&seat.PublicID,
+			&seat.Number,
+			&seat.Category,
+		)
+		if err != nil {
+			return tixer.SeatMap{}, fmt.Errorf("failed to scan row result: %w", err)
+		}
+
+		appendSeat(&seatMap, section, row, seat)
+	}
+
+	if err = rows.Err(); err != nil {
+		return tixer.SeatMap{}, fmt.Errorf("failed to iterate over rows result: %w", err)
+	}
+
+	return seatMap, nil
+}
+
+// SelectEventSeatMap reads the seat map of the venue of an event from the database,
+// together with the availability of every seat for the event.
+func (sr *SeatRepository) SelectEventSeatMap(ctx context.Context, eventID int64, venueID int64) (tixer.SeatMap, error) {
+	query := `SELECT sc.id, sc.name, r.id, r.label, s.id, s.public_id, s.number, s.category, t.public_id, t.price, t.currency, t.held, o.status` +
+		` FROM ` + sectionsTable + ` sc` +
+		` JOIN ` + seatRowsTable + ` r ON r.section_id = sc.id` +
+		` JOIN ` + seatsTable + ` s ON s.row_id = r.id` +
+		` LEFT JOIN ` + ticketsTable + ` t ON t.seat_id = s.id AND t.event_id = $1` +
+		` LEFT JOIN ` + ordersTable + ` o ON o.ticket_id = t.id AND o.status IN ($3, $4, $5, $6)` +
+		` WHERE sc.venue_id = $2` +
+		` ORDER BY sc.position, r.position, s.number`
+
+	args := []any{eventID, venueID, tixer.OrderPending, tixer.OrderPaying, tixer.OrderPaid, tixer.OrderRefunding}
+
+	queryCtx, cancel := context.WithTimeout(ctx, sr.QueryTimeout)
+	defer cancel()
+
+	tx, err := beginScopedTx(queryCtx, sr.DB)
+	if err != nil {
+		return tixer.SeatMap{}, err
+	}
+	defer tx.Rollback(queryCtx)
+
+	rows, err := tx.Query(queryCtx, query, args...)
+	if err != nil {
+		return tixer.SeatMap{}, fmt.Errorf("failed to select seat map from database: %w", err)
+	}
+
+	defer rows.Close()
+
+	var seatMap tixer.SeatMap
+
+	for rows.Next() {
+		var section tixer.Section
+		var row tixer.SeatRow
+		seat := tixer.Seat{VenueID: venueID}
+		var ticketID *tixer.PublicID
+		var price *int64
+		var currency *tixer.Currency
+		var held *int32
+		var orderStatus *tixer.OrderStatus
+
+		err := rows.Scan(
+			&section.ID,
+			&section.Name,
+			&row.ID,
+			&row.Label,
+			&seat.ID,
+			&seat.PublicID,
+			&seat.Number,
+			&seat.Category,
+			&ticketID,
+			&price,
+			&currency,
+			&held,
+			&orderStatus,
+		)
+		if err != nil {
+			return tixer.SeatMap{}, fmt.Errorf("failed to scan row result: %w", err)
+		}
+
+		switch {
+		case ticketID == nil:
+			seat.Status = tixer.SeatUnlisted
+		case orderStatus == nil && *held > 0:
+			seat.Status = tixer.SeatReserved // held for a waitlist claim
+		case orderStatus == nil:
+			seat.Status = tixer.SeatAvailable
+		case *orderStatus == tixer.OrderPaid:
+			seat.Status = tixer.SeatSold
+		default:
+			seat.Status = tixer.SeatReserved
+		}
+		if ticketID != nil {
+			seat.Ticket = &tixer.Ticket{PublicID: *ticketID, Price: tixer.Money{Amount: *price, Currency: *currency}}
+		}
+
+		appendSeat(&seatMap, section, row, seat)
+	}
+
+	if err = rows.Err(); err != nil {
+		return tixer.SeatMap{}, fmt.Errorf("failed to iterate over rows result: %w", err)
+	}
+
+	if err := tx.Commit(queryCtx); err != nil {
+		return tixer.SeatMap{}, fmt.Errorf("failed to commit seat map transaction: %w", err)
+	}
+
+	return seatMap, nil
+}
+
+// SelectSeat reads a seat from the database.
+func (sr *SeatRepository) SelectSeat(ctx context.Context, id tixer.PublicID) (tixer.Seat, error) {
+	query := `SELECT s.id, s.public_id, sc.venue_id, s.number, s.category` +
+		` FROM ` + seatsTable + ` s` +
+		` JOIN ` + seatRowsTable + ` r ON r.id = s.row_id` +
+		` JOIN ` + sectionsTable + ` sc ON sc.id = r.section_id` +
+		` WHERE s.public_id = $1`
+
+	queryCtx, cancel := context.WithTimeout(ctx, sr.QueryTimeout)
+	defer cancel()
+
+	var seat tixer.Seat
+	if err := sr.DB.QueryRow(queryCtx, query, id).Scan(
+		&seat.ID,
+		&seat.PublicID,
+		&seat.VenueID,
+		&seat.Number,
+		&seat.Category,
+	); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return tixer.Seat{}, ErrDbRecordNotFound
+		default:
+			return tixer.Seat{}, fmt.Errorf("failed to select seat from database: %w", err)
+		}
+	}
+
+	return seat, nil
+}
+
+// appendSeat appends a seat read in seat map order to the seat map,
+// opening a new section or row when the seat belongs to a different one than the previous seat.
+func appendSeat(seatMap *tixer.SeatMap, section tixer.Section, row tixer.SeatRow, seat tixer.Seat) {
+	if n := len(seatMap.Sections); n == 0 || seatMap.Sections[n-1].ID != section.ID {
+		seatMap.Sections = append(seatMap.Sections, section)
+	}
+	lastSection := &seatMap.Sections[len(seatMap.Sections)-1]
+
+	if n := len(lastSection.Rows); n == 0 || lastSection.Rows[n-1].ID != row.ID {
+		lastSection.Rows = append(lastSection.Rows, row)
+	}
+	lastRow := &lastSection.Rows[len(lastSection.Rows)-1]
+
+	lastRow.Seats = append(lastRow.Seats, seat)
+}
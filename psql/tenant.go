@@ -0,0 +1,231 @@
+package psql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	tixer "github.com/mroobert/monorepo-tixer"
+)
+
+var (
+	ErrDbTenantRequired      = tixer.ErrTenantRequired
+	ErrDbRowSecurityBypassed = errors.New("db row security bypassed")
+)
+
+const tenantsTable = "tenants"
+
+// TenantRepository persists the organizers hosted on the deployment in the database.
+type TenantRepository struct {
+	DB           *pgxpool.Pool
+	QueryTimeout time.Duration
+}
+
+func NewTenantRepository(db *pgxpool.Pool, queryTimeout time.Duration) *TenantRepository {
+	return &TenantRepository{
+		DB:           db,
+		QueryTimeout: queryTimeout,
+	}
+}
+
+// Insert inserts a new tenant in the database.
+func (tr *TenantRepository) Insert(ctx context.Context, tenant tixer.Tenant) (tixer.Tenant, error) {
+	query := `INSERT INTO ` + tenantsTable + ` (public_id, name) VALUES ($1, $2) RETURNING id, public_id, name, created_at`
+
+	queryCtx, cancel := context.WithTimeout(ctx, tr.QueryTimeout)
+	defer cancel()
+
+	var createdTenant tixer.Tenant
+	if err := tr.DB.QueryRow(queryCtx, query, tenant.PublicID, tenant.Name).Scan(
+		&createdTenant.ID,
+		&createdTenant.PublicID,
+		&createdTenant.Name,
+		&createdTenant.CreatedAt,
+	); err != nil {
+		return tixer.Tenant{}, fmt.Errorf("failed to insert tenant in database: %w", err)
+	}
+
+	return createdTenant, nil
+}
+
+// SelectOne reads a tenant from the database.
+func (tr *TenantRepository) SelectOne(ctx context.Context, id tixer.PublicID) (tixer.Tenant, error) {
+	query := `SELECT id, public_id, name, created_at FROM ` + tenantsTable + ` WHERE public_id = $1`
+
+	queryCtx, cancel := context.WithTimeout(ctx, tr.QueryTimeout)
+	defer cancel()
+
+	var tenant tixer.Tenant
+	if err := tr.DB.QueryRow(queryCtx, query, id).Scan(
+		&tenant.ID,
+		&tenant.PublicID,
+		&tenant.Name,
+		&tenant.CreatedAt,
+	); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return tixer.Tenant{}, ErrDbRecordNotFound
+		default:
+			return tixer.Tenant{}, fmt.Errorf("failed to select tenant from database: %w", err)
+		}
+	}
+
+	return tenant, nil
+}
+
+type TenantFilter struct {
+	Limit  int
+	Offset int
+}
+
+// SelectMultiple reads the tenants from the database, ordered by name.
+func (tr *TenantRepository) SelectMultiple(ctx context.Context, filter TenantFilter) ([]tixer.Tenant, Pagination, error) {
+	query := `SELECT count(*) OVER(), id, public_id, name, created_at FROM ` + tenantsTable +
+		` ORDER BY name, id LIMIT $1 OFFSET $2`
+
+	queryCtx, cancel := context.WithTimeout(ctx, tr.QueryTimeout)
+	defer cancel()
+
+	rows, err := tr.DB.Query(queryCtx, query, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, Pagination{}, fmt.Errorf("failed to select tenants from database: %w", err)
+	}
+
+	defer rows.Close()
+
+	totalRecords := 0
+	tenants := []tixer.Tenant{}
+
+	for rows.Next() {
+		var tenant tixer.Tenant
+
+		err := rows.Scan(
+			&totalRecords,
+			&tenant.ID,
+			&tenant.PublicID,
+			&tenant.Name,
+			&tenant.CreatedAt,
+		)
+		if err != nil {
+			return nil, Pagination{}, fmt.Errorf("failed to scan row result: %w", err)
+		}
+
+		tenants = append(tenants, tenant)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, Pagination{}, fmt.Errorf("failed to iterate over rows result: %w", err)
+	}
+
+	pagination := calculatePagination(totalRecords, filter.Offset/filter.Limit+1, filter.Limit)
+
+	return tenants, pagination, nil
+}
+
+// AddMember makes the user work for the tenant, instead of the tenant it worked for before.
+// It returns ErrDbRecordNotFound if the user does not exist.
+func (tr *TenantRepository) AddMember(ctx context.Context, tenantID int64, userID tixer.PublicID) error {
+	query := `UPDATE ` + usersTable + ` SET tenant_id = $1, version = version + 1, updated_at = NOW() WHERE public_id = $2`
+
+	queryCtx, cancel := context.WithTimeout(ctx, tr.QueryTimeout)
+	defer cancel()
+
+	res, err := tr.DB.Exec(queryCtx, query, tenantID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to add tenant member in database: %w", err)
+	}
+
+	if res.RowsAffected() == 0 {
+		return ErrDbRecordNotFound
+	}
+
+	return nil
+}
+
+// RemoveMember stops the user from working for the tenant.
+// It returns ErrDbRecordNotFound if the user does not work for the tenant.
+func (tr *TenantRepository) RemoveMember(ctx context.Context, tenantID int64, userID tixer.PublicID) error {
+	query := `UPDATE ` + usersTable + ` SET tenant_id = NULL, version = version + 1, updated_at = NOW()` +
+		` WHERE public_id = $1 AND tenant_id = $2`
+
+	queryCtx, cancel := context.WithTimeout(ctx, tr.QueryTimeout)
+	defer cancel()
+
+	res, err := tr.DB.Exec(queryCtx, query, userID, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to remove tenant member from database: %w", err)
+	}
+
+	if res.RowsAffected() == 0 {
+		return ErrDbRecordNotFound
+	}
+
+	return nil
+}
+
+// beginTenantTx begins a transaction for the tenant carried by ctx and returns it with the tenant ID.
+// The tenant is set as app.tenant_id for the transaction only, so the row-level security policies
+// keep the transaction to the rows of the tenant even if one of its queries misses the tenant filter.
+// It returns ErrDbTenantRequired if ctx carries no tenant.
+func beginTenantTx(ctx context.Context, db *pgxpool.Pool) (pgx.Tx, int64, error) {
+	tenantID, ok := tixer.TenantFromContext(ctx)
+	if !ok {
+		return nil, 0, ErrDbTenantRequired
+	}
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	// SET LOCAL does not accept query parameters, set_config with is_local is its equivalent.
+	if _, err := tx.Exec(ctx, `SELECT set_config('app.tenant_id', $1, true)`, strconv.FormatInt(tenantID, 10)); err != nil {
+		tx.Rollback(ctx)
+		return nil, 0, fmt.Errorf("failed to set transaction tenant: %w", err)
+	}
+
+	return tx, tenantID, nil
+}
+
+// CheckRowSecurity checks that the role the pool connects as is subject to row-level security.
+// Superusers and roles with the BYPASSRLS attribute skip the tenant policies of the tables, so a query
+// that forgets its tenant filter would read the rows of every tenant; it returns ErrDbRowSecurityBypassed for them.
+func CheckRowSecurity(ctx context.Context, db *pgxpool.Pool) error {
+	query := `SELECT rolsuper OR rolbypassrls FROM pg_roles WHERE rolname = current_user`
+
+	var bypassed bool
+	if err := db.QueryRow(ctx, query).Scan(&bypassed); err != nil {
+		return fmt.Errorf("failed to read database role attributes: %w", err)
+	}
+	if bypassed {
+		return ErrDbRowSecurityBypassed
+	}
+
+	return nil
+}
+
+// beginScopedTx begins a transaction for the tenant carried by ctx, like beginTenantTx, or a transaction
+// that reaches the rows of every tenant if ctx was made by tixer.NewTenantBypassContext. The bypass is set
+// as app.bypass_rls for the transaction only. It returns ErrDbTenantRequired if ctx carries neither.
+func beginScopedTx(ctx context.Context, db *pgxpool.Pool) (pgx.Tx, error) {
+	if !tixer.TenantBypassed(ctx) {
+		tx, _, err := beginTenantTx(ctx, db)
+		return tx, err
+	}
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `SELECT set_config('app.bypass_rls', 'on', true)`); err != nil {
+		tx.Rollback(ctx)
+		return nil, fmt.Errorf("failed to set transaction tenant bypass: %w", err)
+	}
+
+	return tx, nil
+}
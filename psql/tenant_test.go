@@ -0,0 +1,91 @@
+package psql_test
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	tixer "github.com/mroobert/monorepo-tixer"
+	"github.com/mroobert/monorepo-tixer/psql"
+)
+
+// TestRowSecurityIsolatesTenants reads a ticket as a role that is subject to row-level security,
+// with a query that has no tenant filter, and checks that only its own tenant can see it.
+func TestRowSecurityIsolatesTenants(t *testing.T) {
+	pool := newTestPool(t)
+	ctx := context.Background()
+
+	tenants := psql.NewTenantRepository(pool, testQueryTimeout)
+	owner, err := tenants.Insert(ctx, tixer.Tenant{PublicID: newPublicID(t), Name: "Owning organizer"})
+	if err != nil {
+		t.Fatalf("inserting tenant: %v", err)
+	}
+	other, err := tenants.Insert(ctx, tixer.Tenant{PublicID: newPublicID(t), Name: "Other organizer"})
+	if err != nil {
+		t.Fatalf("inserting tenant: %v", err)
+	}
+
+	ticketDB, err := psql.NewTicketRepository(pool, testQueryTimeout).Insert(tixer.NewTenantContext(ctx, owner.ID), tixer.Ticket{
+		PublicID: newPublicID(t),
+		Title:    "Row security test ticket",
+		Status:   tixer.TicketDraft,
+		Price:    tixer.Money{Amount: 1000, Currency: "EUR"},
+		Capacity: 1,
+	})
+	if err != nil {
+		t.Fatalf("inserting ticket: %v", err)
+	}
+
+	// The roles created in the transaction are dropped with its rollback.
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		t.Fatalf("beginning transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := psql.CheckRowSecurity(ctx, pool); err == psql.ErrDbRowSecurityBypassed {
+		var schema string
+		if err := tx.QueryRow(ctx, `SELECT current_schema()`).Scan(&schema); err != nil {
+			t.Fatalf("reading schema: %v", err)
+		}
+
+		role := "test_app_" + strings.ToLower(string(newPublicID(t)))
+		for _, stmt := range []string{
+			`CREATE ROLE ` + role + ` NOLOGIN NOSUPERUSER NOBYPASSRLS`,
+			`GRANT USAGE ON SCHEMA ` + schema + ` TO ` + role,
+			`GRANT SELECT ON tickets TO ` + role,
+			`SET LOCAL ROLE ` + role,
+		} {
+			if _, err := tx.Exec(ctx, stmt); err != nil {
+				t.Fatalf("switching to a role subject to row security: %v", err)
+			}
+		}
+	} else if err != nil {
+		t.Fatalf("checking row security: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		tenant string
+		want   int
+	}{
+		{"owning tenant", strconv.FormatInt(owner.ID, 10), 1},
+		{"other tenant", strconv.FormatInt(other.ID, 10), 0},
+		{"no tenant", "", 0},
+	}
+
+	for _, tt := range tests {
+		if _, err := tx.Exec(ctx, `SELECT set_config('app.tenant_id', $1, true)`, tt.tenant); err != nil {
+			t.Fatalf("setting tenant: %v", err)
+		}
+
+		var got int
+		if err := tx.QueryRow(ctx, `SELECT count(*) FROM tickets WHERE public_id = $1`, ticketDB.PublicID).Scan(&got); err != nil {
+			t.Fatalf("%s: counting tickets: %v", tt.name, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: read %d tickets without a tenant filter, want %d", tt.name, got, tt.want)
+		}
+	}
+}
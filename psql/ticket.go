@@ -7,19 +7,58 @@ import (
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	tixer "github.com/mroobert/monorepo-tixer"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// The errors shared with the stores defined by the tixer package are the tixer errors,
+// so they compare equal whichever implementation of a store returned them.
 var (
-	ErrDbRecordNotFound = errors.New("db record not found")
-	ErrDbEditConflict   = errors.New("db edit conflict")
+	ErrDbRecordNotFound = tixer.ErrNotFound
+	ErrDbEditConflict   = tixer.ErrEditConflict
+	ErrDbRecordInUse    = tixer.ErrInUse
+	ErrDbSeatTaken      = tixer.ErrSeatTaken
+
+	ErrDbTicketNotPublished = errors.New("db ticket not published")
+
+	ErrDbPriceTierSoldOut = errors.New("db price tier sold out")
 )
 
-const ticketsTable = "tickets"
+const (
+	ticketsTable            = "tickets"
+	ticketPricingRulesTable = "ticket_pricing_rules"
+	ticketPriceTiersTable   = "ticket_price_tiers"
+)
+
+// priceTiersColumn reads the price tiers of a ticket aliased t as a JSON array
+// ordered by start, which is decoded into priceTierRow values.
+const priceTiersColumn = `(SELECT COALESCE(json_agg(json_build_object('id', p.id, 'name', p.name, 'price', p.price,` +
+	` 'startsAt', p.starts_at, 'quantity', p.quantity, 'sold', p.sold) ORDER BY p.starts_at), '[]')` +
+	` FROM ` + ticketPriceTiersTable + ` p WHERE p.ticket_id = t.id)`
+
+// priceTierRow is a price tier read by priceTiersColumn.
+type priceTierRow struct {
+	ID       int64     `json:"id"`
+	Name     string    `json:"name"`
+	Price    int64     `json:"price"`
+	StartsAt time.Time `json:"startsAt"`
+	Quantity *int32    `json:"quantity"`
+	Sold     int32     `json:"sold"`
+}
 
-// TicketRepository persists tickets in the database.
+// pendingColumn counts the sold units of a ticket aliased t whose order awaits payment.
+const pendingColumn = `(SELECT count(*) FROM ` + ordersTable + ` po WHERE po.ticket_id = t.id` +
+	` AND po.status IN ('` + string(tixer.OrderPending) + `', '` + string(tixer.OrderPaying) + `'))`
+
+// pricingRulesColumns reads the pricing rules of a ticket aliased t as two arrays
+// ordered by threshold, which are combined by toPricingRules.
+const pricingRulesColumns = `ARRAY(SELECT r.sold_above FROM ` + ticketPricingRulesTable + ` r WHERE r.ticket_id = t.id ORDER BY r.sold_above),` +
+	` ARRAY(SELECT r.adjust_percent FROM ` + ticketPricingRulesTable + ` r WHERE r.ticket_id = t.id ORDER BY r.sold_above)`
+
+// TicketRepository persists tickets in the database. Every method works on the tickets of
+// the tenant carried by its context only, and returns ErrDbTenantRequired if there is none.
 type TicketRepository struct {
 	DB           *pgxpool.Pool
 	QueryTimeout time.Duration
@@ -32,28 +71,71 @@ func NewTicketRepository(db *pgxpool.Pool, queryTimeout time.Duration) *TicketRe
 	}
 }
 
-// Insert inserts a new ticket in the database.
+// Insert inserts a new ticket with its price tiers and pricing rules in the database.
+// It returns ErrDbSeatTaken if another ticket was already issued for the seat at the same event.
 func (tr *TicketRepository) Insert(ctx context.Context, ticket tixer.Ticket) (tixer.Ticket, error) {
-	query := `INSERT INTO ` + ticketsTable +
-		` (public_id, title, price) VALUES ($1, $2, $3)
-        RETURNING id, public_id, title, price, version, created_at, updated_at`
-
-	args := []any{ticket.PublicID, ticket.Title, ticket.Price}
+	query := `WITH t AS (INSERT INTO ` + ticketsTable +
+		` (public_id, tenant_id, user_id, event_id, seat_id, title, status, price, currency, refund_deadline, refund_percentage, capacity) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+        RETURNING id, public_id, user_id, event_id, seat_id, title, status, price, currency, refund_deadline, refund_percentage, capacity, sold, held, version, created_at, updated_at)` +
+		` SELECT t.id, t.public_id, t.user_id, t.event_id, e.public_id, t.seat_id, st.public_id, t.title, t.status, t.price, t.currency, t.refund_deadline, t.refund_percentage, t.capacity, t.sold, t.held, t.version, t.created_at, t.updated_at` +
+		` FROM t LEFT JOIN ` + eventsTable + ` e ON e.id = t.event_id` +
+		` LEFT JOIN ` + seatsTable + ` st ON st.id = t.seat_id`
 
 	queryCtx, cancel := context.WithTimeout(ctx, tr.QueryTimeout)
 	defer cancel()
 
+	tx, tenantID, err := beginTenantTx(queryCtx, tr.DB)
+	if err != nil {
+		return tixer.Ticket{}, err
+	}
+	defer tx.Rollback(queryCtx)
+
+	args := []any{ticket.PublicID, tenantID, ticket.OwnerID, ticket.EventID, ticket.SeatID, ticket.Title, ticket.Status, ticket.Price.Amount, ticket.Price.Currency, ticket.RefundDeadline, ticket.RefundPercentage, ticket.Capacity}
+
 	var createdTicket tixer.Ticket
-	if err := tr.DB.QueryRow(queryCtx, query, args...).Scan(
+	if err := tx.QueryRow(queryCtx, query, args...).Scan(
 		&createdTicket.ID,
 		&createdTicket.PublicID,
+		&createdTicket.OwnerID,
+		&createdTicket.EventID,
+		&createdTicket.EventPublicID,
+		&createdTicket.SeatID,
+		&createdTicket.SeatPublicID,
 		&createdTicket.Title,
-		&createdTicket.Price,
+		&createdTicket.Status,
+		&createdTicket.Price.Amount,
+		&createdTicket.Price.Currency,
+		&createdTicket.RefundDeadline,
+		&createdTicket.RefundPercentage,
+		&createdTicket.Capacity,
+		&createdTicket.Sold,
+		&createdTicket.Held,
 		&createdTicket.Version,
 		&createdTicket.CreatedAt,
 		&createdTicket.UpdatedAt,
 	); err != nil {
-		return tixer.Ticket{}, fmt.Errorf("failed to insert ticket in database: %w", err)
+		var pgErr *pgconn.PgError
+		switch {
+		case errors.As(err, &pgErr) && pgErr.ConstraintName == "tickets_event_id_seat_id_key":
+			return tixer.Ticket{}, ErrDbSeatTaken
+		default:
+			return tixer.Ticket{}, fmt.Errorf("failed to insert ticket in database: %w", err)
+		}
+	}
+	createdTicket.SettleStatus()
+
+	createdTicket.PricingRules, err = replacePricingRules(queryCtx, tx, createdTicket.ID, ticket.PricingRules)
+	if err != nil {
+		return tixer.Ticket{}, err
+	}
+
+	createdTicket.PriceTiers, err = replacePriceTiers(queryCtx, tx, createdTicket.ID, ticket.PriceTiers, createdTicket.Price.Currency)
+	if err != nil {
+		return tixer.Ticket{}, err
+	}
+
+	if err := tx.Commit(queryCtx); err != nil {
+		return tixer.Ticket{}, fmt.Errorf("failed to commit ticket transaction: %w", err)
 	}
 
 	return createdTicket, nil
@@ -61,21 +143,47 @@ func (tr *TicketRepository) Insert(ctx context.Context, ticket tixer.Ticket) (ti
 
 // SelectOne reads a ticket from the database.
 func (tr *TicketRepository) SelectOne(ctx context.Context, id tixer.PublicID) (tixer.Ticket, error) {
-	query := `SELECT id, public_id, title, price, version, created_at, updated_at FROM ` + ticketsTable +
-		` WHERE public_id = $1`
+	query := `SELECT t.id, t.public_id, t.user_id, t.event_id, e.public_id, t.seat_id, st.public_id, t.title, t.status, t.price, t.currency, t.refund_deadline, t.refund_percentage, t.capacity, t.sold, t.held, ` + pendingColumn + `, t.version, t.created_at, t.updated_at, ` + pricingRulesColumns + `, ` + priceTiersColumn +
+		` FROM ` + ticketsTable + ` t LEFT JOIN ` + eventsTable + ` e ON e.id = t.event_id` +
+		` LEFT JOIN ` + seatsTable + ` st ON st.id = t.seat_id` +
+		` WHERE t.public_id = $1 AND t.tenant_id = $2`
 
 	queryCtx, cancel := context.WithTimeout(ctx, tr.QueryTimeout)
 	defer cancel()
 
+	tx, tenantID, err := beginTenantTx(queryCtx, tr.DB)
+	if err != nil {
+		return tixer.Ticket{}, err
+	}
+	defer tx.Rollback(queryCtx)
+
 	var ticket tixer.Ticket
-	if err := tr.DB.QueryRow(queryCtx, query, id).Scan(
+	var soldAbove, adjustPercent []int32
+	var tiers []priceTierRow
+	if err := tx.QueryRow(queryCtx, query, id, tenantID).Scan(
 		&ticket.ID,
 		&ticket.PublicID,
+		&ticket.OwnerID,
+		&ticket.EventID,
+		&ticket.EventPublicID,
+		&ticket.SeatID,
+		&ticket.SeatPublicID,
 		&ticket.Title,
-		&ticket.Price,
+		&ticket.Status,
+		&ticket.Price.Amount,
+		&ticket.Price.Currency,
+		&ticket.RefundDeadline,
+		&ticket.RefundPercentage,
+		&ticket.Capacity,
+		&ticket.Sold,
+		&ticket.Held,
+		&ticket.Pending,
 		&ticket.Version,
 		&ticket.CreatedAt,
 		&ticket.UpdatedAt,
+		&soldAbove,
+		&adjustPercent,
+		&tiers,
 	); err != nil {
 		switch {
 		case errors.Is(err, pgx.ErrNoRows):
@@ -84,31 +192,45 @@ func (tr *TicketRepository) SelectOne(ctx context.Context, id tixer.PublicID) (t
 			return tixer.Ticket{}, fmt.Errorf("failed to select ticket from database: %w", err)
 		}
 	}
+	ticket.PricingRules = toPricingRules(soldAbove, adjustPercent)
+	ticket.PriceTiers = toPriceTiers(tiers, ticket.Price.Currency)
+	ticket.SettleStatus()
 
-	return ticket, nil
-}
+	if err := tx.Commit(queryCtx); err != nil {
+		return tixer.Ticket{}, fmt.Errorf("failed to commit ticket transaction: %w", err)
+	}
 
-type TicketFilter struct {
-	Title         string
-	Limit         int
-	Offset        int
-	SortColumn    string
-	SortDirection string
+	return ticket, nil
 }
 
 // SelectMultiple reads tickets based on filters from the database.
-func (tr *TicketRepository) SelectMultiple(ctx context.Context, filter TicketFilter) ([]tixer.Ticket, Pagination, error) {
-	query := fmt.Sprintf(`SELECT count(*) OVER(), id, public_id, title, price, version, created_at, updated_at `+
-		` FROM `+ticketsTable+
-		` WHERE (to_tsvector('simple', title) @@ plainto_tsquery('simple', $1) OR $1 = '') `+
-		` ORDER BY %s %s LIMIT $2 OFFSET $3`, filter.SortColumn, filter.SortDirection)
+func (tr *TicketRepository) SelectMultiple(ctx context.Context, filter tixer.TicketFilter) ([]tixer.Ticket, Pagination, error) {
+	// Titles are sorted by byte order rather than by the collation of the database, like the memory store,
+	// and the ties are broken by ID so that paging through them is stable.
+	sortColumn := "t." + filter.SortColumn
+	if filter.SortColumn == "title" {
+		sortColumn += ` COLLATE "C"`
+	}
 
-	args := []any{filter.Title, filter.Limit, filter.Offset}
+	query := fmt.Sprintf(`SELECT count(*) OVER(), t.id, t.public_id, t.user_id, t.event_id, e.public_id, t.seat_id, st.public_id, t.title, t.status, t.price, t.currency, t.refund_deadline, t.refund_percentage, t.capacity, t.sold, t.held, `+pendingColumn+`, t.version, t.created_at, t.updated_at, `+pricingRulesColumns+`, `+priceTiersColumn+
+		` FROM `+ticketsTable+` t LEFT JOIN `+eventsTable+` e ON e.id = t.event_id`+
+		` LEFT JOIN `+seatsTable+` st ON st.id = t.seat_id`+
+		` WHERE (to_tsvector('simple', t.title) @@ plainto_tsquery('simple', $1) OR $1 = '') `+
+		` AND (e.public_id = $2 OR $2 IS NULL) AND t.tenant_id = $5`+
+		` ORDER BY %s %s, t.id ASC LIMIT $3 OFFSET $4`, sortColumn, filter.SortDirection)
 
 	queryCtx, cancel := context.WithTimeout(ctx, tr.QueryTimeout)
 	defer cancel()
 
-	rows, err := tr.DB.Query(queryCtx, query, args...)
+	tx, tenantID, err := beginTenantTx(queryCtx, tr.DB)
+	if err != nil {
+		return nil, Pagination{}, err
+	}
+	defer tx.Rollback(queryCtx)
+
+	args := []any{filter.Title, filter.EventID, filter.Limit, filter.Offset, tenantID}
+
+	rows, err := tx.Query(queryCtx, query, args...)
 	if err != nil {
 		return nil, Pagination{}, fmt.Errorf("failed to select tickets from database: %w", err)
 	}
@@ -120,20 +242,41 @@ func (tr *TicketRepository) SelectMultiple(ctx context.Context, filter TicketFil
 
 	for rows.Next() {
 		var ticket tixer.Ticket
+		var soldAbove, adjustPercent []int32
+		var tiers []priceTierRow
 
 		err := rows.Scan(
 			&totalRecords,
 			&ticket.ID,
 			&ticket.PublicID,
+			&ticket.OwnerID,
+			&ticket.EventID,
+			&ticket.EventPublicID,
+			&ticket.SeatID,
+			&ticket.SeatPublicID,
 			&ticket.Title,
-			&ticket.Price,
+			&ticket.Status,
+			&ticket.Price.Amount,
+			&ticket.Price.Currency,
+			&ticket.RefundDeadline,
+			&ticket.RefundPercentage,
+			&ticket.Capacity,
+			&ticket.Sold,
+			&ticket.Held,
+			&ticket.Pending,
 			&ticket.Version,
 			&ticket.CreatedAt,
 			&ticket.UpdatedAt,
+			&soldAbove,
+			&adjustPercent,
+			&tiers,
 		)
 		if err != nil {
 			return nil, Pagination{}, fmt.Errorf("failed to scan row result: %w", err)
 		}
+		ticket.PricingRules = toPricingRules(soldAbove, adjustPercent)
+		ticket.PriceTiers = toPriceTiers(tiers, ticket.Price.Currency)
+		ticket.SettleStatus()
 
 		tickets = append(tickets, ticket)
 	}
@@ -142,50 +285,285 @@ func (tr *TicketRepository) SelectMultiple(ctx context.Context, filter TicketFil
 		return nil, Pagination{}, fmt.Errorf("failed to iterate over rows result: %w", err)
 	}
 
-	pagination := calculatePagination(totalRecords, filter.Offset, filter.Limit)
+	if err := tx.Commit(queryCtx); err != nil {
+		return nil, Pagination{}, fmt.Errorf("failed to commit tickets transaction: %w", err)
+	}
+
+	pagination := calculatePagination(totalRecords, filter.Offset/filter.Limit+1, filter.Limit)
 
 	return tickets, pagination, nil
 }
 
-// Update updates a ticket in the database
+// Update updates a ticket and replaces its price tiers and pricing rules in the database.
+// The sold, held and pending counts are only changed by orders and waitlists and are read back;
+// the status is only changed by UpdateStatus and is settled against them.
+// It returns ErrDbEditConflict if the ticket was changed in the meantime.
 func (tr *TicketRepository) Update(ctx context.Context, ticket *tixer.Ticket) error {
-	query := `UPDATE ` + ticketsTable +
-		` SET title = $1, price = $2, version = version + 1, updated_at = $3` +
-		` WHERE public_id = $4 AND version = $5 RETURNING version`
-
-	args := []any{ticket.Title, ticket.Price, time.Now(), ticket.PublicID, ticket.Version}
+	query := `UPDATE ` + ticketsTable + ` t` +
+		` SET title = $1, price = $2, currency = $3, refund_deadline = $4, refund_percentage = $5, capacity = $6, version = version + 1, updated_at = $7` +
+		` WHERE public_id = $8 AND version = $9 AND tenant_id = $10 RETURNING t.status, t.sold, t.held, ` + pendingColumn + `, t.version`
 
 	queryCtx, cancel := context.WithTimeout(ctx, tr.QueryTimeout)
 	defer cancel()
 
-	if err := tr.DB.QueryRow(queryCtx, query, args...).Scan(&ticket.Version); err != nil {
+	tx, tenantID, err := beginTenantTx(queryCtx, tr.DB)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(queryCtx)
+
+	args := []any{ticket.Title, ticket.Price.Amount, ticket.Price.Currency, ticket.RefundDeadline, ticket.RefundPercentage, ticket.Capacity, time.Now(), ticket.PublicID, ticket.Version, tenantID}
+
+	if err := tx.QueryRow(queryCtx, query, args...).Scan(&ticket.Status, &ticket.Sold, &ticket.Held, &ticket.Pending, &ticket.Version); err != nil {
+		var pgErr *pgconn.PgError
 		switch {
 		case errors.Is(err, pgx.ErrNoRows):
 			return ErrDbEditConflict
+		case errors.As(err, &pgErr) && (pgErr.ConstraintName == "tickets_sold_check" || pgErr.ConstraintName == "tickets_held_check"):
+			// units were sold or held since the ticket was read, leaving the new capacity below them
+			return ErrDbEditConflict
 		default:
 			return fmt.Errorf("failed to update ticket in database: %w", err)
 		}
 	}
 
+	ticket.SettleStatus()
+
+	ticket.PricingRules, err = replacePricingRules(queryCtx, tx, ticket.ID, ticket.PricingRules)
+	if err != nil {
+		return err
+	}
+
+	ticket.PriceTiers, err = replacePriceTiers(queryCtx, tx, ticket.ID, ticket.PriceTiers, ticket.Price.Currency)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(queryCtx); err != nil {
+		return fmt.Errorf("failed to commit ticket transaction: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateStatus stores the status the ticket was moved to by its owner. The reserved and sold
+// states are not stored, they are the published state settled against the units taken.
+// It returns ErrDbEditConflict if the ticket was changed in the meantime.
+func (tr *TicketRepository) UpdateStatus(ctx context.Context, ticket *tixer.Ticket) error {
+	status := ticket.Status
+	if status.Published() {
+		status = tixer.TicketPublished
+	}
+
+	query := `UPDATE ` + ticketsTable + ` SET status = $1, version = version + 1, updated_at = NOW()` +
+		` WHERE id = $2 AND version = $3 AND tenant_id = $4 RETURNING version, updated_at`
+
+	queryCtx, cancel := context.WithTimeout(ctx, tr.QueryTimeout)
+	defer cancel()
+
+	tx, tenantID, err := beginTenantTx(queryCtx, tr.DB)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(queryCtx)
+
+	if err := tx.QueryRow(queryCtx, query, status, ticket.ID, ticket.Version, tenantID).Scan(&ticket.Version, &ticket.UpdatedAt); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return ErrDbEditConflict
+		default:
+			return fmt.Errorf("failed to update ticket status in database: %w", err)
+		}
+	}
+
+	if err := tx.Commit(queryCtx); err != nil {
+		return fmt.Errorf("failed to commit ticket transaction: %w", err)
+	}
+
+	ticket.SettleStatus()
+
 	return nil
 }
 
 // Delete deletes a ticket from the database.
 func (tr *TicketRepository) Delete(ctx context.Context, id tixer.PublicID) error {
 	query := `DELETE FROM ` + ticketsTable +
-		` WHERE public_id = $1`
+		` WHERE public_id = $1 AND tenant_id = $2`
 
 	queryCtx, cancel := context.WithTimeout(ctx, tr.QueryTimeout)
 	defer cancel()
 
-	res, err := tr.DB.Exec(queryCtx, query, id)
+	tx, tenantID, err := beginTenantTx(queryCtx, tr.DB)
 	if err != nil {
-		return fmt.Errorf("failed to delete ticket from database: %w", err)
+		return err
+	}
+	defer tx.Rollback(queryCtx)
+
+	res, err := tx.Exec(queryCtx, query, id, tenantID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		switch {
+		case errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation:
+			return ErrDbRecordInUse
+		default:
+			return fmt.Errorf("failed to delete ticket from database: %w", err)
+		}
 	}
 
 	if res.RowsAffected() == 0 {
 		return ErrDbRecordNotFound
 	}
 
+	if err := tx.Commit(queryCtx); err != nil {
+		return fmt.Errorf("failed to commit ticket transaction: %w", err)
+	}
+
+	return nil
+}
+
+// lockTickets locks the rows of the tickets in ID order until the transaction ends. The paths that
+// update several tickets in one transaction call it first, so they always take the locks in the same
+// order and cannot deadlock each other.
+func lockTickets(ctx context.Context, tx pgx.Tx, ticketIDs []int64) error {
+	query := `SELECT id FROM ` + ticketsTable + ` WHERE id = ANY($1) ORDER BY id FOR UPDATE`
+
+	if _, err := tx.Exec(ctx, query, ticketIDs); err != nil {
+		return fmt.Errorf("failed to lock tickets in database: %w", err)
+	}
+
+	return nil
+}
+
+// replacePricingRules replaces the pricing rules of the ticket and returns them ordered by threshold.
+// It must run in the transaction that inserts or updates the ticket.
+func replacePricingRules(ctx context.Context, tx pgx.Tx, ticketID int64, rules []tixer.PricingRule) ([]tixer.PricingRule, error) {
+	deleteQuery := `DELETE FROM ` + ticketPricingRulesTable + ` WHERE ticket_id = $1`
+
+	if _, err := tx.Exec(ctx, deleteQuery, ticketID); err != nil {
+		return nil, fmt.Errorf("failed to delete pricing rules from database: %w", err)
+	}
+
+	soldAbove := make([]int32, len(rules))
+	adjustPercent := make([]int32, len(rules))
+	for i, rule := range rules {
+		soldAbove[i] = rule.SoldAbove
+		adjustPercent[i] = rule.AdjustPercent
+	}
+
+	insertQuery := `WITH r AS (INSERT INTO ` + ticketPricingRulesTable + ` (ticket_id, sold_above, adjust_percent)` +
+		` SELECT $1, unnest($2::integer[]), unnest($3::integer[]) RETURNING sold_above, adjust_percent)` +
+		` SELECT COALESCE(array_agg(sold_above ORDER BY sold_above), '{}'), COALESCE(array_agg(adjust_percent ORDER BY sold_above), '{}') FROM r`
+
+	if err := tx.QueryRow(ctx, insertQuery, ticketID, soldAbove, adjustPercent).Scan(&soldAbove, &adjustPercent); err != nil {
+		return nil, fmt.Errorf("failed to insert pricing rules in database: %w", err)
+	}
+
+	return toPricingRules(soldAbove, adjustPercent), nil
+}
+
+// toPricingRules combines the thresholds and adjustments read by pricingRulesColumns.
+func toPricingRules(soldAbove, adjustPercent []int32) []tixer.PricingRule {
+	rules := make([]tixer.PricingRule, len(soldAbove))
+	for i := range soldAbove {
+		rules[i] = tixer.PricingRule{SoldAbove: soldAbove[i], AdjustPercent: adjustPercent[i]}
+	}
+
+	return rules
+}
+
+// replacePriceTiers replaces the price tiers of the ticket and returns them ordered by start.
+// Tiers are matched by name, so a tier that is kept keeps the count of the units sold at its price.
+// It returns ErrDbEditConflict if units were sold at a tier since it was read, leaving its quantity below them
+// or making it a tier that cannot be removed because orders point to it.
+// It must run in the transaction that inserts or updates the ticket.
+func replacePriceTiers(ctx context.Context, tx pgx.Tx, ticketID int64, tiers []tixer.PriceTier, currency tixer.Currency) ([]tixer.PriceTier, error) {
+	names := make([]string, len(tiers))
+	prices := make([]int64, len(tiers))
+	startsAt := make([]time.Time, len(tiers))
+	quantities := make([]*int32, len(tiers))
+	for i, tier := range tiers {
+		names[i] = tier.Name
+		prices[i] = tier.Price.Amount
+		startsAt[i] = tier.StartsAt
+		quantities[i] = tier.Quantity
+	}
+
+	// The sold count of a tier is never given back, so a removed tier that is left after the guarded
+	// delete had units sold at its price.
+	deleteQuery := `DELETE FROM ` + ticketPriceTiersTable + ` WHERE ticket_id = $1 AND name <> ALL($2::text[]) AND sold = 0`
+
+	if _, err := tx.Exec(ctx, deleteQuery, ticketID, names); err != nil {
+		return nil, fmt.Errorf("failed to delete price tiers from database: %w", err)
+	}
+
+	soldQuery := `SELECT EXISTS (SELECT 1 FROM ` + ticketPriceTiersTable + ` WHERE ticket_id = $1 AND name <> ALL($2::text[]))`
+
+	var removedSold bool
+	if err := tx.QueryRow(ctx, soldQuery, ticketID, names).Scan(&removedSold); err != nil {
+		return nil, fmt.Errorf("failed to check price tiers in database: %w", err)
+	}
+	if removedSold {
+		return nil, ErrDbEditConflict
+	}
+
+	upsertQuery := `INSERT INTO ` + ticketPriceTiersTable + ` (ticket_id, name, price, starts_at, quantity)` +
+		` SELECT $1, unnest($2::text[]), unnest($3::bigint[]), unnest($4::timestamptz[]), unnest($5::integer[])` +
+		` ON CONFLICT (ticket_id, name) DO UPDATE SET price = EXCLUDED.price, starts_at = EXCLUDED.starts_at, quantity = EXCLUDED.quantity`
+
+	if _, err := tx.Exec(ctx, upsertQuery, ticketID, names, prices, startsAt, quantities); err != nil {
+		var pgErr *pgconn.PgError
+		switch {
+		case errors.As(err, &pgErr) && pgErr.ConstraintName == "ticket_price_tiers_sold_check":
+			return nil, ErrDbEditConflict
+		default:
+			return nil, fmt.Errorf("failed to insert price tiers in database: %w", err)
+		}
+	}
+
+	selectQuery := `SELECT ` + priceTiersColumn + ` FROM ` + ticketsTable + ` t WHERE t.id = $1`
+
+	var rows []priceTierRow
+	if err := tx.QueryRow(ctx, selectQuery, ticketID).Scan(&rows); err != nil {
+		return nil, fmt.Errorf("failed to select price tiers from database: %w", err)
+	}
+
+	return toPriceTiers(rows, currency), nil
+}
+
+// sellAtPriceTier counts a unit of the ticket as sold at the price tier. The guarded increment
+// locks the tier row until the transaction ends, so concurrent orders at a capped tier are
+// serialized and can never sell more units than its quantity.
+// It returns ErrDbPriceTierSoldOut if the units of the tier were sold out or it no longer exists.
+// It must run in the transaction that inserts the order for the unit.
+func sellAtPriceTier(ctx context.Context, tx pgx.Tx, tierID, ticketID int64) error {
+	query := `UPDATE ` + ticketPriceTiersTable + ` SET sold = sold + 1` +
+		` WHERE id = $1 AND ticket_id = $2 AND (quantity IS NULL OR sold < quantity)`
+
+	res, err := tx.Exec(ctx, query, tierID, ticketID)
+	if err != nil {
+		return fmt.Errorf("failed to sell unit at price tier in database: %w", err)
+	}
+
+	if res.RowsAffected() == 0 {
+		return ErrDbPriceTierSoldOut
+	}
+
 	return nil
 }
+
+// toPriceTiers converts the price tiers read by priceTiersColumn, whose prices are in the ticket currency.
+func toPriceTiers(rows []priceTierRow, currency tixer.Currency) []tixer.PriceTier {
+	tiers := make([]tixer.PriceTier, len(rows))
+	for i, row := range rows {
+		tiers[i] = tixer.PriceTier{
+			ID:       row.ID,
+			Name:     row.Name,
+			Price:    tixer.Money{Amount: row.Price, Currency: currency},
+			StartsAt: row.StartsAt,
+			Quantity: row.Quantity,
+			Sold:     row.Sold,
+		}
+	}
+
+	return tiers
+}
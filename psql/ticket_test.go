@@ -0,0 +1,72 @@
+package psql_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	tixer "github.com/mroobert/monorepo-tixer"
+	"github.com/mroobert/monorepo-tixer/psql"
+	"github.com/mroobert/monorepo-tixer/tixertest"
+)
+
+func TestTicketRepository(t *testing.T) {
+	pool := newTestPool(t)
+	ctx := context.Background()
+
+	tixertest.TestTicketStore(t, tixertest.TicketStoreFixture{
+		Store: psql.NewTicketRepository(pool, testQueryTimeout),
+		NewTenant: func(t *testing.T) int64 {
+			t.Helper()
+
+			tenantDB, err := psql.NewTenantRepository(pool, testQueryTimeout).Insert(ctx, tixer.Tenant{
+				PublicID: newPublicID(t),
+				Name:     "Ticket store test organizer",
+			})
+			if err != nil {
+				t.Fatalf("inserting tenant: %v", err)
+			}
+
+			return tenantDB.ID
+		},
+		NewSeat: func(t *testing.T) (int64, int64) {
+			t.Helper()
+
+			venueDB, err := psql.NewVenueRepository(pool, testQueryTimeout).Insert(ctx, tixer.Venue{
+				PublicID: newPublicID(t),
+				Name:     "Ticket store test venue",
+				Address:  "1 Test Street",
+				Capacity: 1,
+			})
+			if err != nil {
+				t.Fatalf("inserting venue: %v", err)
+			}
+
+			seatMap := tixer.SeatMap{Sections: []tixer.Section{{
+				Name: "Stalls",
+				Rows: []tixer.SeatRow{{
+					Label: "A",
+					Seats: []tixer.Seat{{PublicID: newPublicID(t), Number: 1, Category: "standard"}},
+				}},
+			}}}
+			if err := psql.NewSeatRepository(pool, testQueryTimeout).ReplaceSeatMap(ctx, venueDB.ID, &seatMap); err != nil {
+				t.Fatalf("inserting seat map: %v", err)
+			}
+
+			startsAt := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+			eventDB, err := psql.NewEventRepository(pool, testQueryTimeout).Insert(ctx, tixer.Event{
+				PublicID: newPublicID(t),
+				VenueID:  &venueDB.ID,
+				Name:     "Ticket store test event",
+				StartsAt: startsAt,
+				EndsAt:   startsAt.Add(2 * time.Hour),
+				TimeZone: "UTC",
+			})
+			if err != nil {
+				t.Fatalf("inserting event: %v", err)
+			}
+
+			return eventDB.ID, seatMap.Sections[0].Rows[0].Seats[0].ID
+		},
+	})
+}
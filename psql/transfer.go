@@ -0,0 +1,278 @@
+package psql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	tixer "github.com/mroobert/monorepo-tixer"
+)
+
+var ErrDbTransferPending = errors.New("db transfer pending")
+
+const transfersTable = "ticket_transfers"
+
+// transferColumns lists the columns read by scanTransfer, for a ticket_transfers table
+// aliased tr joined to its ticket t and the user fu that sent it.
+const transferColumns = `tr.id, tr.public_id, tr.ticket_id, t.public_id, tr.from_user_id, fu.email,` +
+	` tr.to_email, tr.to_user_id, tr.status, tr.created_at, tr.responded_at`
+
+// transferJoins joins a ticket_transfers table aliased tr to the tables read by transferColumns.
+const transferJoins = ` JOIN ` + ticketsTable + ` t ON t.id = tr.ticket_id` +
+	` JOIN ` + usersTable + ` fu ON fu.id = tr.from_user_id`
+
+// TransferRepository persists the transfers of purchased tickets between users in the database.
+type TransferRepository struct {
+	DB           *pgxpool.Pool
+	QueryTimeout time.Duration
+}
+
+func NewTransferRepository(db *pgxpool.Pool, queryTimeout time.Duration) *TransferRepository {
+	return &TransferRepository{
+		DB:           db,
+		QueryTimeout: queryTimeout,
+	}
+}
+
+// Insert inserts a new pending transfer in the database.
+// It returns ErrDbTransferPending if the ticket is already on its way to a recipient.
+func (tr *TransferRepository) Insert(ctx context.Context, transfer tixer.Transfer) (tixer.Transfer, error) {
+	query := `WITH tr AS (INSERT INTO ` + transfersTable +
+		` (public_id, ticket_id, from_user_id, to_email, status) VALUES ($1, $2, $3, $4, $5) RETURNING *)` +
+		` SELECT ` + transferColumns + ` FROM tr` + transferJoins
+
+	args := []any{transfer.PublicID, transfer.TicketID, transfer.FromUserID, transfer.ToEmail, tixer.TransferPending}
+
+	queryCtx, cancel := context.WithTimeout(ctx, tr.QueryTimeout)
+	defer cancel()
+
+	tx, err := beginScopedTx(queryCtx, tr.DB)
+	if err != nil {
+		return tixer.Transfer{}, err
+	}
+	defer tx.Rollback(queryCtx)
+
+	createdTransfer, err := scanTransfer(tx.QueryRow(queryCtx, query, args...))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		switch {
+		case errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation && pgErr.ConstraintName == "ticket_transfers_pending_ticket_id_idx":
+			return tixer.Transfer{}, ErrDbTransferPending
+		default:
+			return tixer.Transfer{}, fmt.Errorf("failed to insert transfer in database: %w", err)
+		}
+	}
+
+	if err := tx.Commit(queryCtx); err != nil {
+		return tixer.Transfer{}, fmt.Errorf("failed to commit transfer transaction: %w", err)
+	}
+
+	return createdTransfer, nil
+}
+
+// SelectOne reads a transfer from the database.
+func (tr *TransferRepository) SelectOne(ctx context.Context, id tixer.PublicID) (tixer.Transfer, error) {
+	query := `SELECT ` + transferColumns + ` FROM ` + transfersTable + ` tr` + transferJoins +
+		` WHERE tr.public_id = $1`
+
+	queryCtx, cancel := context.WithTimeout(ctx, tr.QueryTimeout)
+	defer cancel()
+
+	tx, err := beginScopedTx(queryCtx, tr.DB)
+	if err != nil {
+		return tixer.Transfer{}, err
+	}
+	defer tx.Rollback(queryCtx)
+
+	transfer, err := scanTransfer(tx.QueryRow(queryCtx, query, id))
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return tixer.Transfer{}, ErrDbRecordNotFound
+		default:
+			return tixer.Transfer{}, fmt.Errorf("failed to select transfer from database: %w", err)
+		}
+	}
+
+	if err := tx.Commit(queryCtx); err != nil {
+		return tixer.Transfer{}, fmt.Errorf("failed to commit transfer transaction: %w", err)
+	}
+
+	return transfer, nil
+}
+
+type TransferFilter struct {
+	UserID int64  // only the transfers sent by this user
+	Email  string // or addressed to this email are read
+	Limit  int
+	Offset int
+}
+
+// SelectMultiple reads the transfer history of a user from the database, the newest first.
+func (tr *TransferRepository) SelectMultiple(ctx context.Context, filter TransferFilter) ([]tixer.Transfer, Pagination, error) {
+	query := `SELECT count(*) OVER(), ` + transferColumns + ` FROM ` + transfersTable + ` tr` + transferJoins +
+		` WHERE tr.from_user_id = $1 OR tr.to_email = $2` +
+		` ORDER BY tr.id DESC LIMIT $3 OFFSET $4`
+
+	queryCtx, cancel := context.WithTimeout(ctx, tr.QueryTimeout)
+	defer cancel()
+
+	tx, err := beginScopedTx(queryCtx, tr.DB)
+	if err != nil {
+		return nil, Pagination{}, err
+	}
+	defer tx.Rollback(queryCtx)
+
+	rows, err := tx.Query(queryCtx, query, filter.UserID, filter.Email, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, Pagination{}, fmt.Errorf("failed to select transfers from database: %w", err)
+	}
+
+	defer rows.Close()
+
+	totalRecords := 0
+	transfers := []tixer.Transfer{}
+
+	for rows.Next() {
+		var transfer tixer.Transfer
+
+		err := rows.Scan(
+			&totalRecords,
+			&transfer.ID,
+			&transfer.PublicID,
+			&transfer.TicketID,
+			&transfer.TicketPublicID,
+			&transfer.FromUserID,
+			&transfer.FromEmail,
+			&transfer.ToEmail,
+			&transfer.ToUserID,
+			&transfer.Status,
+			&transfer.CreatedAt,
+			&transfer.RespondedAt,
+		)
+		if err != nil {
+			return nil, Pagination{}, fmt.Errorf("failed to scan row result: %w", err)
+		}
+
+		transfers = append(transfers, transfer)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, Pagination{}, fmt.Errorf("failed to iterate over rows result: %w", err)
+	}
+
+	if err := tx.Commit(queryCtx); err != nil {
+		return nil, Pagination{}, fmt.Errorf("failed to commit transfers transaction: %w", err)
+	}
+
+	pagination := calculatePagination(totalRecords, filter.Offset/filter.Limit+1, filter.Limit)
+
+	return transfers, pagination, nil
+}
+
+// Accept completes a pending transfer: the ticket moves to the recipient and gets a new version in the
+// same transaction that marks the transfer accepted. Like TicketRepository.Update, the ticket is only
+// changed if it still has the version it was read with, is still owned by the sender and belongs to the
+// tenant carried by ctx; otherwise, or if the transfer is no longer pending, it returns ErrDbEditConflict.
+func (tr *TransferRepository) Accept(ctx context.Context, transfer *tixer.Transfer, ticket *tixer.Ticket, recipient tixer.User) error {
+	queryCtx, cancel := context.WithTimeout(ctx, tr.QueryTimeout)
+	defer cancel()
+
+	tx, tenantID, err := beginTenantTx(queryCtx, tr.DB)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(queryCtx)
+
+	if err := respondToTransfer(queryCtx, tx, transfer, tixer.TransferAccepted, &recipient.ID); err != nil {
+		return err
+	}
+
+	ticketQuery := `UPDATE ` + ticketsTable + ` SET user_id = $1, version = version + 1, updated_at = NOW()` +
+		` WHERE id = $2 AND version = $3 AND user_id = $4 AND tenant_id = $5 RETURNING user_id, version, updated_at`
+
+	args := []any{recipient.ID, ticket.ID, ticket.Version, transfer.FromUserID, tenantID}
+
+	if err := tx.QueryRow(queryCtx, ticketQuery, args...).Scan(&ticket.OwnerID, &ticket.Version, &ticket.UpdatedAt); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return ErrDbEditConflict
+		default:
+			return fmt.Errorf("failed to transfer ticket in database: %w", err)
+		}
+	}
+
+	if err := tx.Commit(queryCtx); err != nil {
+		return fmt.Errorf("failed to commit transfer transaction: %w", err)
+	}
+
+	return nil
+}
+
+// Close ends a pending transfer without moving the ticket, with the declined or cancelled status.
+// The responder is the recipient that declined the transfer, nil if the sender cancelled it.
+// It returns ErrDbEditConflict if the transfer is no longer pending.
+func (tr *TransferRepository) Close(ctx context.Context, transfer *tixer.Transfer, status tixer.TransferStatus, responderID *int64) error {
+	queryCtx, cancel := context.WithTimeout(ctx, tr.QueryTimeout)
+	defer cancel()
+
+	tx, err := tr.DB.Begin(queryCtx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(queryCtx)
+
+	if err := respondToTransfer(queryCtx, tx, transfer, status, responderID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(queryCtx); err != nil {
+		return fmt.Errorf("failed to commit transfer transaction: %w", err)
+	}
+
+	return nil
+}
+
+// respondToTransfer moves a pending transfer to the given status, recording the recipient that answered it.
+// It returns ErrDbEditConflict if the transfer is no longer pending.
+func respondToTransfer(ctx context.Context, tx pgx.Tx, transfer *tixer.Transfer, status tixer.TransferStatus, responderID *int64) error {
+	query := `UPDATE ` + transfersTable + ` SET status = $1, to_user_id = $2, responded_at = NOW()` +
+		` WHERE id = $3 AND status = $4 RETURNING status, to_user_id, responded_at`
+
+	args := []any{status, responderID, transfer.ID, tixer.TransferPending}
+
+	if err := tx.QueryRow(ctx, query, args...).Scan(&transfer.Status, &transfer.ToUserID, &transfer.RespondedAt); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return ErrDbEditConflict
+		default:
+			return fmt.Errorf("failed to update transfer in database: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func scanTransfer(row pgx.Row) (tixer.Transfer, error) {
+	var transfer tixer.Transfer
+
+	err := row.Scan(
+		&transfer.ID,
+		&transfer.PublicID,
+		&transfer.TicketID,
+		&transfer.TicketPublicID,
+		&transfer.FromUserID,
+		&transfer.FromEmail,
+		&transfer.ToEmail,
+		&transfer.ToUserID,
+		&transfer.Status,
+		&transfer.CreatedAt,
+		&transfer.RespondedAt,
+	)
+
+	return transfer, err
+}
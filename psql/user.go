@@ -0,0 +1,111 @@
+package psql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	tixer "github.com/mroobert/monorepo-tixer"
+)
+
+var (
+	ErrDbDuplicateEmail = errors.New("db duplicate email")
+)
+
+const usersTable = "users"
+
+// UserRepository persists users in the database.
+type UserRepository struct {
+	DB           *pgxpool.Pool
+	QueryTimeout time.Duration
+}
+
+func NewUserRepository(db *pgxpool.Pool, queryTimeout time.Duration) *UserRepository {
+	return &UserRepository{
+		DB:           db,
+		QueryTimeout: queryTimeout,
+	}
+}
+
+// Insert inserts a new user in the database and grants it the buyer role.
+// It returns ErrDbDuplicateEmail if the email is already used by another user.
+func (ur *UserRepository) Insert(ctx context.Context, user tixer.User) (tixer.User, error) {
+	query := `WITH u AS (INSERT INTO ` + usersTable +
+		` (public_id, name, email, password_hash) VALUES ($1, $2, $3, $4)
+        RETURNING id, public_id, name, email, password_hash, version, created_at, updated_at),` +
+		` ur AS (INSERT INTO ` + userRolesTable + ` (user_id, role_id)` +
+		` SELECT u.id, r.id FROM u, ` + rolesTable + ` r WHERE r.name = $5)` +
+		` SELECT id, public_id, name, email, password_hash, version, created_at, updated_at FROM u`
+
+	args := []any{user.PublicID, user.Name, user.Email, user.PasswordHash, tixer.RoleBuyer}
+
+	queryCtx, cancel := context.WithTimeout(ctx, ur.QueryTimeout)
+	defer cancel()
+
+	var createdUser tixer.User
+	if err := ur.DB.QueryRow(queryCtx, query, args...).Scan(
+		&createdUser.ID,
+		&createdUser.PublicID,
+		&createdUser.Name,
+		&createdUser.Email,
+		&createdUser.PasswordHash,
+		&createdUser.Version,
+		&createdUser.CreatedAt,
+		&createdUser.UpdatedAt,
+	); err != nil {
+		var pgErr *pgconn.PgError
+		switch {
+		case errors.As(err, &pgErr) && pgErr.ConstraintName == "users_email_key":
+			return tixer.User{}, ErrDbDuplicateEmail
+		default:
+			return tixer.User{}, fmt.Errorf("failed to insert user in database: %w", err)
+		}
+	}
+
+	return createdUser, nil
+}
+
+// SelectOne reads a user from the database.
+func (ur *UserRepository) SelectOne(ctx context.Context, id tixer.PublicID) (tixer.User, error) {
+	return ur.selectBy(ctx, "public_id", id)
+}
+
+// SelectByEmail reads the user with the given email from the database.
+func (ur *UserRepository) SelectByEmail(ctx context.Context, email string) (tixer.User, error) {
+	return ur.selectBy(ctx, "email", email)
+}
+
+// selectBy reads a user by the value of a unique column from the database.
+func (ur *UserRepository) selectBy(ctx context.Context, column string, value any) (tixer.User, error) {
+	query := `SELECT id, public_id, name, email, password_hash, tenant_id, version, created_at, updated_at FROM ` + usersTable +
+		` WHERE ` + column + ` = $1`
+
+	queryCtx, cancel := context.WithTimeout(ctx, ur.QueryTimeout)
+	defer cancel()
+
+	var user tixer.User
+	if err := ur.DB.QueryRow(queryCtx, query, value).Scan(
+		&user.ID,
+		&user.PublicID,
+		&user.Name,
+		&user.Email,
+		&user.PasswordHash,
+		&user.TenantID,
+		&user.Version,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return tixer.User{}, ErrDbRecordNotFound
+		default:
+			return tixer.User{}, fmt.Errorf("failed to select user from database: %w", err)
+		}
+	}
+
+	return user, nil
+}
@@ -0,0 +1,196 @@
+package psql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	tixer "github.com/mroobert/monorepo-tixer"
+)
+
+const venuesTable = "venues"
+
+// VenueRepository persists venues in the database.
+type VenueRepository struct {
+	DB           *pgxpool.Pool
+	QueryTimeout time.Duration
+}
+
+func NewVenueRepository(db *pgxpool.Pool, queryTimeout time.Duration) *VenueRepository {
+	return &VenueRepository{
+		DB:           db,
+		QueryTimeout: queryTimeout,
+	}
+}
+
+// Insert inserts a new venue in the database.
+func (vr *VenueRepository) Insert(ctx context.Context, venue tixer.Venue) (tixer.Venue, error) {
+	query := `INSERT INTO ` + venuesTable +
+		` (public_id, user_id, name, address, capacity) VALUES ($1, $2, $3, $4, $5)
+        RETURNING id, public_id, user_id, name, address, capacity, version, created_at, updated_at`
+
+	args := []any{venue.PublicID, venue.OwnerID, venue.Name, venue.Address, venue.Capacity}
+
+	queryCtx, cancel := context.WithTimeout(ctx, vr.QueryTimeout)
+	defer cancel()
+
+	var createdVenue tixer.Venue
+	if err := vr.DB.QueryRow(queryCtx, query, args...).Scan(
+		&createdVenue.ID,
+		&createdVenue.PublicID,
+		&createdVenue.OwnerID,
+		&createdVenue.Name,
+		&createdVenue.Address,
+		&createdVenue.Capacity,
+		&createdVenue.Version,
+		&createdVenue.CreatedAt,
+		&createdVenue.UpdatedAt,
+	); err != nil {
+		return tixer.Venue{}, fmt.Errorf("failed to insert venue in database: %w", err)
+	}
+
+	return createdVenue, nil
+}
+
+// SelectOne reads a venue from the database.
+func (vr *VenueRepository) SelectOne(ctx context.Context, id tixer.PublicID) (tixer.Venue, error) {
+	query := `SELECT id, public_id, user_id, name, address, capacity, version, created_at, updated_at FROM ` + venuesTable +
+		` WHERE public_id = $1`
+
+	queryCtx, cancel := context.WithTimeout(ctx, vr.QueryTimeout)
+	defer cancel()
+
+	var venue tixer.Venue
+	if err := vr.DB.QueryRow(queryCtx, query, id).Scan(
+		&venue.ID,
+		&venue.PublicID,
+		&venue.OwnerID,
+		&venue.Name,
+		&venue.Address,
+		&venue.Capacity,
+		&venue.Version,
+		&venue.CreatedAt,
+		&venue.UpdatedAt,
+	); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return tixer.Venue{}, ErrDbRecordNotFound
+		default:
+			return tixer.Venue{}, fmt.Errorf("failed to select venue from database: %w", err)
+		}
+	}
+
+	return venue, nil
+}
+
+type VenueFilter struct {
+	Name   string
+	Limit  int
+	Offset int
+}
+
+// SelectMultiple reads venues based on filters from the database.
+func (vr *VenueRepository) SelectMultiple(ctx context.Context, filter VenueFilter) ([]tixer.Venue, Pagination, error) {
+	query := `SELECT count(*) OVER(), id, public_id, user_id, name, address, capacity, version, created_at, updated_at` +
+		` FROM ` + venuesTable +
+		` WHERE (to_tsvector('simple', name) @@ plainto_tsquery('simple', $1) OR $1 = '')` +
+		` ORDER BY name, id LIMIT $2 OFFSET $3`
+
+	args := []any{filter.Name, filter.Limit, filter.Offset}
+
+	queryCtx, cancel := context.WithTimeout(ctx, vr.QueryTimeout)
+	defer cancel()
+
+	rows, err := vr.DB.Query(queryCtx, query, args...)
+	if err != nil {
+		return nil, Pagination{}, fmt.Errorf("failed to select venues from database: %w", err)
+	}
+
+	defer rows.Close()
+
+	totalRecords := 0
+	venues := []tixer.Venue{}
+
+	for rows.Next() {
+		var venue tixer.Venue
+
+		err := rows.Scan(
+			&totalRecords,
+			&venue.ID,
+			&venue.PublicID,
+			&venue.OwnerID,
+			&venue.Name,
+			&venue.Address,
+			&venue.Capacity,
+			&venue.Version,
+			&venue.CreatedAt,
+			&venue.UpdatedAt,
+		)
+		if err != nil {
+			return nil, Pagination{}, fmt.Errorf("failed to scan row result: %w", err)
+		}
+
+		venues = append(venues, venue)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, Pagination{}, fmt.Errorf("failed to iterate over rows result: %w", err)
+	}
+
+	pagination := calculatePagination(totalRecords, filter.Offset/filter.Limit+1, filter.Limit)
+
+	return venues, pagination, nil
+}
+
+// Update updates a venue in the database.
+func (vr *VenueRepository) Update(ctx context.Context, venue *tixer.Venue) error {
+	query := `UPDATE ` + venuesTable +
+		` SET name = $1, address = $2, capacity = $3, version = version + 1, updated_at = NOW()` +
+		` WHERE public_id = $4 AND version = $5 RETURNING version, updated_at`
+
+	args := []any{venue.Name, venue.Address, venue.Capacity, venue.PublicID, venue.Version}
+
+	queryCtx, cancel := context.WithTimeout(ctx, vr.QueryTimeout)
+	defer cancel()
+
+	if err := vr.DB.QueryRow(queryCtx, query, args...).Scan(&venue.Version, &venue.UpdatedAt); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return ErrDbEditConflict
+		default:
+			return fmt.Errorf("failed to update venue in database: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Delete deletes a venue from the database.
+// It returns ErrDbRecordInUse if events still take place at the venue.
+func (vr *VenueRepository) Delete(ctx context.Context, id tixer.PublicID) error {
+	query := `DELETE FROM ` + venuesTable + ` WHERE public_id = $1`
+
+	queryCtx, cancel := context.WithTimeout(ctx, vr.QueryTimeout)
+	defer cancel()
+
+	res, err := vr.DB.Exec(queryCtx, query, id)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		switch {
+		case errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation:
+			return ErrDbRecordInUse
+		default:
+			return fmt.Errorf("failed to delete venue from database: %w", err)
+		}
+	}
+
+	if res.RowsAffected() == 0 {
+		return ErrDbRecordNotFound
+	}
+
+	return nil
+}
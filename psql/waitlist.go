@@ -0,0 +1,296 @@
+package psql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	tixer "github.com/mroobert/monorepo-tixer"
+)
+
+var (
+	ErrDbTicketOnSale   = errors.New("db ticket on sale")
+	ErrDbAlreadyWaiting = errors.New("db already waiting")
+)
+
+const waitlistEntriesTable = "waitlist_entries"
+
+// WaitlistRepository persists the waitlists of sold out tickets in the database.
+type WaitlistRepository struct {
+	DB           *pgxpool.Pool
+	QueryTimeout time.Duration
+	ClaimWindow  time.Duration // how long a unit released to the waitlist is held for the next user
+}
+
+func NewWaitlistRepository(db *pgxpool.Pool, queryTimeout, claimWindow time.Duration) *WaitlistRepository {
+	return &WaitlistRepository{
+		DB:           db,
+		QueryTimeout: queryTimeout,
+		ClaimWindow:  claimWindow,
+	}
+}
+
+// Insert adds the user referenced by entry.UserID at the end of the waitlist of the ticket
+// referenced by entry.TicketPublicID. The ticket row is locked, so no unit can be released
+// while the user joins. It returns ErrDbTicketNotPublished if the ticket is not published,
+// ErrDbTicketOnSale if the ticket still has units on sale and ErrDbAlreadyWaiting if the user
+// is already on the waitlist.
+func (wr *WaitlistRepository) Insert(ctx context.Context, entry tixer.WaitlistEntry) (tixer.WaitlistEntry, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, wr.QueryTimeout)
+	defer cancel()
+
+	tx, err := beginScopedTx(queryCtx, wr.DB)
+	if err != nil {
+		return tixer.WaitlistEntry{}, err
+	}
+	defer tx.Rollback(queryCtx)
+
+	lockQuery := `SELECT id, status, capacity - sold - held FROM ` + ticketsTable + ` WHERE public_id = $1 FOR UPDATE`
+
+	var ticketID int64
+	var status tixer.TicketStatus
+	var remaining int32
+	if err := tx.QueryRow(queryCtx, lockQuery, entry.TicketPublicID).Scan(&ticketID, &status, &remaining); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return tixer.WaitlistEntry{}, ErrDbRecordNotFound
+		default:
+			return tixer.WaitlistEntry{}, fmt.Errorf("failed to lock ticket in database: %w", err)
+		}
+	}
+	if status != tixer.TicketPublished {
+		return tixer.WaitlistEntry{}, ErrDbTicketNotPublished
+	}
+	if remaining > 0 {
+		return tixer.WaitlistEntry{}, ErrDbTicketOnSale
+	}
+
+	insertQuery := `WITH w AS (INSERT INTO ` + waitlistEntriesTable +
+		` (public_id, ticket_id, user_id, status) VALUES ($1, $2, $3, $4)
+        RETURNING id, public_id, ticket_id, user_id, status, claim_expires_at, created_at, updated_at)` +
+		` SELECT w.id, w.public_id, w.ticket_id, t.public_id, w.user_id, w.status, w.claim_expires_at, w.created_at, w.updated_at,` +
+		` (SELECT count(*) FROM ` + waitlistEntriesTable + ` p WHERE p.ticket_id = w.ticket_id AND p.status = $4 AND p.id < w.id)` +
+		` FROM w JOIN ` + ticketsTable + ` t ON t.id = w.ticket_id`
+
+	args := []any{entry.PublicID, ticketID, entry.UserID, tixer.WaitlistWaiting}
+
+	createdEntry, err := scanWaitlistEntry(tx.QueryRow(queryCtx, insertQuery, args...))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		switch {
+		case errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation:
+			return tixer.WaitlistEntry{}, ErrDbAlreadyWaiting
+		default:
+			return tixer.WaitlistEntry{}, fmt.Errorf("failed to insert waitlist entry in database: %w", err)
+		}
+	}
+
+	if err := tx.Commit(queryCtx); err != nil {
+		return tixer.WaitlistEntry{}, fmt.Errorf("failed to commit waitlist transaction: %w", err)
+	}
+
+	return createdEntry, nil
+}
+
+// SelectOne reads the latest waitlist entry of a user for a ticket from the database.
+func (wr *WaitlistRepository) SelectOne(ctx context.Context, ticketID tixer.PublicID, userID int64) (tixer.WaitlistEntry, error) {
+	query := `SELECT w.id, w.public_id, w.ticket_id, t.public_id, w.user_id, w.status, w.claim_expires_at, w.created_at, w.updated_at,` +
+		` (SELECT count(*) FROM ` + waitlistEntriesTable + ` p WHERE p.ticket_id = w.ticket_id AND p.status = $3 AND p.id < w.id)` +
+		` FROM ` + waitlistEntriesTable + ` w JOIN ` + ticketsTable + ` t ON t.id = w.ticket_id` +
+		` WHERE t.public_id = $1 AND w.user_id = $2 ORDER BY w.id DESC LIMIT 1`
+
+	queryCtx, cancel := context.WithTimeout(ctx, wr.QueryTimeout)
+	defer cancel()
+
+	tx, err := beginScopedTx(queryCtx, wr.DB)
+	if err != nil {
+		return tixer.WaitlistEntry{}, err
+	}
+	defer tx.Rollback(queryCtx)
+
+	entry, err := scanWaitlistEntry(tx.QueryRow(queryCtx, query, ticketID, userID, tixer.WaitlistWaiting))
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return tixer.WaitlistEntry{}, ErrDbRecordNotFound
+		default:
+			return tixer.WaitlistEntry{}, fmt.Errorf("failed to select waitlist entry from database: %w", err)
+		}
+	}
+
+	if err := tx.Commit(queryCtx); err != nil {
+		return tixer.WaitlistEntry{}, fmt.Errorf("failed to commit waitlist transaction: %w", err)
+	}
+
+	return entry, nil
+}
+
+// LapseOverdue marks up to limit offered entries whose claim has expired as lapsed,
+// offers their held units to the next users in line and returns the lapsed entries.
+// Rows locked by another transaction are skipped, so several instances can run it concurrently.
+func (wr *WaitlistRepository) LapseOverdue(ctx context.Context, limit int) ([]tixer.WaitlistEntry, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, wr.QueryTimeout)
+	defer cancel()
+
+	tx, err := beginScopedTx(queryCtx, wr.DB)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(queryCtx)
+
+	overdueQuery := `SELECT id, ticket_id FROM ` + waitlistEntriesTable +
+		` WHERE status = $1 AND claim_expires_at <= NOW() ORDER BY claim_expires_at LIMIT $2 FOR UPDATE SKIP LOCKED`
+
+	rows, err := tx.Query(queryCtx, overdueQuery, tixer.WaitlistOffered, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select overdue waitlist claims from database: %w", err)
+	}
+
+	var entryIDs, ticketIDs []int64
+	for rows.Next() {
+		var entryID, ticketID int64
+		if err := rows.Scan(&entryID, &ticketID); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan row result: %w", err)
+		}
+
+		entryIDs = append(entryIDs, entryID)
+		ticketIDs = append(ticketIDs, ticketID)
+	}
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over rows result: %w", err)
+	}
+
+	if len(entryIDs) == 0 {
+		return []tixer.WaitlistEntry{}, nil
+	}
+
+	// The tickets are updated in no particular order below, so they are locked in ID order first.
+	if err := lockTickets(queryCtx, tx, ticketIDs); err != nil {
+		return nil, err
+	}
+
+	query := `WITH lapsed AS (` +
+		` UPDATE ` + waitlistEntriesTable + ` SET status = $1, updated_at = NOW()` +
+		` WHERE id = ANY($2)` +
+		` RETURNING id, public_id, ticket_id, user_id, status, claim_expires_at, created_at, updated_at),` +
+		` released AS (UPDATE ` + ticketsTable + ` t SET held = t.held - r.units` +
+		` FROM (SELECT ticket_id, count(*) AS units FROM lapsed GROUP BY ticket_id) r WHERE t.id = r.ticket_id)` +
+		` SELECT l.id, l.public_id, l.ticket_id, t.public_id, l.user_id, l.status, l.claim_expires_at, l.created_at, l.updated_at, 0` +
+		` FROM lapsed l JOIN ` + ticketsTable + ` t ON t.id = l.ticket_id`
+
+	args := []any{tixer.WaitlistLapsed, entryIDs}
+
+	rows, err = tx.Query(queryCtx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lapse waitlist claims in database: %w", err)
+	}
+
+	entries := []tixer.WaitlistEntry{}
+	for rows.Next() {
+		entry, err := scanWaitlistEntry(rows)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan row result: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over rows result: %w", err)
+	}
+
+	units := make(map[int64]int)
+	for _, entry := range entries {
+		units[entry.TicketID]++
+	}
+	for ticketID, n := range units {
+		if err := offerReleasedUnits(queryCtx, tx, ticketID, n, wr.ClaimWindow); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(queryCtx); err != nil {
+		return nil, fmt.Errorf("failed to commit waitlist transaction: %w", err)
+	}
+
+	return entries, nil
+}
+
+// offerReleasedUnits is the hook of every code path that gives units of a ticket back.
+// It holds up to units of the released units for the first users waiting for the ticket
+// and offers them a claim that lasts claimWindow. The units that nobody waits for stay on sale.
+// It must run in the transaction that released the units, after their release.
+func offerReleasedUnits(ctx context.Context, tx pgx.Tx, ticketID int64, units int, claimWindow time.Duration) error {
+	query := `WITH offered AS (` +
+		` UPDATE ` + waitlistEntriesTable + ` SET status = $1, claim_expires_at = NOW() + $2::interval, updated_at = NOW()` +
+		` WHERE id IN (SELECT id FROM ` + waitlistEntriesTable +
+		` WHERE ticket_id = $3 AND status = $4 ORDER BY id LIMIT $5 FOR UPDATE)` +
+		` RETURNING id)` +
+		` UPDATE ` + ticketsTable + ` SET held = held + (SELECT count(*) FROM offered) WHERE id = $3`
+
+	args := []any{tixer.WaitlistOffered, claimWindow, ticketID, tixer.WaitlistWaiting, units}
+
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to offer released units to the waitlist in database: %w", err)
+	}
+
+	return nil
+}
+
+// claimHeldUnit turns the unit held for the user's waitlist claim on the ticket into a sold unit.
+// It returns false if the user holds no unexpired claim on the ticket or the ticket is no longer published.
+// It must run in the transaction that inserts the order for the unit.
+func claimHeldUnit(ctx context.Context, tx pgx.Tx, ticketID tixer.PublicID, userID int64) (int64, bool, error) {
+	query := `WITH claimed AS (` +
+		` UPDATE ` + waitlistEntriesTable + ` w SET status = $1, updated_at = NOW()` +
+		` FROM ` + ticketsTable + ` t` +
+		` WHERE w.ticket_id = t.id AND t.public_id = $2 AND t.status = $5` +
+		` AND w.user_id = $3 AND w.status = $4 AND w.claim_expires_at > NOW()` +
+		` RETURNING w.ticket_id)` +
+		` UPDATE ` + ticketsTable + ` t SET sold = t.sold + 1, held = t.held - 1 FROM claimed c WHERE t.id = c.ticket_id RETURNING t.id`
+
+	args := []any{tixer.WaitlistClaimed, ticketID, userID, tixer.WaitlistOffered, tixer.TicketPublished}
+
+	var id int64
+	if err := tx.QueryRow(ctx, query, args...).Scan(&id); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return 0, false, nil
+		default:
+			return 0, false, fmt.Errorf("failed to claim held unit in database: %w", err)
+		}
+	}
+
+	return id, true, nil
+}
+
+// scanWaitlistEntry scans a waitlist entry followed by its position in the waitlist.
+func scanWaitlistEntry(row pgx.Row) (tixer.WaitlistEntry, error) {
+	var entry tixer.WaitlistEntry
+
+	err := row.Scan(
+		&entry.ID,
+		&entry.PublicID,
+		&entry.TicketID,
+		&entry.TicketPublicID,
+		&entry.UserID,
+		&entry.Status,
+		&entry.ClaimExpiresAt,
+		&entry.CreatedAt,
+		&entry.UpdatedAt,
+		&entry.Position,
+	)
+	if entry.Status != tixer.WaitlistWaiting {
+		entry.Position = 0
+	}
+
+	return entry, err
+}
@@ -0,0 +1,28 @@
+package tixer
+
+import (
+	"time"
+)
+
+// RefundStatus represents the state of a refund.
+type RefundStatus string
+
+// Refund statuses.
+const (
+	RefundPending   RefundStatus = "pending" // recorded, the money is being given back through the payment gateway
+	RefundSucceeded RefundStatus = "succeeded"
+)
+
+// Refund represents money given back to a buyer for a cancelled paid order.
+type Refund struct {
+	ID               int64
+	PublicID         PublicID
+	OrderID          int64
+	OrderPublicID    PublicID
+	PaymentID        int64
+	Amount           Money
+	Reason           string
+	Status           RefundStatus
+	GatewayReference string // the identifier of the refund assigned by the payment gateway
+	CreatedAt        time.Time
+}
@@ -0,0 +1,84 @@
+package tixer
+
+import (
+	"time"
+)
+
+// MaxResaleMarkupPercent is how much more than its original price, in percent, a ticket can be resold for.
+const MaxResaleMarkupPercent = 10
+
+// ResaleStatus represents the state of a resale listing.
+type ResaleStatus string
+
+// Resale listing statuses.
+const (
+	ResaleActive    ResaleStatus = "active"
+	ResaleSold      ResaleStatus = "sold"
+	ResaleWithdrawn ResaleStatus = "withdrawn" // the seller took the ticket off the market
+)
+
+// ResaleListing represents a purchased ticket, held through a paid order, that its holder offers to other users.
+// A purchase moves the order to the buyer and records a payout of the price to the seller.
+type ResaleListing struct {
+	ID               int64
+	PublicID         PublicID
+	OrderID          int64
+	OrderPublicID    PublicID
+	TicketPublicID   PublicID
+	SellerID         int64
+	BuyerID          *int64 // set once the listing is sold
+	Price            Money
+	OriginalPrice    Money // the price of the ticket when the order was placed, before any discount
+	Status           ResaleStatus
+	GatewayReference *string // the identifier of the buyer's charge assigned by the payment gateway
+	CreatedAt        time.Time
+	SoldAt           *time.Time
+}
+
+// MaxResalePrice returns the highest price a ticket bought at the original price can be resold for.
+func MaxResalePrice(original Money) Money {
+	return Money{Amount: original.Amount + original.Percent(MaxResaleMarkupPercent).Amount, Currency: original.Currency}
+}
+
+// Validate checks listing's fields to ensure that the basic business rules are met.
+// It returns a boolean indicating if the listing is valid and a map of errors if it's not.
+func (l ResaleListing) Validate() (bool, map[string]string) {
+	errors := make(map[string]string)
+
+	maxPrice := MaxResalePrice(l.OriginalPrice)
+	switch {
+	case l.Price.Currency != l.OriginalPrice.Currency:
+		errors["price"] = "must be in " + string(l.OriginalPrice.Currency) + ", the currency the ticket was bought in"
+	case l.Price.Amount < minTicketPrice:
+		errors["price"] = "must be greater than 0"
+	case l.Price.Amount > maxPrice.Amount:
+		errors["price"] = "must not be more than " + maxPrice.String()
+	}
+
+	if len(errors) > 0 {
+		return false, errors
+	}
+
+	return true, nil
+}
+
+// PayoutStatus represents the state of a payout.
+type PayoutStatus string
+
+// Payout statuses.
+const (
+	PayoutPending PayoutStatus = "pending" // recorded, waiting to be paid out to the seller
+	PayoutPaid    PayoutStatus = "paid"
+)
+
+// Payout represents money owed to a seller for a resold ticket.
+type Payout struct {
+	ID              int64
+	PublicID        PublicID
+	ListingID       int64
+	ListingPublicID PublicID
+	UserID          int64
+	Amount          Money
+	Status          PayoutStatus
+	CreatedAt       time.Time
+}
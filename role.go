@@ -0,0 +1,103 @@
+package tixer
+
+import (
+	"slices"
+)
+
+// Permission represents the right to perform an action on a resource.
+type Permission string
+
+// System permissions. The migrations seed them in the database,
+// where they can be assigned to roles.
+const (
+	PermTicketsRead   Permission = "tickets:read"
+	PermTicketsWrite  Permission = "tickets:write"  // create tickets and update your own tickets
+	PermTicketsDelete Permission = "tickets:delete" // delete your own tickets
+	PermTicketsManage Permission = "tickets:manage" // update and delete tickets of other users
+	PermOrdersWrite   Permission = "orders:write"   // place, pay, read and cancel your own orders
+	PermOrdersManage  Permission = "orders:manage"  // read and cancel orders of other users
+	PermRolesManage   Permission = "roles:manage"   // manage roles and grant them to users
+	PermAPIKeysWrite  Permission = "apikeys:write"  // issue, read and revoke your own API keys
+	PermEventsWrite   Permission = "events:write"   // create events and venues and change your own
+	PermEventsManage  Permission = "events:manage"  // change and delete events and venues of other users
+
+	PermExchangeRatesManage Permission = "exchangerates:manage" // load the exchange rates used to convert prices
+	PermPromoCodesManage    Permission = "promocodes:manage"    // create, read and delete promo codes
+	PermCheckInsWrite       Permission = "checkins:write"       // scan the credentials of ticket holders at the gate
+	PermTicketRulesManage   Permission = "ticketrules:manage"   // override the ticket rules of organizers
+	PermTenantsManage       Permission = "tenants:manage"       // add organizers and the users that work for them
+)
+
+// Role names seeded by the migrations.
+const (
+	RoleAdmin  = "admin"
+	RoleSeller = "seller"
+	RoleBuyer  = "buyer"
+	RoleGuest  = "guest" // the role of anonymous callers
+)
+
+// Role represents a named set of permissions that can be granted to users.
+type Role struct {
+	ID          int64
+	Name        string
+	Description string
+	Permissions Permissions
+}
+
+// BuiltIn reports whether the role is seeded by the migrations and relied upon by the system.
+func (r Role) BuiltIn() bool {
+	return slices.Contains([]string{RoleAdmin, RoleSeller, RoleBuyer, RoleGuest}, r.Name)
+}
+
+// Validate checks role's fields to ensure that the basic business rules are met.
+// It returns a boolean indicating if the role is valid and a map of errors if it's not.
+func (r Role) Validate() (bool, map[string]string) {
+	errors := make(map[string]string)
+
+	if r.Name == "" {
+		errors["name"] = "must be provided"
+	}
+
+	if len(r.Name) > 50 {
+		errors["name"] = "must not be more than 50 characters long"
+	}
+
+	for _, perm := range r.Permissions {
+		if !slices.Contains(AllPermissions, perm) {
+			errors["permissions"] = "contains unknown permission " + string(perm)
+		}
+	}
+
+	if len(errors) > 0 {
+		return false, errors
+	}
+
+	return true, nil
+}
+
+// AllPermissions lists every system permission.
+var AllPermissions = Permissions{
+	PermTicketsRead,
+	PermTicketsWrite,
+	PermTicketsDelete,
+	PermTicketsManage,
+	PermOrdersWrite,
+	PermOrdersManage,
+	PermRolesManage,
+	PermAPIKeysWrite,
+	PermEventsWrite,
+	PermEventsManage,
+	PermExchangeRatesManage,
+	PermPromoCodesManage,
+	PermCheckInsWrite,
+	PermTicketRulesManage,
+	PermTenantsManage,
+}
+
+// Permissions represents the set of permissions held by a caller.
+type Permissions []Permission
+
+// Has reports whether the set contains the permission.
+func (p Permissions) Has(perm Permission) bool {
+	return slices.Contains(p, perm)
+}
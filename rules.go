@@ -0,0 +1,224 @@
+package tixer
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Ticket rule names, reported with the violations so callers know which rule failed.
+const (
+	RuleMaxTitleLength    = "maxTitleLength"
+	RuleForbiddenWords    = "forbiddenWords"
+	RuleMinPrice          = "minPrice"
+	RuleMaxPrice          = "maxPrice"
+	RuleAllowedCurrencies = "allowedCurrencies"
+)
+
+// maxTicketTitleLength is the longest title the rules can allow.
+const maxTicketTitleLength = 500
+
+// TicketRules represents the business rules the title and prices of tickets must follow.
+// The deployment rules are loaded from the configuration and can be overridden per organizer.
+type TicketRules struct {
+	MaxTitleLength    int                // the longest title, in bytes
+	MinPrice          int64              // the lowest price, in the minor unit of any currency
+	MaxPrices         map[Currency]int64 // the highest price per currency, in minor units; the currencies missing use the highest price of the currency
+	AllowedCurrencies []Currency         // the currencies tickets can be sold in; empty allows all the supported currencies
+	ForbiddenWords    []string           // the words a title must not contain, in any letter case
+}
+
+// DefaultTicketRules returns the rules used when the configuration does not set them.
+func DefaultTicketRules() TicketRules {
+	return TicketRules{
+		MaxTitleLength: 150,
+		MinPrice:       minTicketPrice,
+	}
+}
+
+// TicketRulesOverride represents the rules of an organizer that replace the deployment rules.
+// The nil fields keep the deployment rule.
+type TicketRulesOverride struct {
+	TenantID          int64 // the ID of the tenant the override belongs to
+	MaxTitleLength    *int
+	MinPrice          *int64
+	MaxPrices         map[Currency]int64 // merged into the deployment prices, per currency
+	AllowedCurrencies *[]Currency
+	ForbiddenWords    *[]string
+	UpdatedAt         time.Time
+}
+
+// RuleViolation represents a value that breaks one of the ticket rules.
+type RuleViolation struct {
+	Rule    string
+	Message string
+}
+
+// String formats the violation as the message followed by the name of the rule.
+func (v RuleViolation) String() string {
+	return fmt.Sprintf("%s (rule %s)", v.Message, v.Rule)
+}
+
+// Apply returns the rules with the override of an organizer applied.
+func (r TicketRules) Apply(o TicketRulesOverride) TicketRules {
+	if o.MaxTitleLength != nil {
+		r.MaxTitleLength = *o.MaxTitleLength
+	}
+	if o.MinPrice != nil {
+		r.MinPrice = *o.MinPrice
+	}
+	if len(o.MaxPrices) > 0 {
+		prices := maps.Clone(r.MaxPrices)
+		if prices == nil {
+			prices = make(map[Currency]int64, len(o.MaxPrices))
+		}
+		maps.Copy(prices, o.MaxPrices)
+		r.MaxPrices = prices
+	}
+	if o.AllowedCurrencies != nil {
+		r.AllowedCurrencies = *o.AllowedCurrencies
+	}
+	if o.ForbiddenWords != nil {
+		r.ForbiddenWords = *o.ForbiddenWords
+	}
+
+	return r
+}
+
+// Validate checks the rules to ensure they can be followed by a ticket and stay within the limits of the system.
+// It returns a boolean indicating if the rules are valid and a map of errors if they are not.
+func (r TicketRules) Validate() (bool, map[string]string) {
+	errors := make(map[string]string)
+
+	if r.MaxTitleLength < 1 || r.MaxTitleLength > maxTicketTitleLength {
+		errors[RuleMaxTitleLength] = fmt.Sprintf("must be between 1 and %d", maxTicketTitleLength)
+	}
+
+	if r.MinPrice < minTicketPrice {
+		errors[RuleMinPrice] = "must be greater than 0"
+	}
+
+	for currency, amount := range r.MaxPrices {
+		maxPrice, ok := MaxTicketPrice(currency)
+		switch {
+		case !ok:
+			errors[RuleMaxPrice] = fmt.Sprintf("must only have supported currencies, %q is not", currency)
+		case amount < r.MinPrice:
+			errors[RuleMaxPrice] = fmt.Sprintf("must not be less than %s in %s", RuleMinPrice, currency)
+		case amount > maxPrice.Amount:
+			errors[RuleMaxPrice] = "must not be more than " + maxPrice.String()
+		}
+	}
+
+	for _, currency := range r.AllowedCurrencies {
+		if ValidateCurrency(string(currency)) != nil {
+			errors[RuleAllowedCurrencies] = fmt.Sprintf("must only have supported currencies, %q is not", currency)
+		}
+	}
+
+	for _, word := range r.ForbiddenWords {
+		if len(titleWords(word)) != 1 {
+			errors[RuleForbiddenWords] = fmt.Sprintf("must only have single words, %q is not", word)
+		}
+	}
+
+	if len(errors) > 0 {
+		return false, errors
+	}
+
+	return true, nil
+}
+
+// CheckTitle checks the title of a ticket against the rules.
+// It returns nil if the title follows them and the violated rule otherwise.
+func (r TicketRules) CheckTitle(title string) *RuleViolation {
+	if len(title) > r.MaxTitleLength {
+		return &RuleViolation{Rule: RuleMaxTitleLength, Message: fmt.Sprintf("must not be more than %d characters long", r.MaxTitleLength)}
+	}
+
+	for _, word := range titleWords(title) {
+		if slices.ContainsFunc(r.ForbiddenWords, func(forbidden string) bool { return strings.EqualFold(forbidden, word) }) {
+			return &RuleViolation{Rule: RuleForbiddenWords, Message: fmt.Sprintf("must not contain the word %q", word)}
+		}
+	}
+
+	return nil
+}
+
+// CheckCurrency checks that tickets can be sold in the currency.
+// It returns nil if they can and the violated rule otherwise.
+func (r TicketRules) CheckCurrency(currency Currency) *RuleViolation {
+	if ValidateCurrency(string(currency)) != nil {
+		return &RuleViolation{Rule: RuleAllowedCurrencies, Message: "must be in a supported currency"}
+	}
+
+	if len(r.AllowedCurrencies) > 0 && !slices.Contains(r.AllowedCurrencies, currency) {
+		return &RuleViolation{Rule: RuleAllowedCurrencies, Message: fmt.Sprintf("must be in one of the allowed currencies %v", r.AllowedCurrencies)}
+	}
+
+	return nil
+}
+
+// CheckPrice checks a ticket price against the rules, including its currency.
+// It returns nil if the price follows them and the violated rule otherwise.
+func (r TicketRules) CheckPrice(price Money) *RuleViolation {
+	if violation := r.CheckCurrency(price.Currency); violation != nil {
+		return violation
+	}
+
+	if price.Amount < r.MinPrice {
+		return &RuleViolation{Rule: RuleMinPrice, Message: "must not be less than " + Money{Amount: r.MinPrice, Currency: price.Currency}.String()}
+	}
+
+	if maxPrice := r.MaxPrice(price.Currency); price.Amount > maxPrice.Amount {
+		return &RuleViolation{Rule: RuleMaxPrice, Message: "must not be more than " + maxPrice.String()}
+	}
+
+	return nil
+}
+
+// MaxPrice returns the highest price a ticket can have in the supported currency.
+// It is never more than the highest price the system accepts in the currency.
+func (r TicketRules) MaxPrice(currency Currency) Money {
+	maxPrice, _ := MaxTicketPrice(currency)
+	if amount, ok := r.MaxPrices[currency]; ok && amount < maxPrice.Amount {
+		maxPrice.Amount = amount
+	}
+
+	return maxPrice
+}
+
+// ClampPrice keeps the price between the lowest and highest price the rules allow in its currency.
+func (r TicketRules) ClampPrice(price Money) Money {
+	if maxPrice := r.MaxPrice(price.Currency); price.Amount > maxPrice.Amount {
+		price.Amount = maxPrice.Amount
+	}
+	if price.Amount < r.MinPrice {
+		price.Amount = r.MinPrice
+	}
+
+	return price
+}
+
+// Currencies returns the currencies tickets can be sold in, sorted by code.
+func (r TicketRules) Currencies() []Currency {
+	allowed := slices.Clone(r.AllowedCurrencies)
+	if len(allowed) == 0 {
+		for currency := range currencies {
+			allowed = append(allowed, currency)
+		}
+	}
+	slices.Sort(allowed)
+
+	return allowed
+}
+
+// titleWords splits a title into its words, dropping the punctuation and spaces between them.
+func titleWords(title string) []string {
+	return strings.FieldsFunc(title, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
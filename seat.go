@@ -0,0 +1,133 @@
+package tixer
+
+import (
+	"fmt"
+)
+
+// SeatStatus represents the availability of a seat for an event.
+type SeatStatus string
+
+const (
+	SeatUnlisted  SeatStatus = "unlisted"  // no ticket is on sale for the seat
+	SeatAvailable SeatStatus = "available" // a ticket is on sale for the seat and can be ordered
+	SeatReserved  SeatStatus = "reserved"  // the ticket of the seat is held by a pending order
+	SeatSold      SeatStatus = "sold"      // the ticket of the seat was paid
+)
+
+// SeatMap represents the seating layout of a venue.
+type SeatMap struct {
+	Sections []Section
+}
+
+// Section represents a named area of a venue, such as a stand or a balcony.
+type Section struct {
+	ID   int64
+	Name string
+	Rows []SeatRow
+}
+
+// SeatRow represents a row of seats inside a section.
+type SeatRow struct {
+	ID    int64
+	Label string
+	Seats []Seat
+}
+
+// Seat represents a numbered seat inside a row.
+type Seat struct {
+	ID       int64
+	PublicID PublicID
+	VenueID  int64
+	Number   int32
+	Category string // the category of the seat, e.g. standard or VIP
+
+	// The fields below are only set when the seat map is read for an event.
+	Status SeatStatus
+	Ticket *Ticket // the ticket on sale for the seat, with only its public ID and price set
+}
+
+// Len returns the number of seats of the seat map.
+func (m SeatMap) Len() int {
+	n := 0
+	for _, section := range m.Sections {
+		for _, row := range section.Rows {
+			n += len(row.Seats)
+		}
+	}
+
+	return n
+}
+
+// Validate checks seat map's fields to ensure that the basic business rules are met.
+// It returns a boolean indicating if the seat map is valid and a map of errors if it's not.
+func (m SeatMap) Validate() (bool, map[string]string) {
+	errors := make(map[string]string)
+
+	if len(m.Sections) == 0 {
+		errors["sections"] = "must contain at least one section"
+	}
+
+	sections := make(map[string]bool)
+	for i, section := range m.Sections {
+		key := fmt.Sprintf("sections[%d]", i)
+
+		switch {
+		case section.Name == "":
+			errors[key+".name"] = "must be provided"
+		case len(section.Name) > 100:
+			errors[key+".name"] = "must not be more than 100 characters long"
+		case sections[section.Name]:
+			errors[key+".name"] = "must be unique in the venue"
+		}
+		sections[section.Name] = true
+
+		if len(section.Rows) == 0 {
+			errors[key+".rows"] = "must contain at least one row"
+		}
+
+		rows := make(map[string]bool)
+		for j, row := range section.Rows {
+			key := fmt.Sprintf("%s.rows[%d]", key, j)
+
+			switch {
+			case row.Label == "":
+				errors[key+".label"] = "must be provided"
+			case len(row.Label) > 20:
+				errors[key+".label"] = "must not be more than 20 characters long"
+			case rows[row.Label]:
+				errors[key+".label"] = "must be unique in the section"
+			}
+			rows[row.Label] = true
+
+			if len(row.Seats) == 0 {
+				errors[key+".seats"] = "must contain at least one seat"
+			}
+
+			numbers := make(map[int32]bool)
+			for k, seat := range row.Seats {
+				key := fmt.Sprintf("%s.seats[%d]", key, k)
+
+				switch {
+				case seat.Number <= 0:
+					errors[key+".number"] = "must be greater than 0"
+				case numbers[seat.Number]:
+					errors[key+".number"] = "must be unique in the row"
+				}
+				numbers[seat.Number] = true
+
+				switch {
+				case seat.Category == "":
+					errors[key+".category"] = "must be provided"
+				case len(seat.Category) > 50:
+					errors[key+".category"] = "must not be more than 50 characters long"
+				}
+			}
+		}
+	}
+
+	if len(errors) > 0 {
+		return false, errors
+	}
+
+	return true, nil
+}
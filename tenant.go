@@ -0,0 +1,67 @@
+package tixer
+
+import (
+	"context"
+	"time"
+)
+
+// Tenant represents an organizer hosted on the deployment. Its tickets are only
+// visible to the requests made for it, and it is joined by the users that work for it.
+type Tenant struct {
+	ID        int64
+	PublicID  PublicID
+	Name      string
+	CreatedAt time.Time
+}
+
+// Validate checks tenant's fields to ensure that the basic business rules are met.
+// It returns a boolean indicating if the tenant is valid and a map of errors if it's not.
+func (t Tenant) Validate() (bool, map[string]string) {
+	errors := make(map[string]string)
+
+	if t.Name == "" {
+		errors["name"] = "must be provided"
+	}
+
+	if len(t.Name) > 150 {
+		errors["name"] = "must not be more than 150 characters long"
+	}
+
+	if len(errors) > 0 {
+		return false, errors
+	}
+
+	return true, nil
+}
+
+// tenantContextKey is the context key of the tenant the work is done for.
+type tenantContextKey struct{}
+
+// tenantBypass is carried by a context, instead of a tenant ID, for the work done across tenants.
+type tenantBypass struct{}
+
+// NewTenantContext returns a copy of ctx that carries the ID of the tenant the work is done for.
+func NewTenantContext(ctx context.Context, tenantID int64) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenantID)
+}
+
+// NewTenantBypassContext returns a copy of ctx for work that is done across tenants, such as
+// the expiry of the overdue orders or the reading of the orders a buyer placed with several organizers.
+// It replaces the tenant carried by ctx, if any. The caller must keep the work to the records it may reach,
+// as the data of the tenants is no longer kept apart.
+func NewTenantBypassContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenantBypass{})
+}
+
+// TenantFromContext returns the ID of the tenant carried by ctx.
+// It returns false if the work is not done for any tenant.
+func TenantFromContext(ctx context.Context) (int64, bool) {
+	tenantID, ok := ctx.Value(tenantContextKey{}).(int64)
+	return tenantID, ok
+}
+
+// TenantBypassed reports whether ctx was made by NewTenantBypassContext for work done across tenants.
+func TenantBypassed(ctx context.Context) bool {
+	_, ok := ctx.Value(tenantContextKey{}).(tenantBypass)
+	return ok
+}
@@ -1,35 +1,110 @@
 package tixer
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
 	"time"
 )
 
-// Ticket represents a ticket that can be purchased.
+// MaxTicketCapacity is the highest number of units a ticket can be sold in.
+const MaxTicketCapacity = 100_000
+
+// minTicketPrice is the lowest ticket price, in the minor unit of its currency.
+const minTicketPrice = 1
+
+// TicketStatus represents the state of a ticket in its lifecycle.
+type TicketStatus string
+
+// Ticket statuses. Draft, published, cancelled and archived are set by the owner of the ticket;
+// a published ticket moves between published, reserved and sold on its own as units are taken and given back.
+const (
+	TicketDraft     TicketStatus = "draft"     // being prepared, not on sale yet
+	TicketPublished TicketStatus = "published" // on sale, with units remaining
+	TicketReserved  TicketStatus = "reserved"  // no unit remaining, but some are only held by unpaid orders or waitlist claims
+	TicketSold      TicketStatus = "sold"      // every unit is taken by a paid order
+	TicketCancelled TicketStatus = "cancelled" // taken off sale for good
+	TicketArchived  TicketStatus = "archived"  // kept for the records only
+)
+
+// ticketTransitions lists the states the owner of a ticket can legally move each state to.
+// The moves between published, reserved and sold are not listed, they are settled by SettleStatus.
+var ticketTransitions = map[TicketStatus][]TicketStatus{
+	TicketDraft:     {TicketPublished, TicketCancelled},
+	TicketPublished: {TicketCancelled, TicketArchived},
+	TicketReserved:  {TicketCancelled},
+	TicketSold:      {TicketCancelled, TicketArchived},
+	TicketCancelled: {TicketArchived},
+	TicketArchived:  {},
+}
+
+// Published reports whether the status is one of the states of a published ticket: published, reserved or sold.
+// Units of a published ticket can be ordered as long as some remain.
+func (s TicketStatus) Published() bool {
+	return s == TicketPublished || s == TicketReserved || s == TicketSold
+}
+
+// Ticket represents a ticket type that can be purchased up to its capacity,
+// e.g. 500 standing tickets for a concert. A seated ticket has a capacity of 1.
 type Ticket struct {
-	ID        int64
-	PublicID  PublicID
-	Title     string
-	Price     int64
-	Version   int32
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID               int64
+	PublicID         PublicID
+	OwnerID          *int64 // the ID of the user that put the ticket on sale, nil for tickets created before accounts existed
+	EventID          *int64 // the ID of the event the ticket admits to, nil for tickets created before events existed
+	EventPublicID    *PublicID
+	SeatID           *int64 // the ID of the seat the ticket is for, nil for tickets without an assigned seat
+	SeatPublicID     *PublicID
+	Title            string
+	Status           TicketStatus
+	Price            Money         // the base price, used when no price tier is current
+	PriceTiers       []PriceTier   // the prices the ticket is sold at over time, ordered by start
+	PricingRules     []PricingRule // the rules that change the price as the ticket sells out
+	RefundDeadline   *time.Time    // the last moment a paid ticket can be refunded, nil if there is no deadline
+	RefundPercentage int32         // the share of the paid amount that is given back on refund
+	Capacity         int32         // the number of units that can be sold
+	Sold             int32         // the number of units held by pending or paid orders
+	Held             int32         // the number of units held for waitlist claims
+	Pending          int32         // the number of sold units whose order awaits payment
+	Version          int32
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
 }
 
-// Validate checks ticket's fields to ensure that the basic business rules are met.
+// Validate checks ticket's fields to ensure that the basic business rules and the ticket rules of its organizer are met.
 // It returns a boolean indicating if the ticket is valid and a map of errors if it's not.
-func (t Ticket) Validate() (bool, map[string]string) {
+func (t Ticket) Validate(rules TicketRules) (bool, map[string]string) {
 	errors := make(map[string]string)
 
-	if len(t.Title) > 150 {
-		errors["title"] = "must not be more than 150 characters long"
+	if violation := rules.CheckTitle(t.Title); violation != nil {
+		errors["title"] = violation.String()
+	}
+
+	if violation := rules.CheckPrice(t.Price); violation != nil {
+		errors["price"] = violation.String()
+	}
+
+	if t.RefundPercentage < 0 || t.RefundPercentage > 100 {
+		errors["refundPercentage"] = "must be between 0 and 100"
+	}
+
+	switch {
+	case t.Capacity <= 0:
+		errors["capacity"] = "must be greater than 0"
+	case t.Capacity > MaxTicketCapacity:
+		errors["capacity"] = fmt.Sprintf("must not be more than %d", MaxTicketCapacity)
+	case t.Capacity < t.Sold+t.Held:
+		errors["capacity"] = fmt.Sprintf("must not be less than the %d units already sold or held", t.Sold+t.Held)
+	case t.SeatID != nil && t.Capacity != 1:
+		errors["capacity"] = "must be 1 for a seated ticket"
 	}
 
-	if t.Price <= 0 {
-		errors["price"] = "must be greater than 0"
+	if reason := validatePriceTiers(t.PriceTiers, t.Price.Currency, rules); reason != "" {
+		errors["priceTiers"] = reason
 	}
 
-	if t.Price > 50_000 {
-		errors["price"] = "must be lower than 500 euros"
+	if reason := validatePricingRules(t.PricingRules); reason != "" {
+		errors["pricingRules"] = reason
 	}
 
 	if len(errors) > 0 {
@@ -38,3 +113,110 @@ func (t Ticket) Validate() (bool, map[string]string) {
 
 	return true, nil
 }
+
+// TicketFilter represents the filters, order and page of the tickets read from a TicketStore.
+type TicketFilter struct {
+	Title         string    // when set, only the tickets whose title contains all of its words are read
+	EventID       *PublicID // when set, only the tickets of this event are read
+	Limit         int
+	Offset        int
+	SortColumn    string // id, title or price
+	SortDirection string // ASC or DESC
+}
+
+// Errors returned by the stores, whatever their implementation.
+var (
+	ErrNotFound       = errors.New("record not found")
+	ErrEditConflict   = errors.New("edit conflict")
+	ErrInUse          = errors.New("record in use")
+	ErrTenantRequired = errors.New("tenant required")
+	ErrSeatTaken      = errors.New("seat taken")
+)
+
+// TicketStore represents the place tickets are kept. Every method works on the tickets of the tenant
+// carried by its context only, see NewTenantContext, and returns ErrTenantRequired if the context carries none.
+type TicketStore interface {
+	// Insert stores a new ticket with its price tiers and pricing rules and returns it as stored.
+	// It returns ErrSeatTaken if another ticket was already issued for the seat at the same event.
+	Insert(ctx context.Context, ticket Ticket) (Ticket, error)
+
+	// SelectOne reads a ticket. It returns ErrNotFound if the tenant has no ticket with the ID.
+	SelectOne(ctx context.Context, id PublicID) (Ticket, error)
+
+	// SelectMultiple reads a page of the tickets matching the filter and the pagination of all of them.
+	SelectMultiple(ctx context.Context, filter TicketFilter) ([]Ticket, Pagination, error)
+
+	// Update stores the changes of a ticket, replacing its price tiers and pricing rules, and reads back
+	// its status, units and version. It returns ErrEditConflict if the ticket was changed since it was read.
+	Update(ctx context.Context, ticket *Ticket) error
+
+	// UpdateStatus stores the status the ticket was moved to by its owner and reads back its version.
+	// It returns ErrEditConflict if the ticket was changed since it was read.
+	UpdateStatus(ctx context.Context, ticket *Ticket) error
+
+	// Delete removes a ticket. It returns ErrNotFound if the tenant has no ticket with the ID
+	// and ErrInUse if the ticket was ordered.
+	Delete(ctx context.Context, id PublicID) error
+}
+
+// Transition moves the ticket to the given state on behalf of its owner.
+// It returns an EUNPROCESSABLE error if the current state cannot move to it.
+func (t *Ticket) Transition(to TicketStatus) error {
+	if !slices.Contains(ticketTransitions[t.Status], to) {
+		return Errorf(EUNPROCESSABLE, "the ticket is %s and cannot be moved to %s", t.Status, to)
+	}
+
+	t.Status = to
+	return nil
+}
+
+// SettleStatus moves a published ticket to published, reserved or sold according to
+// its sold, held and pending units. The other states are left as they are.
+func (t *Ticket) SettleStatus() {
+	if !t.Status.Published() {
+		return
+	}
+
+	switch {
+	case t.Remaining() > 0:
+		t.Status = TicketPublished
+	case t.Held > 0 || t.Pending > 0:
+		t.Status = TicketReserved
+	default:
+		t.Status = TicketSold
+	}
+}
+
+// CheckUpdate checks that the ticket can be updated, with or without a change of its title or price.
+// It returns an EUNPROCESSABLE error if an archived ticket is updated or the title or price of a sold ticket is changed.
+func (t Ticket) CheckUpdate(titleOrPriceChanged bool) error {
+	switch {
+	case t.Status == TicketArchived:
+		return Errorf(EUNPROCESSABLE, "the ticket is %s and cannot be updated", t.Status)
+	case t.Status == TicketSold && titleOrPriceChanged:
+		return Errorf(EUNPROCESSABLE, "the ticket is %s, its title and price cannot be changed", t.Status)
+	}
+
+	return nil
+}
+
+// Remaining returns the number of units that are still on sale.
+func (t Ticket) Remaining() int32 {
+	return t.Capacity - t.Sold - t.Held
+}
+
+// OwnedBy reports whether the ticket belongs to the user with the given ID.
+func (t Ticket) OwnedBy(userID int64) bool {
+	return t.OwnerID != nil && *t.OwnerID == userID
+}
+
+// RefundAmount returns the maximum amount that can be refunded at the given moment
+// for a ticket that was paid with the paid amount. The result is rounded down to
+// the minor unit. It returns an EUNPROCESSABLE error if the refund deadline has passed.
+func (t Ticket) RefundAmount(paid Money, at time.Time) (Money, error) {
+	if t.RefundDeadline != nil && at.After(*t.RefundDeadline) {
+		return Money{}, Errorf(EUNPROCESSABLE, "the refund deadline passed on %s", t.RefundDeadline.Format(time.RFC3339))
+	}
+
+	return paid.Percent(t.RefundPercentage), nil
+}
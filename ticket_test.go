@@ -0,0 +1,77 @@
+package tixer
+
+import (
+	"testing"
+)
+
+var ticketStatuses = []TicketStatus{
+	TicketDraft,
+	TicketPublished,
+	TicketReserved,
+	TicketSold,
+	TicketCancelled,
+	TicketArchived,
+}
+
+func TestTicketTransition(t *testing.T) {
+	allowed := map[TicketStatus]map[TicketStatus]bool{
+		TicketDraft:     {TicketPublished: true, TicketCancelled: true},
+		TicketPublished: {TicketCancelled: true, TicketArchived: true},
+		TicketReserved:  {TicketCancelled: true},
+		TicketSold:      {TicketCancelled: true, TicketArchived: true},
+		TicketCancelled: {TicketArchived: true},
+		TicketArchived:  {},
+	}
+
+	if len(ticketTransitions) != len(ticketStatuses) {
+		t.Errorf("ticketTransitions lists %d states, want %d", len(ticketTransitions), len(ticketStatuses))
+	}
+
+	for _, from := range ticketStatuses {
+		for _, to := range ticketStatuses {
+			t.Run(string(from)+"->"+string(to), func(t *testing.T) {
+				ticket := Ticket{Status: from}
+				err := ticket.Transition(to)
+
+				switch {
+				case allowed[from][to] && err != nil:
+					t.Fatalf("Transition() = %v, want nil", err)
+				case allowed[from][to] && ticket.Status != to:
+					t.Fatalf("Status = %s, want %s", ticket.Status, to)
+				case !allowed[from][to] && ErrorCode(err) != EUNPROCESSABLE:
+					t.Fatalf("Transition() = %v, want an %s error", err, EUNPROCESSABLE)
+				case !allowed[from][to] && ticket.Status != from:
+					t.Fatalf("Status = %s after a refused transition, want %s", ticket.Status, from)
+				}
+			})
+		}
+	}
+}
+
+func TestTicketCheckUpdate(t *testing.T) {
+	tests := []struct {
+		status              TicketStatus
+		titleOrPriceChanged bool
+		wantErr             bool
+	}{
+		{TicketDraft, true, false},
+		{TicketPublished, true, false},
+		{TicketReserved, true, false},
+		{TicketSold, false, false},
+		{TicketSold, true, true},
+		{TicketCancelled, true, false},
+		{TicketArchived, false, true},
+		{TicketArchived, true, true},
+	}
+
+	for _, tt := range tests {
+		err := Ticket{Status: tt.status}.CheckUpdate(tt.titleOrPriceChanged)
+
+		switch {
+		case tt.wantErr && ErrorCode(err) != EUNPROCESSABLE:
+			t.Errorf("CheckUpdate(%t) of a %s ticket = %v, want an %s error", tt.titleOrPriceChanged, tt.status, err, EUNPROCESSABLE)
+		case !tt.wantErr && err != nil:
+			t.Errorf("CheckUpdate(%t) of a %s ticket = %v, want nil", tt.titleOrPriceChanged, tt.status, err)
+		}
+	}
+}
@@ -0,0 +1,356 @@
+// This package provides the tests shared by the implementations of the tixer stores,
+// so every implementation is held to the same contract.
+package tixertest
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	nanoid "github.com/matoous/go-nanoid/v2"
+	tixer "github.com/mroobert/monorepo-tixer"
+)
+
+// TicketStoreFixture holds the ticket store under test and creates the records it needs besides the tickets.
+type TicketStoreFixture struct {
+	Store     tixer.TicketStore
+	NewTenant func(t *testing.T) int64                   // creates a tenant and returns its ID
+	NewSeat   func(t *testing.T) (eventID, seatID int64) // creates a seat at an event and returns their IDs
+}
+
+// TestTicketStore checks that the store of the fixture follows the contract of tixer.TicketStore:
+// the tickets are kept per tenant, a tenant is required and the errors are the ones of the tixer package.
+func TestTicketStore(t *testing.T, f TicketStoreFixture) {
+	t.Run("TenantRequired", func(t *testing.T) { testTicketStoreTenantRequired(t, f) })
+	t.Run("InsertAndSelect", func(t *testing.T) { testTicketStoreInsertAndSelect(t, f) })
+	t.Run("NotFound", func(t *testing.T) { testTicketStoreNotFound(t, f) })
+	t.Run("EditConflict", func(t *testing.T) { testTicketStoreEditConflict(t, f) })
+	t.Run("SeatTaken", func(t *testing.T) { testTicketStoreSeatTaken(t, f) })
+	t.Run("TenantIsolation", func(t *testing.T) { testTicketStoreTenantIsolation(t, f) })
+	t.Run("SortAndPaginate", func(t *testing.T) { testTicketStoreSortAndPaginate(t, f) })
+	t.Run("TitleSearch", func(t *testing.T) { testTicketStoreTitleSearch(t, f) })
+}
+
+// testTicketStoreTenantRequired checks that every method fails without a tenant in the context.
+func testTicketStoreTenantRequired(t *testing.T, f TicketStoreFixture) {
+	ctx := tixer.NewTenantContext(context.Background(), f.NewTenant(t))
+	ticket := insertTicket(t, f.Store, ctx, "Tenant required ticket")
+
+	ctx = context.Background()
+
+	if _, err := f.Store.Insert(ctx, newTicket(t, "Tenant required ticket")); !errors.Is(err, tixer.ErrTenantRequired) {
+		t.Errorf("Insert: got %v, want %v", err, tixer.ErrTenantRequired)
+	}
+	if _, err := f.Store.SelectOne(ctx, ticket.PublicID); !errors.Is(err, tixer.ErrTenantRequired) {
+		t.Errorf("SelectOne: got %v, want %v", err, tixer.ErrTenantRequired)
+	}
+	if _, _, err := f.Store.SelectMultiple(ctx, newFilter("")); !errors.Is(err, tixer.ErrTenantRequired) {
+		t.Errorf("SelectMultiple: got %v, want %v", err, tixer.ErrTenantRequired)
+	}
+	if err := f.Store.Update(ctx, &ticket); !errors.Is(err, tixer.ErrTenantRequired) {
+		t.Errorf("Update: got %v, want %v", err, tixer.ErrTenantRequired)
+	}
+	if err := f.Store.UpdateStatus(ctx, &ticket); !errors.Is(err, tixer.ErrTenantRequired) {
+		t.Errorf("UpdateStatus: got %v, want %v", err, tixer.ErrTenantRequired)
+	}
+	if err := f.Store.Delete(ctx, ticket.PublicID); !errors.Is(err, tixer.ErrTenantRequired) {
+		t.Errorf("Delete: got %v, want %v", err, tixer.ErrTenantRequired)
+	}
+}
+
+// testTicketStoreInsertAndSelect checks that the stored tickets are read back and their versions advance.
+func testTicketStoreInsertAndSelect(t *testing.T, f TicketStoreFixture) {
+	ctx := tixer.NewTenantContext(context.Background(), f.NewTenant(t))
+
+	first := insertTicket(t, f.Store, ctx, "Opening night")
+	insertTicket(t, f.Store, ctx, "Closing night")
+
+	if first.Version != 1 {
+		t.Errorf("Insert: got version %d, want 1", first.Version)
+	}
+
+	read, err := f.Store.SelectOne(ctx, first.PublicID)
+	if err != nil {
+		t.Fatalf("SelectOne: %v", err)
+	}
+	if read.Title != first.Title || read.Price != first.Price || read.Capacity != first.Capacity {
+		t.Errorf("SelectOne: got %+v, want %+v", read, first)
+	}
+
+	tickets, pagination, err := f.Store.SelectMultiple(ctx, newFilter("OPENING"))
+	if err != nil {
+		t.Fatalf("SelectMultiple: %v", err)
+	}
+	if len(tickets) != 1 || tickets[0].PublicID != first.PublicID {
+		t.Errorf("SelectMultiple: got %d tickets, want only %s", len(tickets), first.PublicID)
+	}
+	if pagination.TotalRecords != 1 {
+		t.Errorf("SelectMultiple: got %d total records, want 1", pagination.TotalRecords)
+	}
+
+	read.Title = "Opening night, revised"
+	if err := f.Store.Update(ctx, &read); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if read.Version != 2 {
+		t.Errorf("Update: got version %d, want 2", read.Version)
+	}
+
+	read.Status = tixer.TicketPublished
+	if err := f.Store.UpdateStatus(ctx, &read); err != nil {
+		t.Fatalf("UpdateStatus: %v", err)
+	}
+	if read.Version != 3 {
+		t.Errorf("UpdateStatus: got version %d, want 3", read.Version)
+	}
+
+	if err := f.Store.Delete(ctx, first.PublicID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := f.Store.SelectOne(ctx, first.PublicID); !errors.Is(err, tixer.ErrNotFound) {
+		t.Errorf("SelectOne after Delete: got %v, want %v", err, tixer.ErrNotFound)
+	}
+}
+
+// testTicketStoreNotFound checks that reading or deleting a missing ticket fails.
+func testTicketStoreNotFound(t *testing.T, f TicketStoreFixture) {
+	ctx := tixer.NewTenantContext(context.Background(), f.NewTenant(t))
+	id := newPublicID(t)
+
+	if _, err := f.Store.SelectOne(ctx, id); !errors.Is(err, tixer.ErrNotFound) {
+		t.Errorf("SelectOne: got %v, want %v", err, tixer.ErrNotFound)
+	}
+	if err := f.Store.Delete(ctx, id); !errors.Is(err, tixer.ErrNotFound) {
+		t.Errorf("Delete: got %v, want %v", err, tixer.ErrNotFound)
+	}
+}
+
+// testTicketStoreEditConflict checks that a ticket changed since it was read is not overwritten.
+func testTicketStoreEditConflict(t *testing.T, f TicketStoreFixture) {
+	ctx := tixer.NewTenantContext(context.Background(), f.NewTenant(t))
+	ticket := insertTicket(t, f.Store, ctx, "Edit conflict ticket")
+
+	stale := ticket
+	ticket.Title = "Edit conflict ticket, revised"
+	if err := f.Store.Update(ctx, &ticket); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	stale.Title = "Edit conflict ticket, lost"
+	if err := f.Store.Update(ctx, &stale); !errors.Is(err, tixer.ErrEditConflict) {
+		t.Errorf("Update: got %v, want %v", err, tixer.ErrEditConflict)
+	}
+
+	stale.Status = tixer.TicketPublished
+	if err := f.Store.UpdateStatus(ctx, &stale); !errors.Is(err, tixer.ErrEditConflict) {
+		t.Errorf("UpdateStatus: got %v, want %v", err, tixer.ErrEditConflict)
+	}
+}
+
+// testTicketStoreSeatTaken checks that a seat is sold once per event, whatever the tenant.
+func testTicketStoreSeatTaken(t *testing.T, f TicketStoreFixture) {
+	eventID, seatID := f.NewSeat(t)
+
+	ticket := newTicket(t, "Seat ticket")
+	ticket.EventID, ticket.SeatID = &eventID, &seatID
+	ticket.Capacity = 1
+
+	ctx := tixer.NewTenantContext(context.Background(), f.NewTenant(t))
+	if _, err := f.Store.Insert(ctx, ticket); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+
+	for _, tenantCtx := range []context.Context{ctx, tixer.NewTenantContext(context.Background(), f.NewTenant(t))} {
+		ticket.PublicID = newPublicID(t)
+		if _, err := f.Store.Insert(tenantCtx, ticket); !errors.Is(err, tixer.ErrSeatTaken) {
+			t.Errorf("Insert: got %v, want %v", err, tixer.ErrSeatTaken)
+		}
+	}
+}
+
+// testTicketStoreTenantIsolation checks that the tickets of a tenant are out of reach of the others.
+func testTicketStoreTenantIsolation(t *testing.T, f TicketStoreFixture) {
+	ownerCtx := tixer.NewTenantContext(context.Background(), f.NewTenant(t))
+	otherCtx := tixer.NewTenantContext(context.Background(), f.NewTenant(t))
+	ticket := insertTicket(t, f.Store, ownerCtx, "Isolated ticket")
+
+	if _, err := f.Store.SelectOne(otherCtx, ticket.PublicID); !errors.Is(err, tixer.ErrNotFound) {
+		t.Errorf("SelectOne: got %v, want %v", err, tixer.ErrNotFound)
+	}
+
+	tickets, _, err := f.Store.SelectMultiple(otherCtx, newFilter(""))
+	if err != nil {
+		t.Fatalf("SelectMultiple: %v", err)
+	}
+	if len(tickets) != 0 {
+		t.Errorf("SelectMultiple: got %d tickets, want none", len(tickets))
+	}
+
+	changed := ticket
+	changed.Title = "Isolated ticket, taken over"
+	if err := f.Store.Update(otherCtx, &changed); !errors.Is(err, tixer.ErrEditConflict) {
+		t.Errorf("Update: got %v, want %v", err, tixer.ErrEditConflict)
+	}
+
+	changed.Status = tixer.TicketPublished
+	if err := f.Store.UpdateStatus(otherCtx, &changed); !errors.Is(err, tixer.ErrEditConflict) {
+		t.Errorf("UpdateStatus: got %v, want %v", err, tixer.ErrEditConflict)
+	}
+
+	if err := f.Store.Delete(otherCtx, ticket.PublicID); !errors.Is(err, tixer.ErrNotFound) {
+		t.Errorf("Delete: got %v, want %v", err, tixer.ErrNotFound)
+	}
+
+	read, err := f.Store.SelectOne(ownerCtx, ticket.PublicID)
+	if err != nil {
+		t.Fatalf("SelectOne: %v", err)
+	}
+	if read.Title != ticket.Title || read.Version != ticket.Version {
+		t.Errorf("SelectOne: got %+v, want %+v", read, ticket)
+	}
+}
+
+// testTicketStoreSortAndPaginate checks that the tickets are sorted by title in byte order or by price,
+// in both directions, with the ties broken by ascending ID, and that the pages follow that order.
+func testTicketStoreSortAndPaginate(t *testing.T, f TicketStoreFixture) {
+	ctx := tixer.NewTenantContext(context.Background(), f.NewTenant(t))
+
+	insert := func(title string, price int64) tixer.PublicID {
+		ticket := newTicket(t, title)
+		ticket.Price.Amount = price
+
+		stored, err := f.Store.Insert(ctx, ticket)
+		if err != nil {
+			t.Fatalf("inserting ticket: %v", err)
+		}
+		return stored.PublicID
+	}
+
+	// Inserted in ID order: upper case titles sort before lower case ones in byte order.
+	alphaUpper := insert("Alpha", 3000)
+	beta := insert("Beta", 1000)
+	alphaLower := insert("alpha", 1000)
+	gammaFirst := insert("Gamma", 2000)
+	gammaSecond := insert("Gamma", 1000)
+
+	tests := []struct {
+		name      string
+		column    string
+		direction string
+		offset    int
+		limit     int
+		want      []tixer.PublicID
+	}{
+		{"title ascending", "title", "ASC", 0, 20, []tixer.PublicID{alphaUpper, beta, gammaFirst, gammaSecond, alphaLower}},
+		{"title descending", "title", "DESC", 0, 20, []tixer.PublicID{alphaLower, gammaFirst, gammaSecond, beta, alphaUpper}},
+		{"price ascending", "price", "ASC", 0, 20, []tixer.PublicID{beta, alphaLower, gammaSecond, gammaFirst, alphaUpper}},
+		{"price descending", "price", "DESC", 0, 20, []tixer.PublicID{alphaUpper, gammaFirst, beta, alphaLower, gammaSecond}},
+		{"price ascending, second page", "price", "ASC", 2, 2, []tixer.PublicID{gammaSecond, gammaFirst}},
+		{"title descending, last page", "title", "DESC", 4, 2, []tixer.PublicID{alphaUpper}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tickets, pagination, err := f.Store.SelectMultiple(ctx, tixer.TicketFilter{
+				Limit:         tt.limit,
+				Offset:        tt.offset,
+				SortColumn:    tt.column,
+				SortDirection: tt.direction,
+			})
+			if err != nil {
+				t.Fatalf("SelectMultiple: %v", err)
+			}
+			if got := publicIDs(tickets); !slices.Equal(got, tt.want) {
+				t.Errorf("SelectMultiple: got %v, want %v", got, tt.want)
+			}
+			if pagination.TotalRecords != 5 {
+				t.Errorf("SelectMultiple: got %d total records, want 5", pagination.TotalRecords)
+			}
+		})
+	}
+}
+
+// testTicketStoreTitleSearch checks that a search of several words matches the titles that contain
+// all of them, in any order and letter case.
+func testTicketStoreTitleSearch(t *testing.T, f TicketStoreFixture) {
+	ctx := tixer.NewTenantContext(context.Background(), f.NewTenant(t))
+
+	live := insertTicket(t, f.Store, ctx, "Jazz night live")
+	insertTicket(t, f.Store, ctx, "Jazz morning")
+	insertTicket(t, f.Store, ctx, "Night at the opera")
+
+	tests := []struct {
+		title string
+		want  []tixer.PublicID
+	}{
+		{"night jazz", []tixer.PublicID{live.PublicID}},
+		{"LIVE Jazz Night", []tixer.PublicID{live.PublicID}},
+		{"jazz opera", nil},
+	}
+
+	for _, tt := range tests {
+		tickets, _, err := f.Store.SelectMultiple(ctx, newFilter(tt.title))
+		if err != nil {
+			t.Fatalf("SelectMultiple(%q): %v", tt.title, err)
+		}
+		if got := publicIDs(tickets); !slices.Equal(got, tt.want) {
+			t.Errorf("SelectMultiple(%q): got %v, want %v", tt.title, got, tt.want)
+		}
+	}
+}
+
+// publicIDs returns the public IDs of the tickets, in their order.
+func publicIDs(tickets []tixer.Ticket) []tixer.PublicID {
+	var ids []tixer.PublicID
+	for _, ticket := range tickets {
+		ids = append(ids, ticket.PublicID)
+	}
+	return ids
+}
+
+// insertTicket stores a new ticket with the title for the tenant of the context.
+func insertTicket(t *testing.T, store tixer.TicketStore, ctx context.Context, title string) tixer.Ticket {
+	t.Helper()
+
+	ticket, err := store.Insert(ctx, newTicket(t, title))
+	if err != nil {
+		t.Fatalf("inserting ticket: %v", err)
+	}
+
+	return ticket
+}
+
+// newTicket returns a draft ticket with the title that is ready to be stored.
+func newTicket(t *testing.T, title string) tixer.Ticket {
+	t.Helper()
+
+	return tixer.Ticket{
+		PublicID: newPublicID(t),
+		Title:    title,
+		Status:   tixer.TicketDraft,
+		Price:    tixer.Money{Amount: 1000, Currency: "EUR"},
+		Capacity: 10,
+	}
+}
+
+// newFilter returns the filter of the first page of the tickets whose title contains the words.
+func newFilter(title string) tixer.TicketFilter {
+	return tixer.TicketFilter{
+		Title:         title,
+		Limit:         20,
+		SortColumn:    "id",
+		SortDirection: "ASC",
+	}
+}
+
+func newPublicID(t *testing.T) tixer.PublicID {
+	t.Helper()
+
+	id, err := nanoid.Generate(tixer.PublicIDAlphabet, tixer.PublicIDLength)
+	if err != nil {
+		t.Fatalf("generating public ID: %v", err)
+	}
+
+	return tixer.PublicID(id)
+}
@@ -0,0 +1,43 @@
+package tixer
+
+import (
+	"time"
+)
+
+// TransferStatus represents the state of a ticket transfer.
+type TransferStatus string
+
+// Ticket transfer statuses.
+const (
+	TransferPending   TransferStatus = "pending"   // waiting for the recipient to accept or decline
+	TransferAccepted  TransferStatus = "accepted"  // the ticket now belongs to the recipient
+	TransferDeclined  TransferStatus = "declined"  // the recipient refused the ticket
+	TransferCancelled TransferStatus = "cancelled" // the sender withdrew the transfer
+)
+
+// Transfer represents the handover of a ticket from its owner to another user, addressed by email.
+// The ticket changes hands only once the recipient accepts, which they can do after signing up
+// if the email had no account when the transfer was sent.
+type Transfer struct {
+	ID             int64
+	PublicID       PublicID
+	TicketID       int64
+	TicketPublicID PublicID
+	FromUserID     int64
+	FromEmail      string
+	ToEmail        string
+	ToUserID       *int64 // the ID of the user that accepted or declined the transfer, nil until then
+	Status         TransferStatus
+	CreatedAt      time.Time
+	RespondedAt    *time.Time // the moment the transfer was accepted, declined or cancelled
+}
+
+// SentBy reports whether the transfer was started by the given user.
+func (t Transfer) SentBy(user User) bool {
+	return t.FromUserID == user.ID
+}
+
+// SentTo reports whether the transfer is addressed to the email of the given user.
+func (t Transfer) SentTo(user User) bool {
+	return t.ToEmail == user.Email
+}
@@ -0,0 +1,90 @@
+package tixer
+
+import (
+	"errors"
+	"net/mail"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// User represents an account that can sign in to the system.
+type User struct {
+	ID           int64
+	PublicID     PublicID
+	Name         string
+	Email        string
+	PasswordHash []byte
+	TenantID     *int64 // the ID of the organizer the user works for, nil for users that only buy tickets
+	Version      int32
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// ValidEmail reports whether email is a bare email address of at most 254 characters.
+// ParseAddress also accepts a display name, e.g. "Jane <jane@example.com>", so the parsed
+// address must be the whole email.
+func ValidEmail(email string) bool {
+	address, err := mail.ParseAddress(email)
+	return err == nil && address.Address == email && len(email) <= 254
+}
+
+// Validate checks user's fields to ensure that the basic business rules are met.
+// It returns a boolean indicating if the user is valid and a map of errors if it's not.
+func (u User) Validate() (bool, map[string]string) {
+	errors := make(map[string]string)
+
+	if u.Name == "" {
+		errors["name"] = "must be provided"
+	}
+
+	if len(u.Name) > 100 {
+		errors["name"] = "must not be more than 100 characters long"
+	}
+
+	if !ValidEmail(u.Email) {
+		errors["email"] = "must be a valid email address"
+	}
+
+	if len(errors) > 0 {
+		return false, errors
+	}
+
+	return true, nil
+}
+
+// SetPassword hashes the plaintext password and stores the hash on the user.
+func (u *User) SetPassword(plaintext string) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(plaintext), 12)
+	if err != nil {
+		return err
+	}
+
+	u.PasswordHash = hash
+	return nil
+}
+
+// dummyPasswordHash is a hash of the same cost as the stored ones, that no password is expected to match.
+var dummyPasswordHash = []byte("$2a$12$dCV97/VyDCwsdfB19nPGw.GzhCzpJh6ACOlYLJCie3PbnZykwTXwW")
+
+// ComparePasswordToDummy checks the plaintext password against a hash that belongs to no user.
+// It is meant for sign ins with an unknown email, which then take as long to refuse as a wrong password,
+// so the response time does not reveal which emails have an account.
+func ComparePasswordToDummy(plaintext string) {
+	_ = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(plaintext))
+}
+
+// PasswordMatches checks whether the plaintext password matches the stored hash.
+func (u User) PasswordMatches(plaintext string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword(u.PasswordHash, []byte(plaintext))
+	if err != nil {
+		switch {
+		case errors.Is(err, bcrypt.ErrMismatchedHashAndPassword):
+			return false, nil
+		default:
+			return false, err
+		}
+	}
+
+	return true, nil
+}
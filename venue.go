@@ -0,0 +1,55 @@
+package tixer
+
+import (
+	"time"
+)
+
+// Venue represents a place where events take place.
+type Venue struct {
+	ID        int64
+	PublicID  PublicID
+	OwnerID   *int64 // the ID of the user that added the venue
+	Name      string
+	Address   string
+	Capacity  int32 // the maximum number of attendees
+	Version   int32
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Validate checks venue's fields to ensure that the basic business rules are met.
+// It returns a boolean indicating if the venue is valid and a map of errors if it's not.
+func (v Venue) Validate() (bool, map[string]string) {
+	errors := make(map[string]string)
+
+	if v.Name == "" {
+		errors["name"] = "must be provided"
+	}
+
+	if len(v.Name) > 150 {
+		errors["name"] = "must not be more than 150 characters long"
+	}
+
+	if v.Address == "" {
+		errors["address"] = "must be provided"
+	}
+
+	if len(v.Address) > 500 {
+		errors["address"] = "must not be more than 500 characters long"
+	}
+
+	if v.Capacity <= 0 {
+		errors["capacity"] = "must be greater than 0"
+	}
+
+	if len(errors) > 0 {
+		return false, errors
+	}
+
+	return true, nil
+}
+
+// OwnedBy reports whether the venue belongs to the user with the given ID.
+func (v Venue) OwnedBy(userID int64) bool {
+	return v.OwnerID != nil && *v.OwnerID == userID
+}
@@ -0,0 +1,31 @@
+package tixer
+
+import (
+	"time"
+)
+
+// WaitlistStatus represents the state of a waitlist entry.
+type WaitlistStatus string
+
+// Waitlist entry statuses.
+const (
+	WaitlistWaiting WaitlistStatus = "waiting"
+	WaitlistOffered WaitlistStatus = "offered" // a released unit is held for the user until the claim expires
+	WaitlistClaimed WaitlistStatus = "claimed" // the user placed an order for the held unit
+	WaitlistLapsed  WaitlistStatus = "lapsed"  // the claim expired and the unit was offered to the next user
+)
+
+// WaitlistEntry represents a user waiting for a unit of a sold out ticket.
+// Users are served in the order they joined the waitlist.
+type WaitlistEntry struct {
+	ID             int64
+	PublicID       PublicID
+	TicketID       int64
+	TicketPublicID PublicID
+	UserID         int64
+	Status         WaitlistStatus
+	Position       int32      // the number of entries served before this one, only set while waiting
+	ClaimExpiresAt *time.Time // the moment the held unit goes to the next user, only set once offered
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
@@ -0,0 +1,71 @@
+// This package provides support for background jobs that run alongside the HTTP server.
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	tixer "github.com/mroobert/monorepo-tixer"
+	"github.com/mroobert/monorepo-tixer/psql"
+)
+
+// expireBatchSize is the maximum number of orders expired by a single database round trip.
+const expireBatchSize = 100
+
+// OrderExpirer periodically cancels pending orders that were not paid in time,
+// releasing their tickets. Expiry is driven by the expires_at timestamps stored
+// in the database, so orders that became overdue while the application was down
+// are expired on the first run after a restart.
+type OrderExpirer struct {
+	OrderRepository *psql.OrderRepository
+	Interval        time.Duration // how often the database is checked for overdue orders
+}
+
+func NewOrderExpirer(orderRepository *psql.OrderRepository, interval time.Duration) *OrderExpirer {
+	return &OrderExpirer{
+		OrderRepository: orderRepository,
+		Interval:        interval,
+	}
+}
+
+// Run expires overdue orders once and then every Interval until ctx is canceled.
+// The overdue orders of every tenant are expired.
+func (e *OrderExpirer) Run(ctx context.Context) {
+	ctx = tixer.NewTenantBypassContext(ctx)
+
+	ticker := time.NewTicker(e.Interval)
+	defer ticker.Stop()
+
+	for {
+		e.expireOverdue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// expireOverdue expires overdue orders in batches until none are left.
+func (e *OrderExpirer) expireOverdue(ctx context.Context) {
+	for ctx.Err() == nil {
+		orders, err := e.OrderRepository.ExpireOverdue(ctx, expireBatchSize)
+		if err != nil {
+			slog.Error("failed to expire overdue orders", slog.String("error", err.Error()))
+			return
+		}
+
+		for _, order := range orders {
+			slog.Info("order expired",
+				slog.String("order_id", string(order.PublicID)),
+				slog.String("ticket_id", string(order.TicketPublicID)),
+			)
+		}
+
+		if len(orders) < expireBatchSize {
+			return
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	tixer "github.com/mroobert/monorepo-tixer"
+	"github.com/mroobert/monorepo-tixer/psql"
+)
+
+// lapseBatchSize is the maximum number of waitlist claims lapsed by a single database round trip.
+const lapseBatchSize = 100
+
+// ClaimExpirer periodically lapses the waitlist claims that were not turned into orders
+// in time and offers their held units to the next users in line.
+type ClaimExpirer struct {
+	WaitlistRepository *psql.WaitlistRepository
+	Interval           time.Duration // how often the database is checked for overdue claims
+}
+
+func NewClaimExpirer(waitlistRepository *psql.WaitlistRepository, interval time.Duration) *ClaimExpirer {
+	return &ClaimExpirer{
+		WaitlistRepository: waitlistRepository,
+		Interval:           interval,
+	}
+}
+
+// Run lapses overdue claims once and then every Interval until ctx is canceled.
+// The overdue claims of every tenant are lapsed.
+func (e *ClaimExpirer) Run(ctx context.Context) {
+	ctx = tixer.NewTenantBypassContext(ctx)
+
+	ticker := time.NewTicker(e.Interval)
+	defer ticker.Stop()
+
+	for {
+		e.lapseOverdue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// lapseOverdue lapses overdue claims in batches until none are left.
+func (e *ClaimExpirer) lapseOverdue(ctx context.Context) {
+	for ctx.Err() == nil {
+		entries, err := e.WaitlistRepository.LapseOverdue(ctx, lapseBatchSize)
+		if err != nil {
+			slog.Error("failed to lapse overdue waitlist claims", slog.String("error", err.Error()))
+			return
+		}
+
+		for _, entry := range entries {
+			slog.Info("waitlist claim lapsed",
+				slog.String("entry_id", string(entry.PublicID)),
+				slog.String("ticket_id", string(entry.TicketPublicID)),
+			)
+		}
+
+		if len(entries) < lapseBatchSize {
+			return
+		}
+	}
+}